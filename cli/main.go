@@ -1,10 +1,16 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/nitrix4ly/comet/core"
+	"github.com/nitrix4ly/comet/drivers"
 	"github.com/nitrix4ly/comet/gen"
 	"github.com/spf13/cobra"
 )
@@ -21,12 +27,21 @@ var genCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		outputDir, _ := cmd.Flags().GetString("output")
 		schemaDir, _ := cmd.Flags().GetString("schema")
-		
-		if err := runGenerate(schemaDir, outputDir); err != nil {
+		seedsDir, _ := cmd.Flags().GetString("seeds")
+		tablePrefix, _ := cmd.Flags().GetString("table-prefix")
+		emitJSON, _ := cmd.Flags().GetString("emit-json")
+		jsonSchemaDir, _ := cmd.Flags().GetString("json-schema")
+		models, _ := cmd.Flags().GetStringSlice("models")
+		force, _ := cmd.Flags().GetBool("force")
+		splitQueries, _ := cmd.Flags().GetBool("split-queries")
+		migrationsDir, _ := cmd.Flags().GetString("migrations")
+		provider, _ := cmd.Flags().GetString("provider")
+
+		if err := runGenerate(schemaDir, outputDir, seedsDir, tablePrefix, emitJSON, jsonSchemaDir, models, force, splitQueries, migrationsDir, provider); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		
+
 		fmt.Println("✅ Models generated successfully!")
 	},
 }
@@ -36,12 +51,24 @@ var migrateCmd = &cobra.Command{
 	Short: "Run database migrations",
 	Run: func(cmd *cobra.Command, args []string) {
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
-		
-		if err := runMigrate(dryRun); err != nil {
+		printSQL, _ := cmd.Flags().GetBool("sql")
+		schemaDir, _ := cmd.Flags().GetString("schema")
+		provider, _ := cmd.Flags().GetString("provider")
+		tablePrefix, _ := cmd.Flags().GetString("table-prefix")
+
+		if printSQL {
+			if err := runSchemaSQL(schemaDir, provider, tablePrefix); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if err := runMigrate(schemaDir, provider, tablePrefix, dryRun); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		
+
 		if dryRun {
 			fmt.Println("✅ Migration preview completed!")
 		} else {
@@ -51,16 +78,16 @@ var migrateCmd = &cobra.Command{
 }
 
 var seedCmd = &cobra.Command{
-	Use:   "seed",
+	Use:   "seed [seeder...]",
 	Short: "Seed database with initial data",
 	Run: func(cmd *cobra.Command, args []string) {
-		seedFile, _ := cmd.Flags().GetString("file")
-		
-		if err := runSeed(seedFile); err != nil {
+		seedsDir, _ := cmd.Flags().GetString("dir")
+
+		if err := runSeed(seedsDir, args); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		
+
 		fmt.Println("✅ Database seeded successfully!")
 	},
 }
@@ -68,11 +95,24 @@ var seedCmd = &cobra.Command{
 func init() {
 	genCmd.Flags().StringP("output", "o", "models", "Output directory for generated models")
 	genCmd.Flags().StringP("schema", "s", "schema", "Schema directory")
-	
+	genCmd.Flags().String("seeds", "", "Also scaffold a seed program in this directory")
+	genCmd.Flags().String("table-prefix", getEnv("COMET_TABLE_PREFIX", ""), "Prefix prepended to every table name")
+	genCmd.Flags().String("emit-json", "", "Also write the parsed schema as JSON to this file")
+	genCmd.Flags().String("json-schema", "", "Also write a JSON Schema file per model to this directory")
+	genCmd.Flags().StringSlice("models", nil, "Only generate these models and the models they relate to")
+	genCmd.Flags().Bool("force", false, "Overwrite generated files even if they were edited by hand")
+	genCmd.Flags().Bool("split-queries", false, "Write each model's query builder and scanner to a separate <model>_query.go")
+	genCmd.Flags().String("migrations", "", "Also write SQL migration files for schema changes to this directory")
+	genCmd.Flags().String("provider", getEnv("COMET_DATABASE_PROVIDER", "sqlite"), "Database provider used to render migration SQL")
+
 	migrateCmd.Flags().Bool("dry-run", false, "Preview migrations without applying")
-	
-	seedCmd.Flags().StringP("file", "f", "", "Specific seed file to run")
-	
+	migrateCmd.Flags().Bool("sql", false, "Print the DDL for the whole schema without touching the database")
+	migrateCmd.Flags().StringP("schema", "s", "schema", "Schema directory")
+	migrateCmd.Flags().String("provider", getEnv("COMET_DATABASE_PROVIDER", "sqlite"), "Database provider used to render SQL")
+	migrateCmd.Flags().String("table-prefix", getEnv("COMET_TABLE_PREFIX", ""), "Prefix prepended to every table name")
+
+	seedCmd.Flags().StringP("dir", "d", "seeds", "Directory containing the seed program")
+
 	rootCmd.AddCommand(genCmd)
 	rootCmd.AddCommand(migrateCmd)
 	rootCmd.AddCommand(seedCmd)
@@ -85,69 +125,294 @@ func main() {
 	}
 }
 
-func runGenerate(schemaDir, outputDir string) error {
+func runGenerate(schemaDir, outputDir, seedsDir, tablePrefix, emitJSON, jsonSchemaDir string, models []string, force, splitQueries bool, migrationsDir, provider string) error {
 	if _, err := os.Stat(schemaDir); os.IsNotExist(err) {
 		return fmt.Errorf("schema directory '%s' does not exist", schemaDir)
 	}
-	
+
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %v", err)
 	}
-	
+
 	schemaFiles, err := filepath.Glob(filepath.Join(schemaDir, "*.cmt"))
 	if err != nil {
 		return fmt.Errorf("failed to find schema files: %v", err)
 	}
-	
+
 	if len(schemaFiles) == 0 {
 		return fmt.Errorf("no .cmt schema files found in %s", schemaDir)
 	}
-	
+
 	generator := gen.NewGenerator()
-	
+	if tablePrefix != "" {
+		generator.SetNamingStrategy(core.NewPrefixNamingStrategy(tablePrefix, nil))
+	}
+	generator.SetModelFilter(models)
+	generator.SetForce(force)
+	generator.SetSplitQueries(splitQueries)
+
 	for _, schemaFile := range schemaFiles {
 		fmt.Printf("Processing %s...\n", schemaFile)
-		
-		if err := generator.GenerateFromFile(schemaFile, outputDir); err != nil {
-			return fmt.Errorf("failed to generate from %s: %v", schemaFile, err)
-		}
 	}
-	
+
+	if err := generator.GenerateFromFiles(schemaFiles, outputDir); err != nil {
+		return fmt.Errorf("failed to generate models: %v", err)
+	}
+
 	if err := generator.GenerateHelpers(outputDir); err != nil {
 		return fmt.Errorf("failed to generate helpers: %v", err)
 	}
-	
+
+	for _, skipped := range generator.Skipped() {
+		fmt.Fprintf(os.Stderr, "Warning: skipped %s because it has local changes (use --force to overwrite)\n", skipped)
+	}
+
+	if seedsDir != "" {
+		if err := generator.GenerateSeeds(outputDir, seedsDir); err != nil {
+			return fmt.Errorf("failed to generate seeds: %v", err)
+		}
+	}
+
+	if emitJSON != "" {
+		if err := generator.WriteSchemaJSON(emitJSON); err != nil {
+			return fmt.Errorf("failed to write schema JSON: %v", err)
+		}
+	}
+
+	if jsonSchemaDir != "" {
+		if err := generator.GenerateJSONSchemas(jsonSchemaDir); err != nil {
+			return fmt.Errorf("failed to generate JSON schemas: %v", err)
+		}
+	}
+
+	if migrationsDir != "" {
+		files, err := writeMigrations(migrationsDir, provider, generator.Schema(), time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to write migrations: %v", err)
+		}
+		for _, file := range files {
+			fmt.Printf("Wrote migration %s\n", file)
+		}
+	}
+
 	return nil
 }
 
-func runMigrate(dryRun bool) error {
+type ddlDriver interface {
+	MigrationStatements(change core.SchemaChange) ([]string, error)
+}
+
+func newDDLDriver(provider string) (ddlDriver, error) {
+	switch provider {
+	case "postgres":
+		return &drivers.PostgresDriver{}, nil
+	case "mysql":
+		return &drivers.MySQLDriver{}, nil
+	case "sqlite":
+		return &drivers.SQLiteDriver{}, nil
+	default:
+		return nil, fmt.Errorf("unknown database provider '%s'", provider)
+	}
+}
+
+func schemaStatements(schemaDir, provider, tablePrefix string) ([]string, error) {
+	schemaFiles, err := filepath.Glob(filepath.Join(schemaDir, "*.cmt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find schema files: %v", err)
+	}
+
+	if len(schemaFiles) == 0 {
+		return nil, fmt.Errorf("no .cmt schema files found in %s", schemaDir)
+	}
+
+	driver, err := newDDLDriver(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := gen.NewParser()
+	if tablePrefix != "" {
+		parser.SetNamingStrategy(core.NewPrefixNamingStrategy(tablePrefix, nil))
+	}
+
+	schema, err := parser.ParseFiles(schemaFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %v", err)
+	}
+
+	var statements []string
+	for _, change := range core.DiffSchemas(nil, schema) {
+		changeStatements, err := driver.MigrationStatements(change)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, changeStatements...)
+	}
+
+	return statements, nil
+}
+
+func runSchemaSQL(schemaDir, provider, tablePrefix string) error {
+	statements, err := schemaStatements(schemaDir, provider, tablePrefix)
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range statements {
+		fmt.Printf("%s;\n\n", statement)
+	}
+
+	return nil
+}
+
+const migrationSnapshot = "schema_snapshot.json"
+
+func writeMigrations(dir, provider string, schema *core.Schema, now time.Time) ([]string, error) {
+	driver, err := newDDLDriver(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	snapshotFile := filepath.Join(dir, migrationSnapshot)
+	var previous *core.Schema
+	if data, err := os.ReadFile(snapshotFile); err == nil {
+		previous = &core.Schema{}
+		if err := json.Unmarshal(data, previous); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", snapshotFile, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var tables []string
+	changesByTable := make(map[string][]core.SchemaChange)
+	for _, change := range core.DiffSchemas(previous, schema) {
+		table := change.Model.TableName
+		if _, ok := changesByTable[table]; !ok {
+			tables = append(tables, table)
+		}
+		changesByTable[table] = append(changesByTable[table], change)
+	}
+
+	start := now.UTC().Truncate(time.Second)
+	existing, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range existing {
+		version, _, _ := strings.Cut(filepath.Base(file), "_")
+		if last, err := time.Parse("20060102150405", version); err == nil && !start.After(last) {
+			start = last.Add(time.Second)
+		}
+	}
+
+	names := make([]string, len(tables))
+	contents := make([]string, len(tables))
+	for i, table := range tables {
+		changes := changesByTable[table]
+
+		action := "alter"
+		if len(changes) == 1 && changes[0].Type == core.ChangeCreateTable {
+			action = "create"
+		} else if len(changes) == 1 && changes[0].Type == core.ChangeDropTable {
+			action = "drop"
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "-- %s %s (%s)\n", action, table, provider)
+		for _, change := range changes {
+			statements, err := driver.MigrationStatements(change)
+			if err != nil {
+				return nil, err
+			}
+			for _, statement := range statements {
+				fmt.Fprintf(&b, "\n%s;\n", statement)
+			}
+		}
+
+		version := start.Add(time.Duration(i) * time.Second).Format("20060102150405")
+		names[i] = filepath.Join(dir, fmt.Sprintf("%s_%s_%s.sql", version, action, table))
+		contents[i] = b.String()
+	}
+
+	var files []string
+	for i, filename := range names {
+		if err := os.WriteFile(filename, []byte(contents[i]), 0644); err != nil {
+			return files, err
+		}
+		files = append(files, filename)
+	}
+
+	if previous != nil && len(files) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return files, err
+	}
+	return files, os.WriteFile(snapshotFile, append(data, '\n'), 0644)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func runMigrate(schemaDir, provider, tablePrefix string, dryRun bool) error {
 	fmt.Println("🔄 Running migrations...")
-	
+
 	if dryRun {
+		statements, err := schemaStatements(schemaDir, provider, tablePrefix)
+		if err != nil {
+			return err
+		}
+
 		fmt.Println("📋 DRY RUN - No changes will be applied")
-		fmt.Println("SQL Preview:")
-		fmt.Println("CREATE TABLE users (")
-		fmt.Println("  id SERIAL PRIMARY KEY,")
-		fmt.Println("  email VARCHAR(255) UNIQUE NOT NULL,")
-		fmt.Println("  name VARCHAR(255),")
-		fmt.Println("  created_at TIMESTAMP DEFAULT NOW()")
-		fmt.Println(");")
+		fmt.Printf("SQL Preview (%s):\n", provider)
+		for _, statement := range statements {
+			fmt.Printf("%s;\n", statement)
+		}
 		return nil
 	}
-	
+
 	fmt.Println("📝 Applying migrations to database...")
 	return nil
 }
 
-func runSeed(seedFile string) error {
+func runSeed(seedsDir string, seeders []string) error {
 	fmt.Println("🌱 Seeding database...")
-	
-	if seedFile != "" {
-		fmt.Printf("Running seed file: %s\n", seedFile)
+
+	if _, err := os.Stat(filepath.Join(seedsDir, "seed.go")); os.IsNotExist(err) {
+		return fmt.Errorf("no seed program found in %s, run 'comet gen --seeds %s' first", seedsDir, seedsDir)
+	}
+
+	if len(seeders) > 0 {
+		fmt.Printf("Running seeders: %s\n", strings.Join(seeders, ", "))
 	} else {
-		fmt.Println("Running all seed files...")
+		fmt.Println("Running all registered seeders...")
+	}
+
+	target := seedsDir
+	if !filepath.IsAbs(target) {
+		target = "./" + filepath.ToSlash(filepath.Clean(target))
 	}
-	
-	fmt.Println("📝 Sample data inserted")
+
+	args := append([]string{"run", target}, seeders...)
+	cmd := exec.Command("go", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("seed program failed: %v", err)
+	}
+
 	return nil
 }
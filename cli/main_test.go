@@ -0,0 +1,284 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nitrix4ly/comet/core"
+	"github.com/nitrix4ly/comet/gen"
+)
+
+const blogSchema = `
+model User {
+  Id    Int    @id @auto
+  Email String @unique
+  Posts Post[]
+}
+
+model Post {
+  Id       Int    @id @auto
+  Title    String
+  AuthorId Int
+  Author   User   @relation(fields: [AuthorId], references: [Id])
+
+  @@index([Title])
+}
+`
+
+func writeSchemaDir(t *testing.T, schema string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "schema.cmt"), []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// captureStdout runs fn and returns what it printed to stdout.
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	fnErr := fn()
+	os.Stdout = stdout
+	w.Close()
+	output := <-done
+	if fnErr != nil {
+		t.Fatal(fnErr)
+	}
+	return output
+}
+
+func TestSchemaSQL(t *testing.T) {
+	dir := writeSchemaDir(t, blogSchema)
+
+	tests := map[string]string{
+		"sqlite": `CREATE TABLE IF NOT EXISTS users (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  email TEXT UNIQUE NOT NULL,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS posts (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  title TEXT NOT NULL,
+  author_id INTEGER NOT NULL,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS posts_Title_idx ON posts (title);
+
+`,
+		"postgres": `CREATE TABLE IF NOT EXISTS users (
+  id SERIAL PRIMARY KEY,
+  email VARCHAR(255) UNIQUE NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS posts (
+  id SERIAL PRIMARY KEY,
+  title VARCHAR(255) NOT NULL,
+  author_id INTEGER NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS posts_Title_idx ON posts (title);
+
+`,
+	}
+
+	for provider, want := range tests {
+		output := captureStdout(t, func() error {
+			return runSchemaSQL(dir, provider, "")
+		})
+		if output != want {
+			t.Errorf("%s output:\n%s\nwant:\n%s", provider, output, want)
+		}
+	}
+}
+
+func TestSchemaSQLWithoutSchemaFiles(t *testing.T) {
+	err := runSchemaSQL(t.TempDir(), "sqlite", "")
+	if err == nil || !strings.Contains(err.Error(), "no .cmt schema files") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestMigrateDryRunMatchesDDL(t *testing.T) {
+	dir := writeSchemaDir(t, blogSchema)
+
+	for _, provider := range []string{"sqlite", "postgres", "mysql"} {
+		statements, err := schemaStatements(dir, provider, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "🔄 Running migrations...\n📋 DRY RUN - No changes will be applied\nSQL Preview (" + provider + "):\n"
+		for _, statement := range statements {
+			want += statement + ";\n"
+		}
+
+		output := captureStdout(t, func() error {
+			return runMigrate(dir, provider, "", true)
+		})
+		if output != want {
+			t.Errorf("%s dry run:\n%s\nwant:\n%s", provider, output, want)
+		}
+		if strings.Contains(output, "CREATE TABLE users (") {
+			t.Errorf("%s dry run still prints the placeholder table", provider)
+		}
+	}
+}
+
+func TestSchemaSQLTablePrefix(t *testing.T) {
+	dir := writeSchemaDir(t, blogSchema)
+
+	output := captureStdout(t, func() error {
+		return runSchemaSQL(dir, "sqlite", "app_")
+	})
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS app_users (",
+		"CREATE TABLE IF NOT EXISTS app_posts (",
+		"CREATE INDEX IF NOT EXISTS app_posts_Title_idx ON app_posts (title);",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output does not contain %q:\n%s", want, output)
+		}
+	}
+}
+
+func parseBlogSchema(t *testing.T, schema string) *core.Schema {
+	t.Helper()
+	parsed, err := gen.NewParser().ParseFiles([]string{filepath.Join(writeSchemaDir(t, schema), "schema.cmt")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestInitialMigrationForBlogSchema(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	files, err := writeMigrations(dir, "sqlite", parseBlogSchema(t, blogSchema), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "20261015120000_create_users.sql"),
+		filepath.Join(dir, "20261015120001_create_posts.sql"),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+
+	statements, err := schemaStatements(writeSchemaDir(t, blogSchema), "sqlite", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		written += string(data)
+	}
+	if !strings.HasPrefix(written, "-- create users (sqlite)\n\nCREATE TABLE IF NOT EXISTS users (") {
+		t.Errorf("users migration does not start with its header:\n%s", written)
+	}
+	for _, statement := range statements {
+		if !strings.Contains(written, "\n"+statement+";\n") {
+			t.Errorf("migrations do not contain %q:\n%s", statement, written)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, migrationSnapshot)); err != nil {
+		t.Errorf("snapshot was not written: %v", err)
+	}
+
+	files, err = writeMigrations(dir, "sqlite", parseBlogSchema(t, blogSchema), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("unchanged schema wrote %v", files)
+	}
+}
+
+func TestMigrationForChangedModel(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	if _, err := writeMigrations(dir, "sqlite", parseBlogSchema(t, blogSchema), now); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := strings.Replace(blogSchema, "Title    String", "Title    String\n  Draft    Boolean @default(true)", 1)
+	files, err := writeMigrations(dir, "sqlite", parseBlogSchema(t, changed), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(dir, "20261015120002_alter_posts.sql")
+	if len(files) != 1 || files[0] != want {
+		t.Fatalf("files = %v, want %s", files, want)
+	}
+	data, err := os.ReadFile(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\nALTER TABLE posts ADD COLUMN draft INTEGER NOT NULL DEFAULT 1;\n") {
+		t.Errorf("migration does not add draft:\n%s", data)
+	}
+}
+
+func TestGenerateWritesSeedsOnlyWhenRequested(t *testing.T) {
+	schemaDir := writeSchemaDir(t, blogSchema)
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(root, "models")
+	seeds := filepath.Join(root, "seeds")
+
+	generate := func(seedsDir string) {
+		captureStdout(t, func() error {
+			return runGenerate(schemaDir, output, seedsDir, "", "", "", nil, false, false, "", "sqlite")
+		})
+	}
+
+	generate("")
+	if _, err := os.Stat(seeds); !os.IsNotExist(err) {
+		t.Errorf("gen without --seeds created %s: %v", seeds, err)
+	}
+
+	generate(seeds)
+	data, err := os.ReadFile(filepath.Join(seeds, "seed.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"example.com/app/models"`) {
+		t.Errorf("seed.go does not import the generated models:\n%s", data)
+	}
+}
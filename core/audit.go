@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+type AuditEvent struct {
+	Table      string                 `json:"table"`
+	Operation  string                 `json:"operation"`
+	PrimaryKey interface{}            `json:"primary_key"`
+	Changes    map[string]AuditChange `json:"changes"`
+	Time       time.Time              `json:"time"`
+}
+
+type AuditLogger interface {
+	Audit(ctx context.Context, event AuditEvent) error
+}
+
+type AuditLoggerFunc func(ctx context.Context, event AuditEvent) error
+
+func (f AuditLoggerFunc) Audit(ctx context.Context, event AuditEvent) error {
+	return f(ctx, event)
+}
+
+var (
+	auditMu     sync.RWMutex
+	auditLogger AuditLogger
+)
+
+func SetAuditLogger(l AuditLogger) {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	auditLogger = l
+}
+
+func AuditEnabled() bool {
+	auditMu.RLock()
+	defer auditMu.RUnlock()
+	return auditLogger != nil
+}
+
+func Audit(ctx context.Context, event AuditEvent) error {
+	auditMu.RLock()
+	l := auditLogger
+	auditMu.RUnlock()
+
+	if l == nil {
+		return nil
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	return l.Audit(ctx, event)
+}
@@ -5,16 +5,27 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 )
 
 type QueryExecutor struct {
-	query     *Query
-	modelType string
-	scanner   func(*sql.Rows) (interface{}, error)
+	db           *DB
+	query        *Query
+	modelType    string
+	scanner      func(*sql.Rows) (interface{}, error)
+	newModel     func() Joinable
+	tenantColumn string
+	unscoped     bool
+	cacheTTL     time.Duration
 }
 
 func NewQueryExecutor(table, modelType string, scanner func(*sql.Rows) (interface{}, error)) *QueryExecutor {
+	return NewQueryExecutorOn(nil, table, modelType, scanner)
+}
+
+func NewQueryExecutorOn(db *DB, table, modelType string, scanner func(*sql.Rows) (interface{}, error)) *QueryExecutor {
 	return &QueryExecutor{
+		db: db,
 		query: &Query{
 			Table:  table,
 			Fields: []string{"*"},
@@ -24,6 +35,33 @@ func NewQueryExecutor(table, modelType string, scanner func(*sql.Rows) (interfac
 	}
 }
 
+func (qe *QueryExecutor) ScopeTenant(column string) *QueryExecutor {
+	qe.tenantColumn = column
+	return qe
+}
+
+func (qe *QueryExecutor) JoinModel(fn func() Joinable) *QueryExecutor {
+	qe.newModel = fn
+	return qe
+}
+
+func (qe *QueryExecutor) Unscoped() QueryBuilder {
+	qe.unscoped = true
+	return qe
+}
+
+func (qe *QueryExecutor) Cache(ttl time.Duration) QueryBuilder {
+	qe.cacheTTL = ttl
+	return qe
+}
+
+func (qe *QueryExecutor) When(condition bool, fn func(QueryBuilder) QueryBuilder) QueryBuilder {
+	if !condition {
+		return qe
+	}
+	return fn(qe)
+}
+
 func (qe *QueryExecutor) Where(field, operator string, value interface{}) QueryBuilder {
 	qe.query.Wheres = append(qe.query.Wheres, WhereClause{
 		Field:    field,
@@ -35,15 +73,10 @@ func (qe *QueryExecutor) Where(field, operator string, value interface{}) QueryB
 }
 
 func (qe *QueryExecutor) WhereIn(field string, values []interface{}) QueryBuilder {
-	placeholders := make([]string, len(values))
-	for i := range placeholders {
-		placeholders[i] = "?"
-	}
-	
 	qe.query.Wheres = append(qe.query.Wheres, WhereClause{
 		Field:    field,
 		Operator: "IN",
-		Value:    fmt.Sprintf("(%s)", strings.Join(placeholders, ",")),
+		Value:    values,
 	})
 	return qe
 }
@@ -58,6 +91,78 @@ func (qe *QueryExecutor) WhereNot(field, operator string, value interface{}) Que
 	return qe
 }
 
+func (qe *QueryExecutor) WhereRaw(condition string, args ...interface{}) QueryBuilder {
+	qe.query.Wheres = append(qe.query.Wheres, WhereClause{
+		Field:    condition,
+		Operator: "RAW",
+		Value:    args,
+	})
+	return qe
+}
+
+func (qe *QueryExecutor) WhereJSON(column, path, operator string, value interface{}) QueryBuilder {
+	qe.query.Wheres = append(qe.query.Wheres, WhereClause{
+		Field:    column,
+		Operator: operator,
+		Value:    value,
+		Path:     path,
+	})
+	return qe
+}
+
+func (qe *QueryExecutor) WhereFullText(columns []string, query string) QueryBuilder {
+	qe.query.Wheres = append(qe.query.Wheres, WhereClause{
+		Field:    strings.Join(columns, ","),
+		Operator: "FULLTEXT",
+		Value:    query,
+	})
+	return qe
+}
+
+func (qe *QueryExecutor) WhereInSubquery(field string, sub QueryBuilder) QueryBuilder {
+	qe.query.Wheres = append(qe.query.Wheres, WhereClause{
+		Field:    field,
+		Operator: "IN",
+		Subquery: sub,
+	})
+	return qe
+}
+
+func (qe *QueryExecutor) WhereExists(sub QueryBuilder) QueryBuilder {
+	qe.query.Wheres = append(qe.query.Wheres, WhereClause{
+		Operator: "EXISTS",
+		Subquery: sub,
+	})
+	return qe
+}
+
+func (qe *QueryExecutor) WhereNotExists(sub QueryBuilder) QueryBuilder {
+	qe.query.Wheres = append(qe.query.Wheres, WhereClause{
+		Operator: "EXISTS",
+		Subquery: sub,
+		Not:      true,
+	})
+	return qe
+}
+
+func (qe *QueryExecutor) WhereColumn(first, operator, second string) QueryBuilder {
+	qe.query.Wheres = append(qe.query.Wheres, WhereClause{
+		Field:    first,
+		Operator: operator,
+		Column:   second,
+	})
+	return qe
+}
+
+func (qe *QueryExecutor) WhereHas(relation string, constrain func(QueryBuilder)) QueryBuilder {
+	qe.query.Wheres = append(qe.query.Wheres, WhereClause{
+		Field:    relation,
+		Operator: "HAS",
+		Value:    constrain,
+	})
+	return qe
+}
+
 func (qe *QueryExecutor) OrderBy(field, direction string) QueryBuilder {
 	qe.query.Orders = append(qe.query.Orders, OrderClause{
 		Field:     field,
@@ -66,6 +171,20 @@ func (qe *QueryExecutor) OrderBy(field, direction string) QueryBuilder {
 	return qe
 }
 
+func (qe *QueryExecutor) GroupBy(fields ...string) QueryBuilder {
+	qe.query.Groups = append(qe.query.Groups, fields...)
+	return qe
+}
+
+func (qe *QueryExecutor) Having(field, operator string, value interface{}) QueryBuilder {
+	qe.query.Havings = append(qe.query.Havings, WhereClause{
+		Field:    field,
+		Operator: operator,
+		Value:    value,
+	})
+	return qe
+}
+
 func (qe *QueryExecutor) Limit(limit int) QueryBuilder {
 	qe.query.LimitVal = &limit
 	return qe
@@ -76,6 +195,16 @@ func (qe *QueryExecutor) Offset(offset int) QueryBuilder {
 	return qe
 }
 
+func (qe *QueryExecutor) ForUpdate() QueryBuilder {
+	qe.query.Lock = "FOR UPDATE"
+	return qe
+}
+
+func (qe *QueryExecutor) ForShare() QueryBuilder {
+	qe.query.Lock = "FOR SHARE"
+	return qe
+}
+
 func (qe *QueryExecutor) Select(fields ...string) QueryBuilder {
 	qe.query.Fields = fields
 	return qe
@@ -86,51 +215,200 @@ func (qe *QueryExecutor) Include(relations ...string) QueryBuilder {
 	return qe
 }
 
+func (qe *QueryExecutor) JoinInclude(relations ...string) QueryBuilder {
+	qe.query.JoinIncludes = append(qe.query.JoinIncludes, relations...)
+	return qe
+}
+
+func (qe *QueryExecutor) Join(table, first, operator, second string) QueryBuilder {
+	qe.query.Joins = append(qe.query.Joins, JoinClause{
+		Type:     "INNER",
+		Table:    table,
+		First:    first,
+		Operator: operator,
+		Second:   second,
+	})
+	return qe
+}
+
+func (qe *QueryExecutor) LeftJoin(table, first, operator, second string) QueryBuilder {
+	qe.query.Joins = append(qe.query.Joins, JoinClause{
+		Type:     "LEFT",
+		Table:    table,
+		First:    first,
+		Operator: operator,
+		Second:   second,
+	})
+	return qe
+}
+
 func (qe *QueryExecutor) All(ctx context.Context) ([]interface{}, error) {
-	db := GetDB()
+	db := qe.database()
 	if db == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
-	
-	query, args := qe.buildSelectQuery()
+
+	query, args, err := qe.compile(ctx, qe.scoped())
+	if err != nil {
+		return nil, err
+	}
+
+	cache, key := qe.cacheKey(ctx, "all", query, args)
+	if cache != nil {
+		if value, ok := cache.Get(key); ok {
+			results := copyResults(value.([]interface{}))
+			if err := qe.loadIncludes(ctx, results); err != nil {
+				return nil, err
+			}
+			return results, nil
+		}
+	}
+
 	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	
+
 	var results []interface{}
 	for rows.Next() {
-		item, err := qe.scanner(rows)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		item, err := qe.scan(rows)
 		if err != nil {
 			return nil, err
 		}
 		results = append(results, item)
 	}
-	
-	return results, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if cache != nil {
+		cache.Set(key, copyResults(results), qe.cacheTTL)
+	}
+	if err := qe.loadIncludes(ctx, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (qe *QueryExecutor) AllAsMaps(ctx context.Context) ([]map[string]interface{}, error) {
+	db := qe.database()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query, args, err := qe.compile(ctx, qe.scoped())
+	if err != nil {
+		return nil, err
+	}
+
+	cache, key := qe.cacheKey(ctx, "maps", query, args)
+	if cache != nil {
+		if value, ok := cache.Get(key); ok {
+			return copyMaps(value.([]map[string]interface{})), nil
+		}
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []map[string]interface{}
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			switch v := values[i].(type) {
+			case []byte:
+				row[column] = string(v)
+			default:
+				row[column] = v
+			}
+		}
+		results = append(results, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if cache != nil {
+		cache.Set(key, copyMaps(results), qe.cacheTTL)
+	}
+	return results, nil
 }
 
 func (qe *QueryExecutor) First(ctx context.Context) (interface{}, error) {
 	qe.query.LimitVal = intPtr(1)
-	
-	db := GetDB()
+
+	db := qe.database()
 	if db == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
-	
-	query, args := qe.buildSelectQuery()
+
+	query, args, err := qe.compile(ctx, qe.scoped())
+	if err != nil {
+		return nil, err
+	}
+
+	cache, key := qe.cacheKey(ctx, "first", query, args)
+	if cache != nil {
+		if value, ok := cache.Get(key); ok {
+			item := copyModel(value)
+			if err := qe.loadIncludes(ctx, []interface{}{item}); err != nil {
+				return nil, err
+			}
+			return item, nil
+		}
+	}
+
 	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	
+
 	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
 		return nil, sql.ErrNoRows
 	}
-	
-	return qe.scanner(rows)
+
+	item, err := qe.scan(rows)
+	if err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if cache != nil {
+		cache.Set(key, copyModel(item), qe.cacheTTL)
+	}
+	if err := qe.loadIncludes(ctx, []interface{}{item}); err != nil {
+		return nil, err
+	}
+	return item, nil
 }
 
 func (qe *QueryExecutor) Last(ctx context.Context) (interface{}, error) {
@@ -140,85 +418,620 @@ func (qe *QueryExecutor) Last(ctx context.Context) (interface{}, error) {
 			Direction: "DESC",
 		})
 	}
-	
+
 	return qe.First(ctx)
 }
 
 func (qe *QueryExecutor) Count(ctx context.Context) (int64, error) {
-	db := GetDB()
+	db := qe.database()
 	if db == nil {
 		return 0, fmt.Errorf("database not initialized")
 	}
-	
+
+	base := qe.scoped()
 	countQuery := &Query{
-		Table:     qe.query.Table,
+		Table:     base.Table,
 		Fields:    []string{"COUNT(*)"},
-		Wheres:    qe.query.Wheres,
+		Joins:     base.Joins,
+		Wheres:    base.Wheres,
 		Orders:    nil,
 		LimitVal:  nil,
 		OffsetVal: nil,
 	}
-	
-	query, args := qe.buildSelectQueryFromQuery(countQuery)
-	
+
+	query, args, err := qe.compile(ctx, countQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	cache, key := qe.cacheKey(ctx, "count", query, args)
+	if cache != nil {
+		if value, ok := cache.Get(key); ok {
+			return value.(int64), nil
+		}
+	}
+
 	var count int64
-	err := db.QueryRow(ctx, query, args...).Scan(&count)
-	return count, err
+	if err := db.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	if cache != nil {
+		cache.Set(key, count, qe.cacheTTL)
+	}
+	return count, nil
 }
 
 func (qe *QueryExecutor) Exists(ctx context.Context) (bool, error) {
-	count, err := qe.Count(ctx)
-	return count > 0, err
+	db := qe.database()
+	if db == nil {
+		return false, fmt.Errorf("database not initialized")
+	}
+
+	base := qe.scoped()
+	existsQuery := &Query{
+		Table:    base.Table,
+		Fields:   []string{"1"},
+		Joins:    base.Joins,
+		Wheres:   base.Wheres,
+		LimitVal: intPtr(1),
+	}
+
+	query, args, err := qe.compile(ctx, existsQuery)
+	if err != nil {
+		return false, err
+	}
+
+	cache, key := qe.cacheKey(ctx, "exists", query, args)
+	if cache != nil {
+		if value, ok := cache.Get(key); ok {
+			return value.(bool), nil
+		}
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	exists := rows.Next()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	if cache != nil {
+		cache.Set(key, exists, qe.cacheTTL)
+	}
+	return exists, nil
 }
 
-func (qe *QueryExecutor) buildSelectQuery() (string, []interface{}) {
-	return qe.buildSelectQueryFromQuery(qe.query)
+func (qe *QueryExecutor) Explain(ctx context.Context) (string, error) {
+	return qe.explain(ctx, false)
+}
+
+func (qe *QueryExecutor) ExplainAnalyze(ctx context.Context) (string, error) {
+	return qe.explain(ctx, true)
+}
+
+func (qe *QueryExecutor) explain(ctx context.Context, analyze bool) (string, error) {
+	db := qe.database()
+	if db == nil {
+		return "", fmt.Errorf("database not initialized")
+	}
+
+	prefix, err := explainPrefix(db.Dialect(), analyze)
+	if err != nil {
+		return "", err
+	}
+
+	query, args, err := qe.compile(ctx, qe.scoped())
+	if err != nil {
+		return "", err
+	}
+
+	rows, err := db.Query(ctx, prefix+query, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	if len(columns) > 1 {
+		lines = append(lines, strings.Join(columns, "\t"))
+	}
+
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+
+		parts := make([]string, len(values))
+		for i, value := range values {
+			parts[i] = value.String
+		}
+		lines = append(lines, strings.Join(parts, "\t"))
+	}
+
+	return strings.Join(lines, "\n"), rows.Err()
+}
+
+func explainPrefix(dialect string, analyze bool) (string, error) {
+	switch dialect {
+	case "sqlite":
+		if analyze {
+			return "", fmt.Errorf("EXPLAIN ANALYZE is not supported by sqlite")
+		}
+		return "EXPLAIN QUERY PLAN ", nil
+	default:
+		if analyze {
+			return "EXPLAIN ANALYZE ", nil
+		}
+		return "EXPLAIN ", nil
+	}
+}
+
+func (qe *QueryExecutor) cacheKey(ctx context.Context, kind, query string, args []interface{}) (Cache, string) {
+	if qe.cacheTTL <= 0 || qe.query.Lock != "" || TxFromContext(ctx) != nil {
+		return nil, ""
+	}
+
+	cache := GetCache()
+	if cache == nil {
+		return nil, ""
+	}
+
+	var versions []string
+	for _, table := range qe.tables() {
+		versions = append(versions, fmt.Sprintf("%s@%d", table, tableVersion(table)))
+	}
+
+	return cache, fmt.Sprintf("%s|%s|%s|%#v", kind, strings.Join(versions, ","), query, args)
+}
+
+func copyResults(results []interface{}) []interface{} {
+	copied := make([]interface{}, len(results))
+	for i, item := range results {
+		copied[i] = copyModel(item)
+	}
+	return copied
+}
+
+func copyMaps(results []map[string]interface{}) []map[string]interface{} {
+	copied := make([]map[string]interface{}, len(results))
+	for i, row := range results {
+		copied[i] = make(map[string]interface{}, len(row))
+		for column, value := range row {
+			copied[i][column] = value
+		}
+	}
+	return copied
+}
+
+func (qe *QueryExecutor) scoped() *Query {
+	if qe.unscoped {
+		return qe.query
+	}
+
+	scopes := DefaultScopes(qe.modelType)
+	if len(scopes) == 0 {
+		return qe.query
+	}
+
+	q := qe.query.clone()
+	for _, scope := range scopes {
+		scope(q)
+	}
+	return q
+}
+
+func (qe *QueryExecutor) compile(ctx context.Context, q *Query) (string, []interface{}, error) {
+	q, err := qe.prepare(ctx, q)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query, args := qe.buildSelectQueryFromQuery(q)
+	return query, args, nil
+}
+
+func (qe *QueryExecutor) prepare(ctx context.Context, q *Query) (*Query, error) {
+	for _, where := range q.Wheres {
+		if where.Path != "" {
+			if _, err := parseJSONPath(where.Path); err != nil {
+				return nil, err
+			}
+		}
+		if where.Operator == "FULLTEXT" && where.Field == "" {
+			return nil, fmt.Errorf("full-text search needs at least one column")
+		}
+	}
+
+	q, err := qe.resolveSubqueries(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if qe.tenantColumn != "" {
+		tenant, ok := TenantFrom(ctx)
+		if !ok {
+			return nil, ErrMissingTenant
+		}
+
+		scoped := *q
+		scoped.Wheres = append(append([]WhereClause{}, q.Wheres...), WhereClause{
+			Field:    qe.tenantColumn,
+			Operator: "=",
+			Value:    tenant,
+		})
+		q = &scoped
+	}
+
+	if len(q.JoinIncludes) > 0 && len(q.Fields) == 1 && q.Fields[0] == "*" {
+		q, err = qe.resolveJoinIncludes(q)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return q, nil
+}
+
+func (qe *QueryExecutor) resolveJoinIncludes(q *Query) (*Query, error) {
+	if qe.newModel == nil {
+		return nil, fmt.Errorf("%s does not support JoinInclude", qe.modelType)
+	}
+
+	resolved := q.clone()
+	for i, where := range resolved.Wheres {
+		if where.Operator != "RAW" && isPlainColumn(where.Field) {
+			resolved.Wheres[i].Field = q.Table + "." + where.Field
+		}
+	}
+	for i, order := range resolved.Orders {
+		if isPlainColumn(order.Field) {
+			resolved.Orders[i].Field = q.Table + "." + order.Field
+		}
+	}
+
+	resolved.Fields = []string{q.Table + ".*"}
+	for _, name := range q.JoinIncludes {
+		relation, ok := LookupRelation(qe.modelType, name)
+		if !ok {
+			return nil, fmt.Errorf("unknown relation '%s' on %s", name, qe.modelType)
+		}
+		if relation.Many || relation.New == nil {
+			return nil, fmt.Errorf("relation '%s' on %s cannot be loaded with a join", name, qe.modelType)
+		}
+
+		alias := joinAlias(name)
+		resolved.Joins = append(resolved.Joins, JoinClause{
+			Type:     "LEFT",
+			Table:    relation.Table + " AS " + alias,
+			First:    alias + "." + relation.ForeignKey,
+			Operator: "=",
+			Second:   q.Table + "." + relation.LocalKey,
+		})
+		resolved.Fields = append(resolved.Fields, alias+".*")
+	}
+	return resolved, nil
+}
+
+func (qe *QueryExecutor) scan(rows *sql.Rows) (item interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			item, err = nil, newScanError(qe.modelType, rows, qe.expectedColumns(), fmt.Errorf("scanner panicked: %v", p))
+		}
+	}()
+
+	item, err = qe.scanRow(rows)
+	if err != nil {
+		return nil, newScanError(qe.modelType, rows, qe.expectedColumns(), err)
+	}
+	return item, nil
+}
+
+func (qe *QueryExecutor) expectedColumns() int {
+	if qe.newModel == nil {
+		return 0
+	}
+
+	expected := len(qe.newModel().ScanDest())
+	for _, name := range qe.query.JoinIncludes {
+		if relation, ok := LookupRelation(qe.modelType, name); ok {
+			expected += len(relation.New().ScanDest())
+		}
+	}
+	return expected
+}
+
+func (qe *QueryExecutor) scanRow(rows *sql.Rows) (interface{}, error) {
+	if len(qe.query.JoinIncludes) == 0 || qe.newModel == nil {
+		return qe.scanner(rows)
+	}
+
+	model := qe.newModel()
+	dest := model.ScanDest()
+
+	related := make([]Joinable, len(qe.query.JoinIncludes))
+	assign := make([]func() bool, len(qe.query.JoinIncludes))
+	for i, name := range qe.query.JoinIncludes {
+		relation, _ := LookupRelation(qe.modelType, name)
+		related[i] = relation.New()
+
+		var holders []interface{}
+		holders, assign[i] = nullableDest(related[i].ScanDest())
+		dest = append(dest, holders...)
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	model.AfterScan()
+
+	for i, name := range qe.query.JoinIncludes {
+		if assign[i]() {
+			related[i].AfterScan()
+			model.SetRelation(name, related[i])
+		}
+	}
+	return model, nil
+}
+
+func isPlainColumn(field string) bool {
+	return identifierRefPattern.MatchString(field) && !strings.Contains(field, ".")
+}
+
+func joinAlias(relation string) string {
+	return ToSnakeCase(relation)
+}
+
+func (qe *QueryExecutor) resolveSubqueries(ctx context.Context, q *Query) (*Query, error) {
+	resolved := q
+	for i, where := range q.Wheres {
+		if where.Operator == "HAS" {
+			relation, ok := LookupRelation(qe.modelType, where.Field)
+			if !ok {
+				return nil, fmt.Errorf("unknown relation '%s' on %s", where.Field, qe.modelType)
+			}
+
+			sub := relation.Query().WhereColumn(relation.Table+"."+relation.ForeignKey, "=", q.Table+"."+relation.LocalKey)
+			if constrain, _ := where.Value.(func(QueryBuilder)); constrain != nil {
+				constrain(sub)
+			}
+			where = WhereClause{Operator: "EXISTS", Subquery: sub}
+		}
+
+		if where.Subquery == nil {
+			continue
+		}
+
+		sub, ok := where.Subquery.(*QueryExecutor)
+		if !ok {
+			return nil, fmt.Errorf("unsupported subquery builder %T", where.Subquery)
+		}
+		if sub.db == nil {
+			sub.db = qe.db
+		}
+
+		subQuery := sub.scoped()
+		if where.Operator == "EXISTS" && len(subQuery.Fields) == 1 && subQuery.Fields[0] == "*" {
+			subQuery = subQuery.clone()
+			subQuery.Fields = []string{"1"}
+		}
+
+		query, args, err := sub.compile(ctx, subQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		operator := where.Operator
+		if where.Not {
+			operator = "NOT " + operator
+		}
+
+		condition := fmt.Sprintf("%s (%s)", operator, query)
+		if where.Operator != "EXISTS" {
+			condition = QuoteRef(where.Field, qe.dialect()) + " " + condition
+		}
+
+		if resolved == q {
+			resolved = q.clone()
+		}
+		resolved.Wheres[i] = WhereClause{
+			Field:    condition,
+			Operator: "RAW",
+			Value:    args,
+		}
+	}
+	return resolved, nil
+}
+
+func (qe *QueryExecutor) tables() []string {
+	tables := []string{qe.query.Table}
+	for _, join := range qe.query.Joins {
+		tables = append(tables, join.Table)
+	}
+	for _, name := range qe.query.JoinIncludes {
+		if relation, ok := LookupRelation(qe.modelType, name); ok {
+			tables = append(tables, relation.Table)
+		}
+	}
+	for _, where := range qe.query.Wheres {
+		if sub, ok := where.Subquery.(*QueryExecutor); ok {
+			tables = append(tables, sub.tables()...)
+		}
+		if where.Operator == "HAS" {
+			if relation, ok := LookupRelation(qe.modelType, where.Field); ok {
+				tables = append(tables, relation.Table)
+			}
+		}
+	}
+	return tables
 }
 
 func (qe *QueryExecutor) buildSelectQueryFromQuery(q *Query) (string, []interface{}) {
+	return buildSelect(q, qe.dialect(), qe.supports(FeatureRowLocking))
+}
+
+// BuildSelect renders q as a SELECT statement for dialect, with the
+// placeholders the dialect expects ($n on postgres, ? elsewhere). The lock
+// clause is only added when rowLocking is true.
+func BuildSelect(q *Query, dialect string, rowLocking bool) (string, []interface{}) {
+	query, args := buildSelect(q, dialect, rowLocking)
+	return Rebind(query, dialect), args
+}
+
+func buildSelect(q *Query, dialect string, rowLocking bool) (string, []interface{}) {
 	var parts []string
 	var args []interface{}
-	
-	fields := strings.Join(q.Fields, ", ")
-	parts = append(parts, fmt.Sprintf("SELECT %s FROM %s", fields, q.Table))
-	
-	if len(q.Wheres) > 0 {
-		var whereParts []string
-		for _, where := range q.Wheres {
-			operator := where.Operator
-			if where.Not {
-				operator = "NOT " + operator
-			}
-			
-			if where.Operator == "IN" {
-				whereParts = append(whereParts, fmt.Sprintf("%s %s %v", where.Field, operator, where.Value))
-			} else {
-				whereParts = append(whereParts, fmt.Sprintf("%s %s ?", where.Field, operator))
-				args = append(args, where.Value)
-			}
+
+	fields := make([]string, len(q.Fields))
+	for i, field := range q.Fields {
+		fields[i] = QuoteRef(field, dialect)
+	}
+	parts = append(parts, fmt.Sprintf("SELECT %s FROM %s", strings.Join(fields, ", "), QuoteRef(q.Table, dialect)))
+
+	for _, join := range q.Joins {
+		parts = append(parts, fmt.Sprintf("%s JOIN %s ON %s %s %s",
+			join.Type,
+			QuoteRef(join.Table, dialect),
+			QuoteRef(join.First, dialect),
+			join.Operator,
+			QuoteRef(join.Second, dialect)))
+	}
+
+	if where, whereArgs := buildWhere(q, dialect); where != "" {
+		parts = append(parts, where)
+		args = append(args, whereArgs...)
+	}
+
+	if len(q.Groups) > 0 {
+		groupParts := make([]string, len(q.Groups))
+		for i, group := range q.Groups {
+			groupParts[i] = QuoteRef(group, dialect)
+		}
+		parts = append(parts, "GROUP BY "+strings.Join(groupParts, ", "))
+	}
+
+	if len(q.Havings) > 0 {
+		var havingParts []string
+		for _, having := range q.Havings {
+			havingParts = append(havingParts, fmt.Sprintf("%s %s ?", HavingRef(having.Field, q.Fields, dialect), having.Operator))
+			args = append(args, having.Value)
 		}
-		parts = append(parts, "WHERE "+strings.Join(whereParts, " AND "))
+		parts = append(parts, "HAVING "+strings.Join(havingParts, " AND "))
 	}
-	
+
 	if len(q.Orders) > 0 {
 		var orderParts []string
 		for _, order := range q.Orders {
-			orderParts = append(orderParts, fmt.Sprintf("%s %s", order.Field, order.Direction))
+			orderParts = append(orderParts, fmt.Sprintf("%s %s", QuoteRef(order.Field, dialect), order.Direction))
 		}
 		parts = append(parts, "ORDER BY "+strings.Join(orderParts, ", "))
 	}
-	
+
 	if q.LimitVal != nil {
 		parts = append(parts, fmt.Sprintf("LIMIT %d", *q.LimitVal))
 	}
-	
+
 	if q.OffsetVal != nil {
 		parts = append(parts, fmt.Sprintf("OFFSET %d", *q.OffsetVal))
 	}
-	
+
+	if q.Lock != "" && rowLocking {
+		parts = append(parts, q.Lock)
+	}
+
 	return strings.Join(parts, " "), args
 }
 
+func buildWhere(q *Query, dialect string) (string, []interface{}) {
+	if len(q.Wheres) == 0 {
+		return "", nil
+	}
+
+	var args []interface{}
+	var whereParts []string
+	for _, where := range q.Wheres {
+		operator := where.Operator
+		if where.Not {
+			operator = "NOT " + operator
+		}
+
+		if where.Operator == "RAW" {
+			values, _ := where.Value.([]interface{})
+			whereParts = append(whereParts, "("+where.Field+")")
+			args = append(args, values...)
+			continue
+		}
+
+		if where.Operator == "FULLTEXT" {
+			query, _ := where.Value.(string)
+			whereParts = append(whereParts, FullTextCondition(q.Table, strings.Split(where.Field, ","), dialect))
+			args = append(args, FullTextQuery(query, dialect))
+			continue
+		}
+
+		if where.Operator == "IN" {
+			values, _ := where.Value.([]interface{})
+			if len(values) == 0 {
+				if where.Not {
+					whereParts = append(whereParts, "1 = 1")
+				} else {
+					whereParts = append(whereParts, "1 = 0")
+				}
+				continue
+			}
+			whereParts = append(whereParts, fmt.Sprintf("%s %s (%s)", QuoteRef(where.Field, dialect), operator, BuildPlaceholders(len(values))))
+			args = append(args, values...)
+		} else if where.Column != "" {
+			whereParts = append(whereParts, fmt.Sprintf("%s %s %s", QuoteRef(where.Field, dialect), operator, QuoteRef(where.Column, dialect)))
+		} else if where.Path != "" {
+			field, _ := JSONExtract(where.Field, where.Path, dialect)
+			whereParts = append(whereParts, fmt.Sprintf("%s %s ?", field, operator))
+			args = append(args, where.Value)
+		} else {
+			whereParts = append(whereParts, fmt.Sprintf("%s %s ?", QuoteRef(where.Field, dialect), operator))
+			args = append(args, where.Value)
+		}
+	}
+
+	return "WHERE " + strings.Join(whereParts, " AND "), args
+}
+
+func (qe *QueryExecutor) database() *DB {
+	if qe.db != nil {
+		return qe.db
+	}
+	return GetDB()
+}
+
+func (qe *QueryExecutor) dialect() string {
+	if db := qe.database(); db != nil {
+		return db.Dialect()
+	}
+	return ""
+}
+
+func (qe *QueryExecutor) supports(feature string) bool {
+	if db := qe.database(); db != nil {
+		return db.Supports(feature)
+	}
+	return false
+}
+
 func intPtr(i int) *int {
 	return &i
 }
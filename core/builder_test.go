@@ -0,0 +1,477 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComposedQueryPlaceholders(t *testing.T) {
+	build := func(db *DB) QueryBuilder {
+		sub := NewQueryExecutorOn(db, "comments", "Comment", noScan).Select("post_id").Where("approved", "=", true)
+		return NewQueryExecutorOn(db, "posts", "Post", noScan).
+			Where("status", "=", "published").
+			WhereRaw("views > ? AND views < ?", 10, 100).
+			WhereInSubquery("id", sub).
+			WhereFullText([]string{"title"}, "go").
+			Limit(5)
+	}
+
+	tests := []struct {
+		dialect string
+		want    string
+		term    string
+	}{
+		{"postgres", `SELECT * FROM "posts" WHERE "status" = $1 AND (views > $2 AND views < $3) AND ("id" IN (SELECT "post_id" FROM "comments" WHERE "approved" = $4)) AND to_tsvector("title") @@ plainto_tsquery($5) LIMIT 5`, "go"},
+		{"sqlite", `SELECT * FROM "posts" WHERE "status" = ? AND (views > ? AND views < ?) AND ("id" IN (SELECT "post_id" FROM "comments" WHERE "approved" = ?)) AND "posts".rowid IN (SELECT rowid FROM "posts_fts" WHERE "posts_fts" MATCH ?) LIMIT 5`, `"go"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			db, rec := newRecordingDB(t, tt.dialect)
+			if _, err := build(db).All(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			got := rec.Last(t)
+			if got.Query != tt.want {
+				t.Errorf("query = %s\nwant    %s", got.Query, tt.want)
+			}
+			wantArgs := []interface{}{"published", int64(10), int64(100), true, tt.term}
+			if !reflect.DeepEqual(got.Args, wantArgs) {
+				t.Errorf("args = %v, want %v", got.Args, wantArgs)
+			}
+		})
+	}
+}
+
+func TestTxRebindsPlaceholders(t *testing.T) {
+	db, rec := newRecordingDB(t, "postgres")
+
+	err := db.WithTransaction(context.Background(), func(ctx context.Context, tx *Tx) error {
+		_, err := db.Exec(ctx, "UPDATE posts SET title = ? WHERE id = ?", "a", 1)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rec.Last(t).Query; got != "UPDATE posts SET title = $1 WHERE id = $2" {
+		t.Errorf("query = %s", got)
+	}
+}
+
+func TestSelectQualifiedColumns(t *testing.T) {
+	tests := []struct {
+		dialect string
+		want    string
+	}{
+		{"postgres", `SELECT "posts".*, "users"."name" AS "author", "users"."id" FROM "posts" INNER JOIN "users" ON "posts"."author_id" = "users"."id" WHERE "users"."active" = $1 ORDER BY "users"."name" ASC`},
+		{"mysql", "SELECT `posts`.*, `users`.`name` AS `author`, `users`.`id` FROM `posts` INNER JOIN `users` ON `posts`.`author_id` = `users`.`id` WHERE `users`.`active` = ? ORDER BY `users`.`name` ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			db, rec := newRecordingDB(t, tt.dialect)
+			_, err := NewQueryExecutorOn(db, "posts", "Post", noScan).
+				Select("posts.*", "users.name AS author", "users.id").
+				Join("users", "posts.author_id", "=", "users.id").
+				Where("users.active", "=", true).
+				OrderBy("users.name", "ASC").
+				AllAsMaps(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := rec.Last(t).Query; got != tt.want {
+				t.Errorf("query = %s\nwant    %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainPrefixPerDialect(t *testing.T) {
+	tests := []struct {
+		dialect string
+		analyze bool
+		want    string
+	}{
+		{"postgres", false, `EXPLAIN SELECT * FROM "posts" WHERE "views" > $1`},
+		{"postgres", true, `EXPLAIN ANALYZE SELECT * FROM "posts" WHERE "views" > $1`},
+		{"mysql", false, "EXPLAIN SELECT * FROM `posts` WHERE `views` > ?"},
+		{"mysql", true, "EXPLAIN ANALYZE SELECT * FROM `posts` WHERE `views` > ?"},
+		{"sqlite", false, `EXPLAIN QUERY PLAN SELECT * FROM "posts" WHERE "views" > ?`},
+	}
+
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t, tt.dialect)
+		query := NewQueryExecutorOn(db, "posts", "Post", noScan).Where("views", ">", 10)
+
+		explain := query.Explain
+		if tt.analyze {
+			explain = query.ExplainAnalyze
+		}
+		if _, err := explain(context.Background()); err != nil {
+			t.Fatalf("%s: %v", tt.dialect, err)
+		}
+		if got := rec.Last(t).Query; got != tt.want {
+			t.Errorf("%s analyze=%v: query = %s\nwant %s", tt.dialect, tt.analyze, got, tt.want)
+		}
+	}
+
+	db, _ := newRecordingDB(t, "sqlite")
+	if _, err := NewQueryExecutorOn(db, "posts", "Post", noScan).ExplainAnalyze(context.Background()); err == nil {
+		t.Error("ExplainAnalyze on sqlite should fail")
+	}
+}
+
+func TestWhereJSONPerDialect(t *testing.T) {
+	tests := []struct {
+		dialect string
+		path    string
+		want    string
+	}{
+		{"postgres", "$.status", `SELECT * FROM "accounts" WHERE "metadata"->>'status' = $1`},
+		{"postgres", "$.billing.plans[0]", `SELECT * FROM "accounts" WHERE "metadata"#>>'{billing,plans,0}' = $1`},
+		{"mysql", "$.status", "SELECT * FROM `accounts` WHERE JSON_EXTRACT(`metadata`, '$.status') = ?"},
+		{"mysql", "billing.plans[0]", "SELECT * FROM `accounts` WHERE JSON_EXTRACT(`metadata`, '$.billing.plans[0]') = ?"},
+		{"sqlite", "$.status", `SELECT * FROM "accounts" WHERE "metadata"->>'status' = ?`},
+		{"sqlite", "$.billing.plans[0]", `SELECT * FROM "accounts" WHERE "metadata"->>'$.billing.plans[0]' = ?`},
+	}
+
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t, tt.dialect)
+		_, err := NewQueryExecutorOn(db, "accounts", "Account", noScan).
+			WhereJSON("metadata", tt.path, "=", "active").
+			All(context.Background())
+		if err != nil {
+			t.Fatalf("%s %s: %v", tt.dialect, tt.path, err)
+		}
+
+		got := rec.Last(t)
+		if got.Query != tt.want {
+			t.Errorf("%s %s: query = %s\nwant %s", tt.dialect, tt.path, got.Query, tt.want)
+		}
+		if !reflect.DeepEqual(got.Args, []interface{}{"active"}) {
+			t.Errorf("%s %s: args = %v", tt.dialect, tt.path, got.Args)
+		}
+	}
+
+	db, _ := newRecordingDB(t, "postgres")
+	_, err := NewQueryExecutorOn(db, "accounts", "Account", noScan).
+		WhereJSON("metadata", "$.status'; DROP TABLE accounts; --", "=", "x").
+		All(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "invalid JSON path") {
+		t.Errorf("err = %v, want invalid JSON path", err)
+	}
+}
+
+func TestWhereFullTextPerDialect(t *testing.T) {
+	tests := []struct {
+		dialect string
+		columns []string
+		want    string
+		term    string
+	}{
+		{"postgres", []string{"title"}, `SELECT * FROM "posts" WHERE to_tsvector("title") @@ plainto_tsquery($1)`, `go "orm"`},
+		{"postgres", []string{"title", "body"}, `SELECT * FROM "posts" WHERE to_tsvector(coalesce("title", '') || ' ' || coalesce("body", '')) @@ plainto_tsquery($1)`, `go "orm"`},
+		{"mysql", []string{"title"}, "SELECT * FROM `posts` WHERE MATCH(`title`) AGAINST(?)", `go "orm"`},
+		{"mysql", []string{"title", "body"}, "SELECT * FROM `posts` WHERE MATCH(`title`, `body`) AGAINST(?)", `go "orm"`},
+		{"sqlite", []string{"title", "body"}, `SELECT * FROM "posts" WHERE "posts".rowid IN (SELECT rowid FROM "posts_fts" WHERE "posts_fts" MATCH ?)`, `"go" """orm"""`},
+	}
+
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t, tt.dialect)
+		_, err := NewQueryExecutorOn(db, "posts", "Post", noScan).
+			WhereFullText(tt.columns, `go "orm"`).
+			All(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.dialect, err)
+		}
+
+		got := rec.Last(t)
+		if got.Query != tt.want {
+			t.Errorf("%s %v: query = %s\nwant %s", tt.dialect, tt.columns, got.Query, tt.want)
+		}
+		if !reflect.DeepEqual(got.Args, []interface{}{tt.term}) {
+			t.Errorf("%s %v: args = %q, want %q", tt.dialect, tt.columns, got.Args, tt.term)
+		}
+	}
+
+	db, _ := newRecordingDB(t, "postgres")
+	if _, err := NewQueryExecutorOn(db, "posts", "Post", noScan).WhereFullText(nil, "go").All(context.Background()); err == nil {
+		t.Error("full-text search without columns should fail")
+	}
+}
+
+func TestHavingInlinesSelectAliases(t *testing.T) {
+	tests := []struct {
+		dialect string
+		want    string
+	}{
+		{"postgres", `SELECT "author_id", COUNT(*) AS "posts", SUM(views) AS "total" FROM "posts" GROUP BY "author_id" HAVING COUNT(*) > $1 AND SUM(views) >= $2 AND "author_id" <> $3`},
+		{"mysql", "SELECT `author_id`, COUNT(*) AS `posts`, SUM(views) AS `total` FROM `posts` GROUP BY `author_id` HAVING COUNT(*) > ? AND SUM(views) >= ? AND `author_id` <> ?"},
+		{"sqlite", `SELECT "author_id", COUNT(*) AS "posts", SUM(views) AS "total" FROM "posts" GROUP BY "author_id" HAVING COUNT(*) > ? AND SUM(views) >= ? AND "author_id" <> ?`},
+	}
+
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t, tt.dialect)
+		_, err := NewQueryExecutorOn(db, "posts", "Post", noScan).
+			Select("author_id", "COUNT(*) AS posts", "SUM(views) AS total").
+			GroupBy("author_id").
+			Having("posts", ">", 1).
+			Having("total", ">=", 100).
+			Having("author_id", "<>", 3).
+			All(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.dialect, err)
+		}
+
+		got := rec.Last(t)
+		if got.Query != tt.want {
+			t.Errorf("%s: query = %s\nwant %s", tt.dialect, got.Query, tt.want)
+		}
+		if !reflect.DeepEqual(got.Args, []interface{}{int64(1), int64(100), int64(3)}) {
+			t.Errorf("%s: args = %v", tt.dialect, got.Args)
+		}
+	}
+}
+
+func TestWhereExistsSQL(t *testing.T) {
+	tests := []struct {
+		dialect string
+		exists  string
+		not     string
+	}{
+		{
+			"postgres",
+			`SELECT * FROM "users" WHERE "active" = $1 AND (EXISTS (SELECT 1 FROM "posts" WHERE "posts"."author_id" = "users"."id" AND "published" = $2))`,
+			`SELECT * FROM "users" WHERE "active" = $1 AND (NOT EXISTS (SELECT 1 FROM "posts" WHERE "posts"."author_id" = "users"."id" AND "published" = $2))`,
+		},
+		{
+			"mysql",
+			"SELECT * FROM `users` WHERE `active` = ? AND (EXISTS (SELECT 1 FROM `posts` WHERE `posts`.`author_id` = `users`.`id` AND `published` = ?))",
+			"SELECT * FROM `users` WHERE `active` = ? AND (NOT EXISTS (SELECT 1 FROM `posts` WHERE `posts`.`author_id` = `users`.`id` AND `published` = ?))",
+		},
+	}
+
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t, tt.dialect)
+		published := func() QueryBuilder {
+			return NewQueryExecutorOn(db, "posts", "Post", noScan).
+				WhereColumn("posts.author_id", "=", "users.id").
+				Where("published", "=", true)
+		}
+		users := func() QueryBuilder {
+			return NewQueryExecutorOn(db, "users", "User", noScan).Where("active", "=", true)
+		}
+
+		if _, err := users().WhereExists(published()).All(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		got := rec.Last(t)
+		if got.Query != tt.exists {
+			t.Errorf("%s: query = %s\nwant %s", tt.dialect, got.Query, tt.exists)
+		}
+		if !reflect.DeepEqual(got.Args, []interface{}{true, true}) {
+			t.Errorf("%s: args = %v", tt.dialect, got.Args)
+		}
+
+		if _, err := users().WhereNotExists(published()).All(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if got := rec.Last(t).Query; got != tt.not {
+			t.Errorf("%s: query = %s\nwant %s", tt.dialect, got, tt.not)
+		}
+	}
+}
+
+func TestWhereHasSQL(t *testing.T) {
+	RegisterRelation("HasPost", "Author", RelationInfo{
+		Table:      "users",
+		LocalKey:   "author_id",
+		ForeignKey: "id",
+		Query: func() QueryBuilder {
+			return NewQueryExecutorOn(nil, "users", "User", noScan)
+		},
+	})
+
+	db, rec := newRecordingDB(t, "postgres")
+	posts := func() QueryBuilder {
+		return NewQueryExecutorOn(db, "posts", "HasPost", noScan).Where("published", "=", true)
+	}
+	ctx := context.Background()
+
+	_, err := posts().WhereHas("author", func(q QueryBuilder) {
+		q.Where("is_active", "=", true)
+	}).All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rec.Last(t)
+	if want := `SELECT * FROM "posts" WHERE "published" = $1 AND (EXISTS (SELECT 1 FROM "users" WHERE "users"."id" = "posts"."author_id" AND "is_active" = $2))`; got.Query != want {
+		t.Errorf("query = %s\nwant    %s", got.Query, want)
+	}
+	if !reflect.DeepEqual(got.Args, []interface{}{true, true}) {
+		t.Errorf("args = %v", got.Args)
+	}
+
+	if _, err := posts().WhereHas("Author", nil).All(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rec.Last(t).Query, `SELECT * FROM "posts" WHERE "published" = $1 AND (EXISTS (SELECT 1 FROM "users" WHERE "users"."id" = "posts"."author_id"))`; got != want {
+		t.Errorf("query = %s\nwant    %s", got, want)
+	}
+
+	if _, err := posts().WhereHas("Editor", nil).All(ctx); err == nil || !strings.Contains(err.Error(), "unknown relation 'Editor' on HasPost") {
+		t.Errorf("err = %v, want unknown relation", err)
+	}
+}
+
+func TestLockClauseOnlyOnSupportingDrivers(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		features []string
+		lock     func(QueryBuilder) QueryBuilder
+		want     string
+	}{
+		{"postgres", []string{FeatureRowLocking}, QueryBuilder.ForUpdate, `SELECT * FROM "accounts" WHERE "id" = $1 LIMIT 1 FOR UPDATE`},
+		{"postgres", []string{FeatureRowLocking}, QueryBuilder.ForShare, `SELECT * FROM "accounts" WHERE "id" = $1 LIMIT 1 FOR SHARE`},
+		{"mysql", []string{FeatureRowLocking}, QueryBuilder.ForUpdate, "SELECT * FROM `accounts` WHERE `id` = ? LIMIT 1 FOR UPDATE"},
+		{"mysql", []string{FeatureRowLocking}, QueryBuilder.ForShare, "SELECT * FROM `accounts` WHERE `id` = ? LIMIT 1 FOR SHARE"},
+		{"sqlite", nil, QueryBuilder.ForUpdate, `SELECT * FROM "accounts" WHERE "id" = ? LIMIT 1`},
+		{"sqlite", nil, QueryBuilder.ForShare, `SELECT * FROM "accounts" WHERE "id" = ? LIMIT 1`},
+	}
+
+	for _, tt := range tests {
+		db, rec := newRecordingDB(t, tt.dialect, tt.features...)
+		query := NewQueryExecutorOn(db, "accounts", "Account", noScan).Where("id", "=", 1)
+		if _, err := tt.lock(query).First(context.Background()); err != nil && err != sql.ErrNoRows {
+			t.Fatalf("%s: %v", tt.dialect, err)
+		}
+		if got := rec.Last(t).Query; got != tt.want {
+			t.Errorf("%s: query = %s\nwant %s", tt.dialect, got, tt.want)
+		}
+	}
+}
+
+func TestPaginateWindowFunctionPerDialect(t *testing.T) {
+	newPost := func() Joinable { return nil }
+	ctx := context.Background()
+
+	db, rec := newRecordingDB(t, "postgres", FeatureWindowFunctions)
+	_, err := NewQueryExecutorOn(db, "posts", "Post", noScan).JoinModel(newPost).
+		Where("published", "=", true).Paginate(ctx, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rec.Statements()[0].Query, `SELECT "posts".*, COUNT(*) OVER() AS "comet_total" FROM "posts" WHERE "published" = $1 LIMIT 10 OFFSET 10`; got != want {
+		t.Errorf("query = %s\nwant    %s", got, want)
+	}
+
+	db, rec = newRecordingDB(t, "sqlite")
+	_, err = NewQueryExecutorOn(db, "posts", "Post", noScan).JoinModel(newPost).
+		Where("published", "=", true).Paginate(ctx, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var queries []string
+	for _, statement := range rec.Statements() {
+		queries = append(queries, statement.Query)
+	}
+	want := []string{
+		`SELECT * FROM "posts" WHERE "published" = ? LIMIT 10 OFFSET 10`,
+		`SELECT COUNT(*) FROM "posts" WHERE "published" = ?`,
+	}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("queries = %q\nwant      %q", queries, want)
+	}
+}
+
+func TestWhenAppliesClauseOnlyWhenTrue(t *testing.T) {
+	db, rec := newRecordingDB(t, "postgres")
+	ctx := context.Background()
+
+	search := func(status string, authorID int) statement {
+		_, err := NewQueryExecutorOn(db, "posts", "Post", noScan).
+			When(status != "", func(q QueryBuilder) QueryBuilder {
+				return q.Where("status", "=", status)
+			}).
+			When(authorID != 0, func(q QueryBuilder) QueryBuilder {
+				return q.Where("author_id", "=", authorID)
+			}).
+			OrderBy("id", "ASC").
+			All(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rec.Last(t)
+	}
+
+	tests := []struct {
+		status   string
+		authorID int
+		query    string
+		args     []interface{}
+	}{
+		{"", 0, `SELECT * FROM "posts" ORDER BY "id" ASC`, []interface{}{}},
+		{"draft", 0, `SELECT * FROM "posts" WHERE "status" = $1 ORDER BY "id" ASC`, []interface{}{"draft"}},
+		{"", 7, `SELECT * FROM "posts" WHERE "author_id" = $1 ORDER BY "id" ASC`, []interface{}{int64(7)}},
+		{"draft", 7, `SELECT * FROM "posts" WHERE "status" = $1 AND "author_id" = $2 ORDER BY "id" ASC`, []interface{}{"draft", int64(7)}},
+	}
+	for _, tt := range tests {
+		got := search(tt.status, tt.authorID)
+		if got.Query != tt.query {
+			t.Errorf("When(%q, %d) query = %s\nwant %s", tt.status, tt.authorID, got.Query, tt.query)
+		}
+		if !reflect.DeepEqual(got.Args, tt.args) {
+			t.Errorf("When(%q, %d) args = %v, want %v", tt.status, tt.authorID, got.Args, tt.args)
+		}
+	}
+}
+
+func TestUpdateWithRawExpressions(t *testing.T) {
+	db, rec := newRecordingDB(t, "postgres")
+
+	_, err := NewQueryExecutorOn(db, "posts", "Post", noScan).Where("id", "=", 1).Update(context.Background(), map[string]interface{}{
+		"view_count": Raw("view_count + 1"),
+		"score":      Raw("score * ?", 2),
+		"title":      "edited",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := rec.Last(t)
+	if want := `UPDATE "posts" SET "score" = score * $1, "title" = $2, "view_count" = view_count + 1 WHERE "id" = $3`; got.Query != want {
+		t.Errorf("query = %s\nwant    %s", got.Query, want)
+	}
+	if !reflect.DeepEqual(got.Args, []interface{}{int64(2), "edited", int64(1)}) {
+		t.Errorf("args = %v", got.Args)
+	}
+}
+
+func TestIncrementSQL(t *testing.T) {
+	db, rec := newRecordingDB(t, "postgres")
+	ctx := context.Background()
+
+	if _, err := NewQueryExecutorOn(db, "posts", "Post", noScan).Where("id", "=", 7).Increment(ctx, "view_count", 3); err != nil {
+		t.Fatal(err)
+	}
+	got := rec.Last(t)
+	if want := `UPDATE "posts" SET "view_count" = "view_count" + $1 WHERE "id" = $2`; got.Query != want {
+		t.Errorf("query = %s\nwant    %s", got.Query, want)
+	}
+	if !reflect.DeepEqual(got.Args, []interface{}{int64(3), int64(7)}) {
+		t.Errorf("args = %v", got.Args)
+	}
+
+	if _, err := NewQueryExecutorOn(db, "posts", "Post", noScan).Decrement(ctx, "stock", 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Last(t); got.Query != `UPDATE "posts" SET "stock" = "stock" + $1` || !reflect.DeepEqual(got.Args, []interface{}{int64(-2)}) {
+		t.Errorf("decrement = %s %v", got.Query, got.Args)
+	}
+}
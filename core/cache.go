@@ -0,0 +1,142 @@
+package core
+
+import (
+	"container/list"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+}
+
+type Copyable interface {
+	CopyModel() interface{}
+}
+
+var (
+	cacheMu       sync.RWMutex
+	queryCache    Cache
+	tableVersions = make(map[string]uint64)
+)
+
+func SetCache(cache Cache) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	queryCache = cache
+}
+
+func GetCache() Cache {
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+	return queryCache
+}
+
+func InvalidateTable(table string) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	tableVersions[strings.ToLower(table)]++
+}
+
+func tableVersion(table string) uint64 {
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+	return tableVersions[strings.ToLower(table)]
+}
+
+var writeStatementRegex = regexp.MustCompile("(?i)^\\s*(?:INSERT\\s+(?:OR\\s+\\w+\\s+|IGNORE\\s+)?INTO|REPLACE\\s+INTO|UPDATE|DELETE\\s+FROM|TRUNCATE(?:\\s+TABLE)?|ALTER\\s+TABLE|DROP\\s+TABLE(?:\\s+IF\\s+EXISTS)?)\\s+[`\"]?(\\w+)")
+
+func writtenTable(query string) string {
+	match := writeStatementRegex.FindStringSubmatch(query)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func copyModel(value interface{}) interface{} {
+	if c, ok := value.(Copyable); ok {
+		return c.CopyModel()
+	}
+	return value
+}
+
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type lruEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *LRUCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.items, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (c *LRUCache) Set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = value
+		entry.expires = expires
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{
+		key:     key,
+		value:   value,
+		expires: expires,
+	})
+
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,142 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+type countingCache struct {
+	*core.LRUCache
+	hits, misses int
+}
+
+func (c *countingCache) Get(key string) (interface{}, bool) {
+	value, ok := c.LRUCache.Get(key)
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return value, ok
+}
+
+func useCache(t *testing.T) *countingCache {
+	cache := &countingCache{LRUCache: core.NewLRUCache(16)}
+	core.SetCache(cache)
+	t.Cleanup(func() { core.SetCache(nil) })
+	return cache
+}
+
+func TestQueryCacheHitAndInvalidation(t *testing.T) {
+	db := openSQLite(t, blogTables...)
+	cache := useCache(t)
+	ctx := context.Background()
+
+	views := func() int64 {
+		rows, err := core.NewQueryExecutorOn(db, "posts", "Post", nil).
+			Select("SUM(views) AS views").
+			Cache(time.Minute).
+			AllAsMaps(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rows[0]["views"].(int64)
+	}
+
+	if got := views(); got != 35 || cache.misses != 1 {
+		t.Fatalf("first read = %d with %d misses, want 35 from the database", got, cache.misses)
+	}
+
+	// Writes that bypass comet don't invalidate, so a hit serves the old rows.
+	if _, err := db.SQL().Exec("UPDATE posts SET views = views + 1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := views(); got != 35 || cache.hits != 1 {
+		t.Errorf("second read = %d with %d hits, want the cached 35", got, cache.hits)
+	}
+
+	if _, err := db.Exec(ctx, "UPDATE posts SET views = views + 1 WHERE id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := views(); got != 39 || cache.misses != 2 {
+		t.Errorf("read after write = %d with %d misses, want 39 from the database", got, cache.misses)
+	}
+
+	count, err := core.NewQueryExecutorOn(db, "posts", "Post", nil).Cache(time.Minute).Count(ctx)
+	if err != nil || count != 3 || cache.misses != 3 {
+		t.Errorf("count = %d, %v with %d misses, want a separate entry", count, err, cache.misses)
+	}
+
+	if _, err := core.NewQueryExecutorOn(db, "users", "User", nil).Cache(time.Minute).Count(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(ctx, "INSERT INTO posts (author_id, title) VALUES (2, 'fourth')"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := core.NewQueryExecutorOn(db, "users", "User", nil).Cache(time.Minute).Count(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if cache.hits != 2 {
+		t.Errorf("hits = %d, a posts write should not invalidate users", cache.hits)
+	}
+}
+
+func TestQueryCacheInvalidatedOnCommit(t *testing.T) {
+	db := openSQLite(t, blogTables...)
+	cache := useCache(t)
+	ctx := context.Background()
+
+	count := func(ctx context.Context) int64 {
+		n, err := core.NewQueryExecutorOn(db, "users", "User", nil).Cache(time.Minute).Count(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	count(ctx)
+	err := db.WithTransaction(ctx, func(ctx context.Context, tx *core.Tx) error {
+		_, err := db.Exec(ctx, "INSERT INTO users (name) VALUES (?)", "Cy")
+		if err != nil {
+			return err
+		}
+		if got := count(ctx); got != 3 {
+			t.Errorf("count inside the transaction = %d, want 3", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := count(ctx); got != 3 || cache.hits != 0 {
+		t.Errorf("count after commit = %d with %d hits, want 3 from the database", got, cache.hits)
+	}
+}
+
+func TestLRUCacheEvictionAndTTL(t *testing.T) {
+	cache := core.NewLRUCache(2)
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+	cache.Get("a")
+	cache.Set("c", 3, 0)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("least recently used entry b was not evicted")
+	}
+	if value, ok := cache.Get("a"); !ok || value != 1 {
+		t.Errorf("a = %v, %v, want 1", value, ok)
+	}
+
+	cache.Set("short", 4, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := cache.Get("short"); ok {
+		t.Error("expired entry was returned")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("len = %d, want only a left", cache.Len())
+	}
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestOnConnectHook(t *testing.T) {
+	name, rec := newRecorder(t)
+
+	db, err := NewDBWithOptions(&testDriver{dialect: "postgres"}, name, DBOptions{
+		OnConnect: func(conn *sql.DB) error {
+			_, err := conn.ExecContext(context.Background(), "SET TIME ZONE 'UTC'")
+			return err
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	statements := rec.Statements()
+	if len(statements) != 1 || statements[0].Query != "SET TIME ZONE 'UTC'" {
+		t.Errorf("statements = %v, want the hook's SET TIME ZONE", statements)
+	}
+}
+
+func TestOnConnectHookErrorAbortsConnect(t *testing.T) {
+	name, _ := newRecorder(t)
+
+	var hooked *sql.DB
+	db, err := NewDBWithOptions(&testDriver{dialect: "postgres"}, name, DBOptions{
+		OnConnect: func(conn *sql.DB) error {
+			hooked = conn
+			return fmt.Errorf("sql_mode rejected")
+		},
+	})
+	if db != nil {
+		t.Error("NewDBWithOptions returned a DB after the hook failed")
+	}
+	if err == nil || !strings.Contains(err.Error(), "connect hook failed: sql_mode rejected") {
+		t.Errorf("err = %v, want the hook's error", err)
+	}
+	if err := hooked.Ping(); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("ping after failed hook = %v, want the connection closed", err)
+	}
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	RuleUnique     = "unique"
+	RuleRequired   = "required"
+	RuleForeignKey = "foreign_key"
+)
+
+var (
+	ErrUniqueViolation     = errors.New("unique constraint violation")
+	ErrForeignKeyViolation = errors.New("foreign key constraint violation")
+)
+
+var constraintErrors = map[error]string{
+	ErrUniqueViolation:     RuleUnique,
+	ErrForeignKeyViolation: RuleForeignKey,
+}
+
+var constraintMessages = map[string]string{
+	RuleUnique:     "already exists",
+	RuleRequired:   "is required",
+	RuleForeignKey: "violates a foreign key constraint",
+}
+
+func NewConstraintError(rule, field string, cause error) *ValidationError {
+	return &ValidationError{
+		Errors: []FieldError{{
+			Field:   field,
+			Rule:    rule,
+			Message: constraintMessages[rule],
+		}},
+		Err: cause,
+	}
+}
+
+func (e *ValidationError) Is(target error) bool {
+	rule, ok := constraintErrors[target]
+	if !ok {
+		return false
+	}
+	for _, fieldErr := range e.Errors {
+		if fieldErr.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func IsUniqueViolation(err error) bool {
+	return errors.Is(err, ErrUniqueViolation)
+}
+
+func IsForeignKeyViolation(err error) bool {
+	return errors.Is(err, ErrForeignKeyViolation)
+}
+
+// SerializationError wraps a database error reporting that a transaction
+// could not be serialized with concurrent ones (SQLSTATE 40001 on PostgreSQL,
+// a deadlock on MySQL). Running the transaction again may succeed.
+type SerializationError struct {
+	Err error
+}
+
+func (e *SerializationError) Error() string {
+	return "serialization failure: " + e.Err.Error()
+}
+
+func (e *SerializationError) Unwrap() error {
+	return e.Err
+}
+
+func IsSerializationFailure(err error) bool {
+	var serializationErr *SerializationError
+	return errors.As(err, &serializationErr)
+}
+
+var keyColumnsPattern = regexp.MustCompile(`\(([^)]*)\)`)
+
+func KeyColumn(detail string) string {
+	match := keyColumnsPattern.FindStringSubmatch(detail)
+	if match == nil {
+		return ""
+	}
+	column := strings.TrimSpace(strings.Split(match[1], ",")[0])
+	return strings.Trim(column, "\"`")
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func (db *DB) CopyFrom(ctx context.Context, table string, columns []string, rows [][]interface{}) error {
+	if IsReadOnly(ctx) {
+		return ErrReadOnly
+	}
+	if err := db.Require(FeatureCopy); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return db.WithTransaction(ctx, func(ctx context.Context, tx *Tx) error {
+		return tx.copyFrom(ctx, table, columns, rows)
+	})
+}
+
+func (tx *Tx) copyFrom(ctx context.Context, table string, columns []string, rows [][]interface{}) error {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = QuoteIdentifier(column, "postgres")
+	}
+
+	stmt, err := tx.tx.PrepareContext(ctx, fmt.Sprintf("COPY %s (%s) FROM STDIN",
+		QuoteIdentifier(table, "postgres"),
+		strings.Join(quoted, ", ")))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return tx.db.driver.TranslateError(err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return tx.db.driver.TranslateError(err)
+	}
+
+	tx.markWritten(table)
+	return nil
+}
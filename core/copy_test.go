@@ -0,0 +1,120 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nitrix4ly/comet/core"
+	"github.com/nitrix4ly/comet/drivers"
+)
+
+const copyTable = "comet_copy_test"
+
+func openPostgres(tb testing.TB) *core.DB {
+	tb.Helper()
+	dsn := os.Getenv("COMET_TEST_POSTGRES_URL")
+	if dsn == "" {
+		tb.Skip("COMET_TEST_POSTGRES_URL is not set")
+	}
+
+	db, err := core.NewDB(&drivers.PostgresDriver{}, dsn)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, statement := range []string{
+		"DROP TABLE IF EXISTS " + copyTable,
+		"CREATE TABLE " + copyTable + " (id SERIAL PRIMARY KEY, name TEXT NOT NULL, score INTEGER NOT NULL)",
+	} {
+		if _, err := db.Exec(ctx, statement); err != nil {
+			db.Close()
+			tb.Fatal(err)
+		}
+	}
+	tb.Cleanup(func() {
+		db.Exec(context.Background(), "DROP TABLE IF EXISTS "+copyTable)
+		db.Close()
+	})
+	return db
+}
+
+func copyRows(n int) [][]interface{} {
+	rows := make([][]interface{}, n)
+	for i := range rows {
+		rows[i] = []interface{}{fmt.Sprintf("row %d", i), i}
+	}
+	return rows
+}
+
+func TestCopyFrom(t *testing.T) {
+	db := openPostgres(t)
+	ctx := context.Background()
+
+	if err := db.CopyFrom(ctx, copyTable, []string{"name", "score"}, copyRows(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	var count, total int
+	if err := db.QueryRow(ctx, "SELECT COUNT(*), SUM(score) FROM "+copyTable).Scan(&count, &total); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1000 || total != 999*1000/2 {
+		t.Errorf("copied %d rows with score sum %d, want 1000 and %d", count, total, 999*1000/2)
+	}
+}
+
+func TestCopyFromUnsupported(t *testing.T) {
+	db, err := core.NewDB(&drivers.SQLiteDriver{}, filepath.Join(t.TempDir(), "copy.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	err = db.CopyFrom(context.Background(), copyTable, []string{"name", "score"}, copyRows(1))
+	var unsupported *core.UnsupportedError
+	if !errors.As(err, &unsupported) || unsupported.Feature != core.FeatureCopy {
+		t.Errorf("err = %v, want an unsupported %s error", err, core.FeatureCopy)
+	}
+}
+
+const benchmarkRows = 10000
+
+func BenchmarkCopyFrom(b *testing.B) {
+	db := openPostgres(b)
+	ctx := context.Background()
+	rows := copyRows(benchmarkRows)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := db.CopyFrom(ctx, copyTable, []string{"name", "score"}, rows); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCopyFromInserts loads the same rows the way CreateMany does, with
+// one INSERT per record, for comparison with BenchmarkCopyFrom.
+func BenchmarkCopyFromInserts(b *testing.B) {
+	db := openPostgres(b)
+	ctx := context.Background()
+	rows := copyRows(benchmarkRows)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := db.WithTransaction(ctx, func(ctx context.Context, tx *core.Tx) error {
+			for _, row := range rows {
+				if _, err := tx.Exec(ctx, "INSERT INTO "+copyTable+" (name, score) VALUES (?, ?)", row...); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNotFound = errors.New("record not found")
+	ErrReadOnly = errors.New("write attempted in read-only context")
+
+	ErrMissingTenant  = errors.New("tenant not set in context")
+	ErrTenantMismatch = errors.New("record belongs to a different tenant")
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+	Err    error        `json:"-"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		if fieldErr.Field == "" {
+			messages[i] = fieldErr.Message
+			continue
+		}
+		messages[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+type UnsupportedError struct {
+	Feature string
+	Dialect string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s is not supported on %s", e.Feature, e.Dialect)
+}
+
+type ScanError struct {
+	Model    string
+	Columns  []string
+	Expected int
+	Err      error
+}
+
+func newScanError(model string, rows *sql.Rows, expected int, err error) *ScanError {
+	columns, _ := rows.Columns()
+	return &ScanError{
+		Model:    model,
+		Columns:  columns,
+		Expected: expected,
+		Err:      err,
+	}
+}
+
+func (e *ScanError) Error() string {
+	if e.Expected > 0 && len(e.Columns) != e.Expected {
+		return fmt.Sprintf("scanning %s: column count mismatch: query returned %d columns, model expects %d", e.Model, len(e.Columns), e.Expected)
+	}
+	return fmt.Sprintf("scanning %s (columns: %s): %v", e.Model, strings.Join(e.Columns, ", "), e.Err)
+}
+
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
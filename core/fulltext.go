@@ -0,0 +1,43 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+func FullTextCondition(table string, columns []string, dialect string) string {
+	refs := make([]string, len(columns))
+	for i, column := range columns {
+		refs[i] = QuoteRef(column, dialect)
+	}
+
+	switch dialect {
+	case "postgres":
+		document := refs[0]
+		if len(refs) > 1 {
+			parts := make([]string, len(refs))
+			for i, ref := range refs {
+				parts[i] = fmt.Sprintf("coalesce(%s, '')", ref)
+			}
+			document = strings.Join(parts, " || ' ' || ")
+		}
+		return fmt.Sprintf("to_tsvector(%s) @@ plainto_tsquery(?)", document)
+	case "mysql":
+		return fmt.Sprintf("MATCH(%s) AGAINST(?)", strings.Join(refs, ", "))
+	default:
+		fts := QuoteIdentifier(table+"_fts", dialect)
+		return fmt.Sprintf("%s.rowid IN (SELECT rowid FROM %s WHERE %s MATCH ?)", QuoteIdentifier(table, dialect), fts, fts)
+	}
+}
+
+func FullTextQuery(query, dialect string) string {
+	if dialect != "sqlite" {
+		return query
+	}
+
+	words := strings.Fields(query)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	return strings.Join(words, " ")
+}
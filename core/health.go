@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultReconnectBackoff    = time.Second
+	defaultMaxReconnectBackoff = 30 * time.Second
+)
+
+func (db *DB) Ping(ctx context.Context) error {
+	return db.conn.PingContext(ctx)
+}
+
+func (db *DB) Healthy() bool {
+	return atomic.LoadInt32(&db.unhealthy) == 0
+}
+
+func (db *DB) startHealthCheck() {
+	db.stop = make(chan struct{})
+	db.stopped = make(chan struct{})
+
+	go func() {
+		defer close(db.stopped)
+
+		ticker := time.NewTicker(db.options.PingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-db.stop:
+				return
+			case <-ticker.C:
+				if err := db.ping(); err != nil {
+					db.reconnect(err)
+				}
+			}
+		}
+	}()
+}
+
+func (db *DB) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), db.options.PingInterval)
+	defer cancel()
+	return db.conn.PingContext(ctx)
+}
+
+func (db *DB) reconnect(cause error) {
+	atomic.StoreInt32(&db.unhealthy, 1)
+	logf("database connection lost: %v", cause)
+
+	backoff := db.options.ReconnectBackoff
+	if backoff <= 0 {
+		backoff = defaultReconnectBackoff
+	}
+	maxBackoff := db.options.MaxReconnectBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxReconnectBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-db.stop:
+			return
+		case <-time.After(backoff):
+		}
+
+		err := db.ping()
+		if err == nil {
+			break
+		}
+		logf("database reconnect attempt %d failed: %v", attempt, err)
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	if db.options.OnConnect != nil {
+		if err := db.options.OnConnect(db.conn); err != nil {
+			logf("connect hook failed after reconnect: %v", err)
+		}
+	}
+
+	atomic.StoreInt32(&db.unhealthy, 0)
+	logf("database connection restored")
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type eventLogger chan string
+
+func (l eventLogger) Printf(format string, args ...interface{}) {
+	select {
+	case l <- fmt.Sprintf(format, args...):
+	default:
+	}
+}
+
+func waitForEvent(t *testing.T, events eventLogger, prefix string) {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if strings.HasPrefix(event, prefix) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %q", prefix)
+		}
+	}
+}
+
+func TestHealthCheckReconnects(t *testing.T) {
+	events := make(eventLogger, 100)
+	previous := GetLogger()
+	SetLogger(events)
+	defer SetLogger(previous)
+
+	name, rec := newRecorder(t)
+	var connects int32
+	db, err := NewDBWithOptions(&testDriver{dialect: "postgres"}, name, DBOptions{
+		PingInterval:        10 * time.Millisecond,
+		ReconnectBackoff:    5 * time.Millisecond,
+		MaxReconnectBackoff: 20 * time.Millisecond,
+		OnConnect: func(conn *sql.DB) error {
+			atomic.AddInt32(&connects, 1)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if !db.Healthy() {
+		t.Error("new connection is not healthy")
+	}
+
+	rec.SetDown(true)
+	waitForEvent(t, events, "database connection lost")
+	if db.Healthy() {
+		t.Error("connection is healthy while the database is down")
+	}
+	waitForEvent(t, events, "database reconnect attempt 1 failed")
+
+	rec.SetDown(false)
+	waitForEvent(t, events, "database connection restored")
+	if !db.Healthy() {
+		t.Error("connection is not healthy after it was restored")
+	}
+	if n := atomic.LoadInt32(&connects); n != 2 {
+		t.Errorf("OnConnect ran %d times, want once on connect and once on reconnect", n)
+	}
+}
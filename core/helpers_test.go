@@ -0,0 +1,242 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type statement struct {
+	Query string
+	Args  []interface{}
+}
+
+type recorder struct {
+	mu         sync.Mutex
+	statements []statement
+	txOptions  []driver.TxOptions
+	txLog      []string
+	down       bool
+}
+
+func (r *recorder) SetDown(down bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.down = down
+}
+
+func (r *recorder) isDown() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.down
+}
+
+func (r *recorder) record(query string, args []driver.NamedValue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	r.statements = append(r.statements, statement{Query: query, Args: values})
+	r.txLog = append(r.txLog, query)
+}
+
+func (r *recorder) logTx(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txLog = append(r.txLog, event)
+}
+
+// TxLog lists BEGIN, COMMIT and ROLLBACK along with the statements run
+// between them.
+func (r *recorder) TxLog() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.txLog...)
+}
+
+func (r *recorder) Statements() []statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]statement(nil), r.statements...)
+}
+
+func (r *recorder) Last(t *testing.T) statement {
+	t.Helper()
+	statements := r.Statements()
+	if len(statements) == 0 {
+		t.Fatal("no statements recorded")
+	}
+	return statements[len(statements)-1]
+}
+
+var (
+	recordersMu sync.Mutex
+	recorders   = make(map[string]*recorder)
+	recorderSeq int
+)
+
+func init() {
+	sql.Register("comet_record", recordDriver{})
+}
+
+type recordDriver struct{}
+
+func (recordDriver) Open(name string) (driver.Conn, error) {
+	recordersMu.Lock()
+	defer recordersMu.Unlock()
+
+	rec, ok := recorders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown recorder %q", name)
+	}
+	if rec.isDown() {
+		return nil, fmt.Errorf("connection refused")
+	}
+	return &recordConn{rec: rec}, nil
+}
+
+type recordConn struct {
+	rec *recorder
+}
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare is not supported")
+}
+
+func (c *recordConn) Close() error {
+	return nil
+}
+
+func (c *recordConn) Ping(ctx context.Context) error {
+	if c.rec.isDown() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (c *recordConn) Begin() (driver.Tx, error) {
+	c.rec.logTx("BEGIN")
+	return recordTx{rec: c.rec}, nil
+}
+
+func (c *recordConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.rec.mu.Lock()
+	c.rec.txOptions = append(c.rec.txOptions, opts)
+	c.rec.mu.Unlock()
+	return c.Begin()
+}
+
+func (c *recordConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.rec.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.rec.record(query, args)
+	if strings.HasPrefix(query, "SELECT COUNT(*)") {
+		return &recordRows{columns: []string{"count"}, values: [][]driver.Value{{int64(0)}}}, nil
+	}
+	return &recordRows{}, nil
+}
+
+type recordTx struct {
+	rec *recorder
+}
+
+func (tx recordTx) Commit() error {
+	tx.rec.logTx("COMMIT")
+	return nil
+}
+
+func (tx recordTx) Rollback() error {
+	tx.rec.logTx("ROLLBACK")
+	return nil
+}
+
+// recordRows answers COUNT queries with zero and everything else with no
+// rows.
+type recordRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *recordRows) Columns() []string { return r.columns }
+func (r *recordRows) Close() error      { return nil }
+
+func (r *recordRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+type testDriver struct {
+	dialect  string
+	features map[string]bool
+}
+
+func (d *testDriver) Connect(dsn string) (*sql.DB, error) {
+	return sql.Open("comet_record", dsn)
+}
+
+func (d *testDriver) Migrate(schema *Schema) error {
+	return nil
+}
+
+func (d *testDriver) BuildQuery(query *Query) (string, []interface{}) {
+	return BuildSelect(query, d.dialect, d.Supports(FeatureRowLocking))
+}
+
+func (d *testDriver) GetDialect() string {
+	return d.dialect
+}
+
+func (d *testDriver) Supports(feature string) bool {
+	return d.features[feature]
+}
+
+func (d *testDriver) TranslateError(err error) error {
+	return err
+}
+
+func newRecorder(t *testing.T) (string, *recorder) {
+	recordersMu.Lock()
+	defer recordersMu.Unlock()
+
+	recorderSeq++
+	name := fmt.Sprintf("%s-%d", t.Name(), recorderSeq)
+	rec := &recorder{}
+	recorders[name] = rec
+	return name, rec
+}
+
+func newRecordingDB(t *testing.T, dialect string, features ...string) (*DB, *recorder) {
+	t.Helper()
+	name, rec := newRecorder(t)
+
+	drv := &testDriver{dialect: dialect, features: make(map[string]bool)}
+	for _, feature := range features {
+		drv.features[feature] = true
+	}
+
+	db, err := NewDB(drv, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, rec
+}
+
+func noScan(rows *sql.Rows) (interface{}, error) {
+	return nil, nil
+}
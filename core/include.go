@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+func (qe *QueryExecutor) loadIncludes(ctx context.Context, items []interface{}) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	for _, name := range qe.query.Includes {
+		relation, ok := LookupRelation(qe.modelType, name)
+		if !ok {
+			return fmt.Errorf("unknown relation '%s' on %s", name, qe.modelType)
+		}
+		if relation.Query == nil || relation.LocalValue == nil || relation.ForeignValue == nil {
+			return fmt.Errorf("relation '%s' on %s cannot be included", name, qe.modelType)
+		}
+		if err := qe.loadInclude(ctx, name, relation, items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (qe *QueryExecutor) loadInclude(ctx context.Context, name string, relation RelationInfo, items []interface{}) error {
+	var keys []interface{}
+	seen := make(map[interface{}]bool)
+	for _, item := range items {
+		key := indirect(relation.LocalValue(item))
+		if key != nil && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	related := make(map[interface{}][]interface{})
+	if len(keys) > 0 {
+		query := relation.Query()
+		if sub, ok := query.(*QueryExecutor); ok && sub.db == nil {
+			sub.db = qe.db
+		}
+
+		results, err := query.WhereIn(relation.ForeignKey, keys).All(ctx)
+		if err != nil {
+			return fmt.Errorf("including %s: %v", name, err)
+		}
+		for _, result := range results {
+			key := indirect(relation.ForeignValue(result))
+			related[key] = append(related[key], result)
+		}
+	}
+
+	for _, item := range items {
+		model, ok := item.(Joinable)
+		if !ok {
+			return fmt.Errorf("%s does not support relations", qe.modelType)
+		}
+
+		matches := related[indirect(relation.LocalValue(item))]
+		if relation.Many {
+			model.SetRelation(name, matches)
+		} else if len(matches) > 0 {
+			model.SetRelation(name, matches[0])
+		}
+	}
+	return nil
+}
+
+func indirect(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Ptr {
+		return value
+	}
+	if v.IsNil() {
+		return nil
+	}
+	return v.Elem().Interface()
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var jsonPathPattern = regexp.MustCompile(`^(?:\.([A-Za-z_]\w*)|\[(\d+)\])`)
+
+type jsonPathSegment struct {
+	Key   string
+	Index string
+}
+
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	rest := strings.TrimSpace(path)
+	if strings.HasPrefix(rest, "$") {
+		rest = rest[1:]
+	} else {
+		rest = "." + rest
+	}
+
+	var segments []jsonPathSegment
+	for rest != "" {
+		match := jsonPathPattern.FindStringSubmatch(rest)
+		if match == nil {
+			return nil, fmt.Errorf("invalid JSON path '%s'", path)
+		}
+		segments = append(segments, jsonPathSegment{Key: match[1], Index: match[2]})
+		rest = rest[len(match[0]):]
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid JSON path '%s'", path)
+	}
+	return segments, nil
+}
+
+func JSONExtract(column, path, dialect string) (string, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	ref := QuoteRef(column, dialect)
+	single := len(segments) == 1 && segments[0].Key != ""
+
+	switch dialect {
+	case "postgres":
+		if single {
+			return fmt.Sprintf("%s->>'%s'", ref, segments[0].Key), nil
+		}
+		keys := make([]string, len(segments))
+		for i, segment := range segments {
+			keys[i] = segment.Key + segment.Index
+		}
+		return fmt.Sprintf("%s#>>'{%s}'", ref, strings.Join(keys, ",")), nil
+	case "mysql":
+		return fmt.Sprintf("JSON_EXTRACT(%s, '%s')", ref, jsonPathString(segments)), nil
+	default:
+		if single {
+			return fmt.Sprintf("%s->>'%s'", ref, segments[0].Key), nil
+		}
+		return fmt.Sprintf("%s->>'%s'", ref, jsonPathString(segments)), nil
+	}
+}
+
+func jsonPathString(segments []jsonPathSegment) string {
+	path := "$"
+	for _, segment := range segments {
+		if segment.Key != "" {
+			path += "." + segment.Key
+		} else {
+			path += "[" + segment.Index + "]"
+		}
+	}
+	return path
+}
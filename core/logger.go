@@ -0,0 +1,34 @@
+package core
+
+import (
+	"log"
+	"os"
+	"sync"
+)
+
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+var (
+	loggerMu sync.RWMutex
+	logger   Logger = log.New(os.Stderr, "comet: ", log.LstdFlags)
+)
+
+func SetLogger(l Logger) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	logger = l
+}
+
+func GetLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return logger
+}
+
+func logf(format string, args ...interface{}) {
+	if l := GetLogger(); l != nil {
+		l.Printf(format, args...)
+	}
+}
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ChangeCreateTable = "create_table"
+	ChangeDropTable   = "drop_table"
+	ChangeAddColumn   = "add_column"
+	ChangeDropColumn  = "drop_column"
+)
+
+type SchemaChange struct {
+	Type  string
+	Model ModelSchema
+	Field FieldSchema
+}
+
+func DiffSchemas(previous, current *Schema) []SchemaChange {
+	var changes []SchemaChange
+
+	var previousTables []ModelSchema
+	previousModels := make(map[string]ModelSchema)
+	if previous != nil {
+		previousTables = previous.tables()
+		for _, model := range previousTables {
+			previousModels[model.TableName] = model
+		}
+	}
+
+	currentTables := make(map[string]bool)
+	for _, model := range current.tables() {
+		currentTables[model.TableName] = true
+
+		old, ok := previousModels[model.TableName]
+		if !ok {
+			changes = append(changes, SchemaChange{
+				Type:  ChangeCreateTable,
+				Model: model,
+			})
+			continue
+		}
+
+		oldFields := make(map[string]bool, len(old.Fields))
+		for _, field := range old.Fields {
+			oldFields[field.Name] = true
+		}
+
+		newFields := make(map[string]bool, len(model.Fields))
+		for _, field := range model.Fields {
+			newFields[field.Name] = true
+			if !oldFields[field.Name] {
+				changes = append(changes, SchemaChange{
+					Type:  ChangeAddColumn,
+					Model: model,
+					Field: field,
+				})
+			}
+		}
+
+		for _, field := range old.Fields {
+			if !newFields[field.Name] {
+				changes = append(changes, SchemaChange{
+					Type:  ChangeDropColumn,
+					Model: model,
+					Field: field,
+				})
+			}
+		}
+	}
+
+	for _, model := range previousTables {
+		if !currentTables[model.TableName] {
+			changes = append(changes, SchemaChange{
+				Type:  ChangeDropTable,
+				Model: model,
+			})
+		}
+	}
+
+	return changes
+}
+
+func (s *Schema) JoinTables() []ModelSchema {
+	models := make(map[string]ModelSchema, len(s.Models))
+	for _, model := range s.Models {
+		models[model.Name] = model
+	}
+
+	var tables []ModelSchema
+	seen := make(map[string]bool)
+	for _, model := range s.Models {
+		for _, relation := range model.Relations {
+			if relation.JoinTable == "" || seen[relation.JoinTable] || len(relation.Fields) != 1 || len(relation.References) != 1 {
+				continue
+			}
+
+			local := model.PrimaryKey()
+			remote := models[relation.Model].PrimaryKey()
+			if local == nil || remote == nil {
+				continue
+			}
+			seen[relation.JoinTable] = true
+
+			columns := []string{relation.Fields[0], relation.References[0]}
+			tables = append(tables, ModelSchema{
+				Name:      ToPascalCase(relation.JoinTable),
+				TableName: relation.JoinTable,
+				Fields: []FieldSchema{
+					joinColumn(columns[0], *local),
+					joinColumn(columns[1], *remote),
+				},
+				Indexes: []Index{{
+					Name:   fmt.Sprintf("%s_%s_key", relation.JoinTable, strings.Join(columns, "_")),
+					Fields: columns,
+					Unique: true,
+				}},
+			})
+		}
+	}
+	return tables
+}
+
+func (s *Schema) tables() []ModelSchema {
+	tables := append([]ModelSchema(nil), s.Models...)
+	return append(tables, s.JoinTables()...)
+}
+
+func joinColumn(column string, key FieldSchema) FieldSchema {
+	return FieldSchema{
+		Name:       column,
+		Column:     column,
+		Type:       key.Type,
+		NativeType: key.NativeType,
+		Precision:  key.Precision,
+		Collation:  key.Collation,
+	}
+}
+
+func RequiresBackfill(field FieldSchema) bool {
+	return !field.Optional && !field.Primary && field.Default == nil && field.Computed == ""
+}
+
+func BackfillValue(field FieldSchema, dialect string) string {
+	if field.Array {
+		return "'{}'"
+	}
+
+	switch field.Type {
+	case "Int", "Float":
+		return "0"
+	case "Boolean":
+		if dialect == "sqlite" {
+			return "0"
+		}
+		return "FALSE"
+	case "DateTime":
+		if dialect == "sqlite" {
+			return "'1970-01-01 00:00:00'"
+		}
+		return "CURRENT_TIMESTAMP"
+	default:
+		return "''"
+	}
+}
@@ -0,0 +1,44 @@
+package core
+
+type NamingStrategy interface {
+	TableName(model string) string
+	ColumnName(field string) string
+	JoinTableName(left, right string) string
+}
+
+type DefaultNamingStrategy struct{}
+
+func (DefaultNamingStrategy) TableName(model string) string {
+	return GetTableName(model)
+}
+
+func (DefaultNamingStrategy) ColumnName(field string) string {
+	return ToSnakeCase(field)
+}
+
+func (DefaultNamingStrategy) JoinTableName(left, right string) string {
+	return ToSnakeCase(left) + "_" + GetTableName(right)
+}
+
+type PrefixNamingStrategy struct {
+	NamingStrategy
+	Prefix string
+}
+
+func NewPrefixNamingStrategy(prefix string, base NamingStrategy) *PrefixNamingStrategy {
+	if base == nil {
+		base = DefaultNamingStrategy{}
+	}
+	return &PrefixNamingStrategy{
+		NamingStrategy: base,
+		Prefix:         prefix,
+	}
+}
+
+func (s *PrefixNamingStrategy) TableName(model string) string {
+	return s.Prefix + s.NamingStrategy.TableName(model)
+}
+
+func (s *PrefixNamingStrategy) JoinTableName(left, right string) string {
+	return s.Prefix + s.NamingStrategy.JoinTableName(left, right)
+}
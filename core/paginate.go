@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+type Page struct {
+	Items   []interface{}
+	Total   int64
+	Page    int
+	PerPage int
+}
+
+func (p *Page) TotalPages() int {
+	if p.PerPage <= 0 {
+		return 0
+	}
+	return int((p.Total + int64(p.PerPage) - 1) / int64(p.PerPage))
+}
+
+func (p *Page) HasNext() bool {
+	return p.Page < p.TotalPages()
+}
+
+type pageResult struct {
+	items []interface{}
+	total int64
+}
+
+func (qe *QueryExecutor) Paginate(ctx context.Context, page, perPage int) (*Page, error) {
+	db := qe.database()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if page < 1 || perPage < 1 {
+		return nil, fmt.Errorf("invalid page %d with %d per page", page, perPage)
+	}
+
+	qe.query.LimitVal = intPtr(perPage)
+	qe.query.OffsetVal = intPtr((page - 1) * perPage)
+
+	result := &Page{Page: page, PerPage: perPage}
+
+	if qe.canCountOver(db) {
+		items, total, err := qe.allWithTotal(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) > 0 {
+			if err := qe.loadIncludes(ctx, items); err != nil {
+				return nil, err
+			}
+			result.Items = items
+			result.Total = total
+			return result, nil
+		}
+	}
+
+	items, err := qe.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	total, err := qe.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Items = items
+	result.Total = total
+	return result, nil
+}
+
+func (qe *QueryExecutor) canCountOver(db *DB) bool {
+	if !db.Supports(FeatureWindowFunctions) || qe.newModel == nil {
+		return false
+	}
+	return len(qe.query.JoinIncludes) == 0 && len(qe.query.Groups) == 0 &&
+		len(qe.query.Fields) == 1 && qe.query.Fields[0] == "*"
+}
+
+func (qe *QueryExecutor) allWithTotal(ctx context.Context) ([]interface{}, int64, error) {
+	db := qe.database()
+
+	q := qe.scoped().clone()
+	q.Fields = []string{q.Table + ".*", "COUNT(*) OVER() AS comet_total"}
+
+	query, args, err := qe.compile(ctx, q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cache, key := qe.cacheKey(ctx, "page", query, args)
+	if cache != nil {
+		if value, ok := cache.Get(key); ok {
+			cached := value.(pageResult)
+			return copyResults(cached.items), cached.total, nil
+		}
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var items []interface{}
+	var total int64
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		model := qe.newModel()
+		dest := append(model.ScanDest(), &total)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, 0, newScanError(qe.modelType, rows, len(dest), err)
+		}
+		model.AfterScan()
+		items = append(items, model)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if cache != nil {
+		cache.Set(key, pageResult{items: copyResults(items), total: total}, qe.cacheTTL)
+	}
+	return items, total, nil
+}
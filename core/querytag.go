@@ -0,0 +1,45 @@
+package core
+
+import (
+	"context"
+	"strings"
+)
+
+type queryTagContextKey struct{}
+
+func WithQueryTag(ctx context.Context, tag string) context.Context {
+	tag = sanitizeQueryTag(tag)
+	if tag == "" {
+		return ctx
+	}
+
+	existing := QueryTags(ctx)
+	tags := make([]string, 0, len(existing)+1)
+	tags = append(append(tags, existing...), tag)
+	return context.WithValue(ctx, queryTagContextKey{}, tags)
+}
+
+func QueryTags(ctx context.Context) []string {
+	tags, _ := ctx.Value(queryTagContextKey{}).([]string)
+	return tags
+}
+
+func tagQuery(ctx context.Context, query string) string {
+	tags := QueryTags(ctx)
+	if len(tags) == 0 {
+		return query
+	}
+	return query + " /* " + strings.Join(tags, ",") + " */"
+}
+
+func sanitizeQueryTag(tag string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case strings.ContainsRune("=,._:/'- ", r):
+			return r
+		}
+		return '_'
+	}, tag))
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestQueryTagComment(t *testing.T) {
+	db, rec := newRecordingDB(t, "postgres")
+	ctx := WithQueryTag(context.Background(), "feature=checkout")
+
+	if _, err := NewQueryExecutorOn(db, "orders", "Order", noScan).Where("id", "=", 1).All(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rec.Last(t).Query, `SELECT * FROM "orders" WHERE "id" = $1 /* feature=checkout */`; got != want {
+		t.Errorf("query = %s\nwant    %s", got, want)
+	}
+
+	nested := WithQueryTag(WithQueryTag(ctx, "route=/cart"), "  ")
+	err := db.WithTransaction(nested, func(ctx context.Context, tx *Tx) error {
+		_, err := tx.Exec(ctx, "DELETE FROM carts")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rec.Last(t).Query, "DELETE FROM carts /* feature=checkout,route=/cart */"; got != want {
+		t.Errorf("query = %s\nwant    %s", got, want)
+	}
+
+	if _, err := db.Exec(context.Background(), "DELETE FROM carts"); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Last(t).Query; got != "DELETE FROM carts" {
+		t.Errorf("untagged query = %s", got)
+	}
+}
+
+func TestQueryTagCannotBreakOutOfComment(t *testing.T) {
+	db, rec := newRecordingDB(t, "postgres")
+
+	for _, tag := range []string{
+		"x */ DROP TABLE users; --",
+		"x /* nested",
+		"line\nbreak*/",
+		"*/*/",
+	} {
+		ctx := WithQueryTag(context.Background(), tag)
+		if _, err := db.Exec(ctx, "SELECT 1"); err != nil {
+			t.Fatal(err)
+		}
+
+		query := rec.Last(t).Query
+		comment := strings.TrimPrefix(query, "SELECT 1 /* ")
+		if comment == query || !strings.HasSuffix(comment, " */") {
+			t.Errorf("tag %q: query = %q", tag, query)
+			continue
+		}
+		body := strings.TrimSuffix(comment, " */")
+		if strings.Contains(body, "*/") || strings.Contains(body, "/*") || strings.ContainsAny(body, "\n;") {
+			t.Errorf("tag %q escapes the comment: %q", tag, query)
+		}
+	}
+}
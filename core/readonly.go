@@ -0,0 +1,16 @@
+package core
+
+import (
+	"context"
+)
+
+type readOnlyContextKey struct{}
+
+func ReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyContextKey{}, true)
+}
+
+func IsReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyContextKey{}).(bool)
+	return readOnly
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReadOnlyContextBlocksWrites(t *testing.T) {
+	db, rec := newRecordingDB(t, "postgres")
+	ctx := ReadOnly(context.Background())
+
+	if _, err := db.Exec(ctx, "DELETE FROM users"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Exec err = %v, want ErrReadOnly", err)
+	}
+	var id int
+	if err := db.ExecReturning(ctx, "INSERT INTO users DEFAULT VALUES", nil, &id); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("ExecReturning err = %v, want ErrReadOnly", err)
+	}
+	if err := db.CopyFrom(ctx, "users", []string{"name"}, [][]interface{}{{"a"}}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("CopyFrom err = %v, want ErrReadOnly", err)
+	}
+
+	err := db.WithTransaction(context.Background(), func(txCtx context.Context, tx *Tx) error {
+		if _, err := tx.Exec(ReadOnly(txCtx), "UPDATE users SET name = ?", "x"); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Tx.Exec err = %v, want ErrReadOnly", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rec.Statements()) != 0 {
+		t.Errorf("writes reached the database: %v", rec.Statements())
+	}
+
+	if _, err := NewQueryExecutorOn(db, "users", "User", noScan).Where("id", "=", 1).All(ctx); err != nil {
+		t.Errorf("read under a read-only context failed: %v", err)
+	}
+	if len(rec.Statements()) != 1 {
+		t.Errorf("read did not reach the database")
+	}
+}
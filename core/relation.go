@@ -0,0 +1,76 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type RelationInfo struct {
+	Table        string
+	LocalKey     string
+	ForeignKey   string
+	Many         bool
+	Query        func() QueryBuilder
+	New          func() Joinable
+	LocalValue   func(model interface{}) interface{}
+	ForeignValue func(related interface{}) interface{}
+}
+
+type Joinable interface {
+	ScanDest() []interface{}
+	AfterScan()
+	SetRelation(name string, value interface{})
+}
+
+var (
+	relationsMu sync.RWMutex
+	relations   = make(map[string]map[string]RelationInfo)
+)
+
+func RegisterRelation(model, name string, relation RelationInfo) {
+	relationsMu.Lock()
+	defer relationsMu.Unlock()
+
+	if relations[model] == nil {
+		relations[model] = make(map[string]RelationInfo)
+	}
+	relations[model][strings.ToLower(name)] = relation
+}
+
+func LookupRelation(model, name string) (RelationInfo, bool) {
+	relationsMu.RLock()
+	defer relationsMu.RUnlock()
+
+	relation, ok := relations[model][strings.ToLower(name)]
+	return relation, ok
+}
+
+func nullableDest(dest []interface{}) ([]interface{}, func() bool) {
+	holders := make([]interface{}, len(dest))
+	direct := make([]bool, len(dest))
+	for i, d := range dest {
+		if reflect.TypeOf(d).Kind() != reflect.Ptr {
+			holders[i] = d
+			direct[i] = true
+			continue
+		}
+		holders[i] = reflect.New(reflect.TypeOf(d)).Interface()
+	}
+
+	return holders, func() bool {
+		present := false
+		for i, holder := range holders {
+			if direct[i] {
+				continue
+			}
+			value := reflect.ValueOf(holder).Elem()
+			if value.IsNil() {
+				continue
+			}
+			reflect.ValueOf(dest[i]).Elem().Set(value.Elem())
+			present = true
+		}
+		return present
+	}
+}
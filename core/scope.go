@@ -0,0 +1,37 @@
+package core
+
+import "sync"
+
+var (
+	defaultScopesMu sync.RWMutex
+	defaultScopes   = make(map[string][]func(*Query))
+)
+
+func RegisterDefaultScope(model string, fn func(*Query)) {
+	defaultScopesMu.Lock()
+	defer defaultScopesMu.Unlock()
+
+	defaultScopes[model] = append(defaultScopes[model], fn)
+}
+
+func DefaultScopes(model string) []func(*Query) {
+	defaultScopesMu.RLock()
+	defer defaultScopesMu.RUnlock()
+
+	scopes := make([]func(*Query), len(defaultScopes[model]))
+	copy(scopes, defaultScopes[model])
+	return scopes
+}
+
+func (q *Query) clone() *Query {
+	c := *q
+	c.Fields = append([]string(nil), q.Fields...)
+	c.Wheres = append([]WhereClause(nil), q.Wheres...)
+	c.Orders = append([]OrderClause(nil), q.Orders...)
+	c.Joins = append([]JoinClause(nil), q.Joins...)
+	c.Groups = append([]string(nil), q.Groups...)
+	c.Havings = append([]WhereClause(nil), q.Havings...)
+	c.Includes = append([]string(nil), q.Includes...)
+	c.JoinIncludes = append([]string(nil), q.JoinIncludes...)
+	return &c
+}
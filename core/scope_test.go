@@ -0,0 +1,40 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDefaultScope(t *testing.T) {
+	RegisterDefaultScope("ScopedDocument", func(q *Query) {
+		q.Wheres = append(q.Wheres, WhereClause{Field: "archived", Operator: "=", Value: false})
+		q.Orders = append(q.Orders, OrderClause{Field: "created_at", Direction: "DESC"})
+	})
+
+	db, rec := newRecordingDB(t, "postgres")
+	documents := func() QueryBuilder {
+		return NewQueryExecutorOn(db, "documents", "ScopedDocument", noScan).Where("owner_id", "=", 7)
+	}
+	ctx := context.Background()
+
+	if _, err := documents().All(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rec.Last(t).Query, `SELECT * FROM "documents" WHERE "owner_id" = $1 AND "archived" = $2 ORDER BY "created_at" DESC`; got != want {
+		t.Errorf("scoped query = %s\nwant           %s", got, want)
+	}
+
+	if _, err := documents().Unscoped().All(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rec.Last(t).Query, `SELECT * FROM "documents" WHERE "owner_id" = $1`; got != want {
+		t.Errorf("unscoped query = %s\nwant             %s", got, want)
+	}
+
+	if _, err := NewQueryExecutorOn(db, "notes", "Note", noScan).All(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rec.Last(t).Query, `SELECT * FROM "notes"`; got != want {
+		t.Errorf("other model query = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type Seeder interface {
+	Name() string
+	Seed(ctx context.Context, db *DB) error
+}
+
+type SeederFunc struct {
+	name string
+	fn   func(ctx context.Context, db *DB) error
+}
+
+func NewSeederFunc(name string, fn func(ctx context.Context, db *DB) error) *SeederFunc {
+	return &SeederFunc{
+		name: name,
+		fn:   fn,
+	}
+}
+
+func (s *SeederFunc) Name() string {
+	return s.name
+}
+
+func (s *SeederFunc) Seed(ctx context.Context, db *DB) error {
+	return s.fn(ctx, db)
+}
+
+var (
+	seedersMu sync.Mutex
+	seeders   []Seeder
+)
+
+func RegisterSeeder(seeder Seeder) {
+	seedersMu.Lock()
+	defer seedersMu.Unlock()
+
+	for i, existing := range seeders {
+		if existing.Name() == seeder.Name() {
+			seeders[i] = seeder
+			return
+		}
+	}
+
+	seeders = append(seeders, seeder)
+}
+
+func RegisterSeed(name string, fn func(ctx context.Context, db *DB) error) {
+	RegisterSeeder(NewSeederFunc(name, fn))
+}
+
+func Seeders() []Seeder {
+	seedersMu.Lock()
+	defer seedersMu.Unlock()
+
+	result := make([]Seeder, len(seeders))
+	copy(result, seeders)
+	return result
+}
+
+func RunSeeders(ctx context.Context, db *DB, names ...string) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	registered := Seeders()
+	toRun := registered
+
+	if len(names) > 0 {
+		byName := make(map[string]Seeder, len(registered))
+		for _, seeder := range registered {
+			byName[seeder.Name()] = seeder
+		}
+
+		toRun = nil
+		for _, name := range names {
+			seeder, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("seeder '%s' is not registered", name)
+			}
+			toRun = append(toRun, seeder)
+		}
+	}
+
+	for _, seeder := range toRun {
+		if err := seeder.Seed(ctx, db); err != nil {
+			return fmt.Errorf("seeder '%s' failed: %v", seeder.Name(), err)
+		}
+	}
+
+	return nil
+}
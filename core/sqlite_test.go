@@ -0,0 +1,466 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nitrix4ly/comet/core"
+	"github.com/nitrix4ly/comet/drivers"
+)
+
+func openSQLite(t *testing.T, statements ...string) *core.DB {
+	t.Helper()
+	db, err := core.NewDB(&drivers.SQLiteDriver{}, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, statement := range statements {
+		if _, err := db.Exec(context.Background(), statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	return db
+}
+
+var blogTables = []string{
+	"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, active BOOLEAN NOT NULL DEFAULT 1)",
+	"CREATE TABLE posts (id INTEGER PRIMARY KEY, author_id INTEGER NOT NULL, title TEXT NOT NULL, views INTEGER NOT NULL DEFAULT 0)",
+	"INSERT INTO users (id, name) VALUES (1, 'Ann'), (2, 'Bob')",
+	"INSERT INTO posts (author_id, title, views) VALUES (1, 'first', 10), (1, 'second', 20), (2, 'third', 5)",
+}
+
+func TestSelectAliasedJoinColumns(t *testing.T) {
+	db := openSQLite(t, blogTables...)
+
+	rows, err := core.NewQueryExecutorOn(db, "posts", "Post", nil).
+		Select("posts.title", "users.name AS author").
+		Join("users", "posts.author_id", "=", "users.id").
+		OrderBy("posts.id", "ASC").
+		AllAsMaps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	if rows[0]["title"] != "first" || rows[0]["author"] != "Ann" || rows[2]["author"] != "Bob" {
+		t.Errorf("rows = %v", rows)
+	}
+}
+
+func TestGroupByAsMaps(t *testing.T) {
+	db := openSQLite(t, blogTables...)
+
+	rows, err := core.NewQueryExecutorOn(db, "posts", "Post", nil).
+		Select("author_id", "COUNT(*) AS posts", "SUM(views) AS views").
+		GroupBy("author_id").
+		OrderBy("author_id", "ASC").
+		AllAsMaps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []map[string]interface{}{
+		{"author_id": int64(1), "posts": int64(2), "views": int64(30)},
+		{"author_id": int64(2), "posts": int64(1), "views": int64(5)},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+}
+
+func TestNestedTransactionRollsBackInnerWork(t *testing.T) {
+	db := openSQLite(t, blogTables...)
+	ctx := context.Background()
+	insert := func(ctx context.Context, name string) error {
+		_, err := db.Exec(ctx, "INSERT INTO users (name) VALUES (?)", name)
+		return err
+	}
+
+	err := db.WithTransaction(ctx, func(ctx context.Context, tx *core.Tx) error {
+		if err := insert(ctx, "Cat"); err != nil {
+			return err
+		}
+		err := db.WithTransaction(ctx, func(ctx context.Context, tx *core.Tx) error {
+			if err := insert(ctx, "Dan"); err != nil {
+				return err
+			}
+			return errors.New("inner failed")
+		})
+		if err == nil {
+			t.Error("inner transaction did not fail")
+		}
+		return insert(ctx, "Eve")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := db.Query(ctx, "SELECT name FROM users WHERE id > 2 ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer names.Close()
+
+	var got []string
+	for names.Next() {
+		var name string
+		if err := names.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, name)
+	}
+	if !reflect.DeepEqual(got, []string{"Cat", "Eve"}) {
+		t.Errorf("users = %v, want [Cat Eve]", got)
+	}
+}
+
+func TestExplainQueryPlan(t *testing.T) {
+	db := openSQLite(t, append(blogTables, "CREATE INDEX posts_author ON posts (author_id)")...)
+
+	plan, err := core.NewQueryExecutorOn(db, "posts", "Post", nil).Where("author_id", "=", 1).Explain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(plan, "posts_author") {
+		t.Errorf("plan does not use the author index:\n%s", plan)
+	}
+}
+
+func TestWhereJSONOnSQLite(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE accounts (id INTEGER PRIMARY KEY, metadata TEXT NOT NULL)",
+		`INSERT INTO accounts (metadata) VALUES ('{"status":"active","billing":{"plans":["pro"]}}'), ('{"status":"closed","billing":{"plans":["free"]}}')`,
+	)
+	ctx := context.Background()
+
+	for path, value := range map[string]string{"$.status": "active", "$.billing.plans[0]": "pro"} {
+		rows, err := core.NewQueryExecutorOn(db, "accounts", "Account", nil).
+			Select("id").
+			WhereJSON("metadata", path, "=", value).
+			AllAsMaps(ctx)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if len(rows) != 1 || rows[0]["id"] != int64(1) {
+			t.Errorf("%s = %v: rows = %v, want account 1", path, value, rows)
+		}
+	}
+}
+
+func TestHavingOnAggregateAlias(t *testing.T) {
+	db := openSQLite(t, blogTables...)
+
+	rows, err := core.NewQueryExecutorOn(db, "posts", "Post", nil).
+		Select("author_id", "COUNT(*) AS posts").
+		GroupBy("author_id").
+		Having("posts", ">", 1).
+		AllAsMaps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []map[string]interface{}{{"author_id": int64(1), "posts": int64(2)}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+}
+
+func TestWhereExistsCorrelated(t *testing.T) {
+	db := openSQLite(t, blogTables...)
+	ctx := context.Background()
+
+	popular := func() core.QueryBuilder {
+		return core.NewQueryExecutorOn(db, "posts", "Post", nil).
+			WhereColumn("posts.author_id", "=", "users.id").
+			Where("views", ">", 15)
+	}
+	names := func(query core.QueryBuilder) []interface{} {
+		rows, err := query.Select("name").OrderBy("id", "ASC").AllAsMaps(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []interface{}
+		for _, row := range rows {
+			names = append(names, row["name"])
+		}
+		return names
+	}
+
+	if got := names(core.NewQueryExecutorOn(db, "users", "User", nil).WhereExists(popular())); !reflect.DeepEqual(got, []interface{}{"Ann"}) {
+		t.Errorf("exists = %v, want [Ann]", got)
+	}
+	if got := names(core.NewQueryExecutorOn(db, "users", "User", nil).WhereNotExists(popular())); !reflect.DeepEqual(got, []interface{}{"Bob"}) {
+		t.Errorf("not exists = %v, want [Bob]", got)
+	}
+}
+
+type joinUser struct {
+	Id     int64
+	Name   string
+	Active bool
+}
+
+func (u *joinUser) ScanDest() []interface{}                    { return []interface{}{&u.Id, &u.Name, &u.Active} }
+func (u *joinUser) AfterScan()                                 {}
+func (u *joinUser) SetRelation(name string, value interface{}) {}
+
+type joinPost struct {
+	Id       int64
+	AuthorId int64
+	Title    string
+	Views    int64
+	Author   *joinUser
+}
+
+func (p *joinPost) ScanDest() []interface{} {
+	return []interface{}{&p.Id, &p.AuthorId, &p.Title, &p.Views}
+}
+
+func (p *joinPost) AfterScan() {}
+
+func (p *joinPost) SetRelation(name string, value interface{}) {
+	if name == "Author" {
+		p.Author = value.(*joinUser)
+	}
+}
+
+func TestJoinIncludeSingleQuery(t *testing.T) {
+	db := openSQLite(t, append(blogTables, "INSERT INTO posts (author_id, title) VALUES (9, 'orphan')")...)
+
+	batched := 0
+	core.RegisterRelation("JoinedPost", "Author", core.RelationInfo{
+		Table:      "users",
+		LocalKey:   "author_id",
+		ForeignKey: "id",
+		New:        func() core.Joinable { return &joinUser{} },
+		Query: func() core.QueryBuilder {
+			batched++
+			return core.NewQueryExecutorOn(db, "users", "JoinedUser", nil)
+		},
+	})
+
+	results, err := core.NewQueryExecutorOn(db, "posts", "JoinedPost", nil).
+		JoinModel(func() core.Joinable { return &joinPost{} }).
+		JoinInclude("Author").
+		Where("views", ">=", 0).
+		OrderBy("id", "ASC").
+		All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batched != 0 {
+		t.Errorf("JoinInclude ran %d extra queries", batched)
+	}
+
+	var got []string
+	for _, result := range results {
+		post := result.(*joinPost)
+		author := "<nil>"
+		if post.Author != nil {
+			author = post.Author.Name
+		}
+		got = append(got, post.Title+":"+author)
+	}
+	want := []string{"first:Ann", "second:Ann", "third:Bob", "orphan:<nil>"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("posts = %v, want %v", got, want)
+	}
+}
+
+func TestValueScalars(t *testing.T) {
+	db := openSQLite(t, blogTables...)
+	ctx := context.Background()
+	posts := func() core.QueryBuilder {
+		return core.NewQueryExecutorOn(db, "posts", "Post", nil)
+	}
+
+	var maxID int
+	if err := posts().Select("MAX(id)").Where("author_id", "=", 1).Value(ctx, &maxID); err != nil {
+		t.Fatal(err)
+	}
+	if maxID != 2 {
+		t.Errorf("max id = %d, want 2", maxID)
+	}
+
+	var title string
+	if err := posts().Select("title").OrderBy("views", "DESC").Value(ctx, &title); err != nil {
+		t.Fatal(err)
+	}
+	if title != "second" {
+		t.Errorf("title = %q, want second", title)
+	}
+
+	if err := posts().Select("title").Where("author_id", "=", 3).Value(ctx, &title); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("no rows: err = %v", err)
+	}
+	if err := posts().Select("MAX(id)").Where("author_id", "=", 3).Value(ctx, &maxID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("NULL aggregate: err = %v", err)
+	}
+
+	if err := posts().Select("title").Where("author_id", "=", 3).ValueOr(ctx, &title, "none"); err != nil || title != "none" {
+		t.Errorf("ValueOr = %q, %v", title, err)
+	}
+	if err := posts().Select("MAX(id)").Where("author_id", "=", 3).ValueOr(ctx, &maxID, 0); err != nil || maxID != 0 {
+		t.Errorf("ValueOr = %d, %v", maxID, err)
+	}
+	if err := posts().Select("title").Where("author_id", "=", 3).ValueOr(ctx, &title, 7); err == nil {
+		t.Error("ValueOr accepted an int fallback for a string")
+	}
+	if err := posts().Select("title").Value(ctx, title); err == nil {
+		t.Error("Value accepted a non-pointer destination")
+	}
+}
+
+func TestAllStopsWhenContextCancelled(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE numbers (n INTEGER NOT NULL)",
+		"WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < 10000) INSERT INTO numbers SELECT n FROM seq",
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scanned := 0
+	scanner := func(rows *sql.Rows) (interface{}, error) {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			return nil, err
+		}
+		scanned++
+		if scanned == 10 {
+			cancel()
+		}
+		return n, nil
+	}
+
+	results, err := core.NewQueryExecutorOn(db, "numbers", "Number", scanner).All(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if results != nil {
+		t.Errorf("got %d partial results", len(results))
+	}
+	if scanned != 10 {
+		t.Errorf("scanned %d rows after cancelling at 10", scanned)
+	}
+}
+
+func TestConcurrentIncrementsBothApply(t *testing.T) {
+	db := openSQLite(t, blogTables...)
+	ctx := context.Background()
+
+	const workers, rounds = 2, 50
+	errs := make(chan error, workers*rounds)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < rounds; j++ {
+				affected, err := core.NewQueryExecutorOn(db, "posts", "Post", nil).Where("title", "=", "first").Increment(ctx, "views", 1)
+				if err == nil && affected != 1 {
+					err = fmt.Errorf("increment affected %d rows", affected)
+				}
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := core.NewQueryExecutorOn(db, "posts", "Post", nil).Where("author_id", "=", 1).Decrement(ctx, "views", 5); err != nil {
+		t.Fatal(err)
+	}
+
+	var views []int
+	rows, err := db.Query(ctx, "SELECT views FROM posts WHERE author_id = 1 ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		views = append(views, n)
+	}
+	if want := []int{10 + workers*rounds - 5, 15}; !reflect.DeepEqual(views, want) {
+		t.Errorf("views = %v, want %v", views, want)
+	}
+}
+
+func TestSQLPassthrough(t *testing.T) {
+	db := openSQLite(t, blogTables...)
+
+	pool := db.SQL()
+	if err := pool.PingContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var name string
+	if err := pool.QueryRowContext(context.Background(), "SELECT name FROM users WHERE id = 2").Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if name != "Bob" {
+		t.Errorf("name = %q, want Bob", name)
+	}
+}
+
+func TestScanErrorReportsColumnMismatch(t *testing.T) {
+	db := openSQLite(t, blogTables...)
+
+	scanPost := func(rows *sql.Rows) (interface{}, error) {
+		post := &joinPost{}
+		return post, rows.Scan(post.ScanDest()...)
+	}
+	query := func() core.QueryBuilder {
+		return core.NewQueryExecutorOn(db, "users", "User", scanPost).
+			JoinModel(func() core.Joinable { return &joinPost{} })
+	}
+
+	want := "scanning User: column count mismatch: query returned 3 columns, model expects 4"
+	if _, err := query().All(context.Background()); err == nil || err.Error() != want {
+		t.Errorf("All err = %v, want %s", err, want)
+	}
+
+	_, err := query().First(context.Background())
+	var scanErr *core.ScanError
+	if !errors.As(err, &scanErr) {
+		t.Fatalf("First err = %v, want a *core.ScanError", err)
+	}
+	if scanErr.Model != "User" || !reflect.DeepEqual(scanErr.Columns, []string{"id", "name", "active"}) || scanErr.Expected != 4 {
+		t.Errorf("scan error = %+v", scanErr)
+	}
+	if scanErr.Err == nil || !strings.Contains(scanErr.Err.Error(), "expected 3 destination arguments") {
+		t.Errorf("underlying err = %v", scanErr.Err)
+	}
+}
+
+func TestScanErrorRecoversScannerPanic(t *testing.T) {
+	db := openSQLite(t, blogTables...)
+
+	_, err := core.NewQueryExecutorOn(db, "users", "User", func(rows *sql.Rows) (interface{}, error) {
+		var fields []interface{}
+		return fields[0], nil
+	}).All(context.Background())
+
+	if err == nil || !strings.HasPrefix(err.Error(), "scanning User (columns: id, name, active): scanner panicked: ") {
+		t.Errorf("err = %v, want the recovered panic", err)
+	}
+}
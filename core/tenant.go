@@ -0,0 +1,16 @@
+package core
+
+import (
+	"context"
+)
+
+type tenantContextKey struct{}
+
+func WithTenant(ctx context.Context, tenant interface{}) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+func TenantFrom(ctx context.Context) (interface{}, bool) {
+	tenant := ctx.Value(tenantContextKey{})
+	return tenant, tenant != nil
+}
@@ -0,0 +1,252 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+type Tx struct {
+	tx        *sql.Tx
+	db        *DB
+	ctx       context.Context
+	root      *Tx
+	savepoint string
+	nextSeq   int
+	done      bool
+	written   map[string]bool
+}
+
+type txContextKey struct{}
+
+var (
+	beginHooksMu sync.RWMutex
+	beginHooks   []func(ctx context.Context, tx *Tx) error
+)
+
+func RegisterBeginHook(fn func(ctx context.Context, tx *Tx) error) {
+	beginHooksMu.Lock()
+	defer beginHooksMu.Unlock()
+
+	beginHooks = append(beginHooks, fn)
+}
+
+func (db *DB) Begin(ctx context.Context) (*Tx, error) {
+	return db.BeginTx(ctx, nil)
+}
+
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.conn.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tx{
+		tx:  tx,
+		db:  db,
+		ctx: ctx,
+	}
+	t.root = t
+
+	beginHooksMu.RLock()
+	hooks := append([]func(ctx context.Context, tx *Tx) error(nil), beginHooks...)
+	beginHooksMu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(ctx, t); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("begin hook failed: %v", err)
+		}
+	}
+	return t, nil
+}
+
+func (tx *Tx) Begin(ctx context.Context) (*Tx, error) {
+	if tx.done {
+		return nil, sql.ErrTxDone
+	}
+
+	tx.root.nextSeq++
+	name := fmt.Sprintf("sp_%d", tx.root.nextSeq)
+
+	if _, err := tx.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+
+	return &Tx{
+		tx:        tx.tx,
+		db:        tx.db,
+		ctx:       ctx,
+		root:      tx.root,
+		savepoint: name,
+	}, nil
+}
+
+func (tx *Tx) IsNested() bool {
+	return tx.savepoint != ""
+}
+
+func (tx *Tx) Commit() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+
+	if tx.IsNested() {
+		_, err := tx.tx.ExecContext(tx.ctx, "RELEASE SAVEPOINT "+tx.savepoint)
+		return err
+	}
+
+	if err := tx.tx.Commit(); err != nil {
+		return err
+	}
+	for table := range tx.written {
+		InvalidateTable(table)
+	}
+	return nil
+}
+
+func (tx *Tx) Rollback() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+
+	if tx.IsNested() {
+		_, err := tx.tx.ExecContext(tx.ctx, "ROLLBACK TO SAVEPOINT "+tx.savepoint)
+		return err
+	}
+
+	return tx.tx.Rollback()
+}
+
+func (tx *Tx) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.tx.QueryContext(ctx, tx.db.bind(ctx, query), args...)
+}
+
+func (tx *Tx) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return tx.tx.QueryRowContext(ctx, tx.db.bind(ctx, query), args...)
+}
+
+func (tx *Tx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if IsReadOnly(ctx) {
+		return nil, ErrReadOnly
+	}
+	result, err := tx.tx.ExecContext(ctx, tx.db.bind(ctx, query), args...)
+	if err != nil {
+		return nil, tx.db.driver.TranslateError(err)
+	}
+	tx.noteWrite(query)
+	return result, nil
+}
+
+func (tx *Tx) ExecReturning(ctx context.Context, query string, args []interface{}, dest ...interface{}) error {
+	if IsReadOnly(ctx) {
+		return ErrReadOnly
+	}
+	if err := tx.tx.QueryRowContext(ctx, tx.db.bind(ctx, query), args...).Scan(dest...); err != nil {
+		return tx.db.driver.TranslateError(err)
+	}
+	tx.noteWrite(query)
+	return nil
+}
+
+func (tx *Tx) SetLocal(ctx context.Context, key string, value interface{}) error {
+	if err := tx.db.Require(FeatureLocalSettings); err != nil {
+		return err
+	}
+
+	_, err := tx.tx.ExecContext(ctx, "SELECT set_config($1, $2, true)", key, fmt.Sprint(value))
+	return err
+}
+
+func (tx *Tx) noteWrite(query string) {
+	if table := writtenTable(query); table != "" {
+		tx.markWritten(table)
+	}
+}
+
+func (tx *Tx) markWritten(table string) {
+	InvalidateTable(table)
+	if tx.root.written == nil {
+		tx.root.written = make(map[string]bool)
+	}
+	tx.root.written[table] = true
+}
+
+func WithTx(ctx context.Context, tx *Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+func TxFromContext(ctx context.Context) *Tx {
+	tx, _ := ctx.Value(txContextKey{}).(*Tx)
+	return tx
+}
+
+func (db *DB) WithTransaction(ctx context.Context, fn func(ctx context.Context, tx *Tx) error) error {
+	return db.WithTransactionOptions(ctx, nil, fn)
+}
+
+func (db *DB) WithTransactionOptions(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context, tx *Tx) error) (err error) {
+	var tx *Tx
+	if parent := TxFromContext(ctx); parent != nil && !parent.done {
+		if opts != nil {
+			return fmt.Errorf("transaction options cannot be applied to a nested transaction")
+		}
+		tx, err = parent.Begin(ctx)
+	} else {
+		tx, err = db.BeginTx(ctx, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(WithTx(ctx, tx), tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%v (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// WithTransactionRetry runs fn in a transaction like WithTransactionOptions
+// and runs it again, up to attempts times in total, while it fails with a
+// serialization failure. fn must be safe to repeat. Inside an existing
+// transaction fn runs once, since only the outermost transaction can retry.
+func (db *DB) WithTransactionRetry(ctx context.Context, opts *sql.TxOptions, attempts int, fn func(ctx context.Context, tx *Tx) error) error {
+	if parent := TxFromContext(ctx); (parent != nil && !parent.done) || attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+		}
+
+		err = db.WithTransactionOptions(ctx, opts, fn)
+		if err == nil {
+			return nil
+		}
+		if !IsSerializationFailure(err) {
+			translated := db.driver.TranslateError(err)
+			if !IsSerializationFailure(translated) {
+				return err
+			}
+			err = translated
+		}
+	}
+	return err
+}
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestNestedTransactionSavepoints(t *testing.T) {
+	db, rec := newRecordingDB(t, "sqlite")
+	failure := errors.New("inner failed")
+
+	err := db.WithTransaction(context.Background(), func(ctx context.Context, tx *Tx) error {
+		if _, err := db.Exec(ctx, "INSERT INTO users (name) VALUES (?)", "outer"); err != nil {
+			return err
+		}
+
+		err := db.WithTransaction(ctx, func(ctx context.Context, inner *Tx) error {
+			if !inner.IsNested() {
+				t.Error("inner transaction is not nested")
+			}
+			if _, err := db.Exec(ctx, "INSERT INTO users (name) VALUES (?)", "inner"); err != nil {
+				return err
+			}
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Errorf("inner err = %v, want %v", err, failure)
+		}
+
+		return db.WithTransaction(ctx, func(ctx context.Context, inner *Tx) error {
+			_, err := db.Exec(ctx, "INSERT INTO users (name) VALUES (?)", "second")
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var queries []string
+	for _, statement := range rec.Statements() {
+		queries = append(queries, statement.Query)
+	}
+	want := []string{
+		"INSERT INTO users (name) VALUES (?)",
+		"SAVEPOINT sp_1",
+		"INSERT INTO users (name) VALUES (?)",
+		"ROLLBACK TO SAVEPOINT sp_1",
+		"SAVEPOINT sp_2",
+		"INSERT INTO users (name) VALUES (?)",
+		"RELEASE SAVEPOINT sp_2",
+	}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("statements:\n%q\nwant:\n%q", queries, want)
+	}
+}
+
+func TestTransactionOptionsPassThrough(t *testing.T) {
+	db, rec := newRecordingDB(t, "postgres")
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.WithTransactionOptions(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead}, func(ctx context.Context, tx *Tx) error {
+		return db.WithTransactionOptions(ctx, &sql.TxOptions{ReadOnly: true}, func(ctx context.Context, tx *Tx) error {
+			return nil
+		})
+	})
+	if err == nil {
+		t.Error("options on a nested transaction should be rejected")
+	}
+
+	want := []driver.TxOptions{
+		{Isolation: driver.IsolationLevel(sql.LevelSerializable), ReadOnly: true},
+		{Isolation: driver.IsolationLevel(sql.LevelRepeatableRead)},
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if !reflect.DeepEqual(rec.txOptions, want) {
+		t.Errorf("BeginTx options = %+v, want %+v", rec.txOptions, want)
+	}
+}
+
+type rlsUserKey struct{}
+
+var registerRLSHook sync.Once
+
+func TestBeginHookSetsLocalInsideTransaction(t *testing.T) {
+	registerRLSHook.Do(func() {
+		RegisterBeginHook(func(ctx context.Context, tx *Tx) error {
+			user, ok := ctx.Value(rlsUserKey{}).(int)
+			if !ok {
+				return nil
+			}
+			if user < 0 {
+				return fmt.Errorf("invalid user %d", user)
+			}
+			return tx.SetLocal(ctx, "app.current_user", user)
+		})
+	})
+
+	db, rec := newRecordingDB(t, "postgres", FeatureLocalSettings)
+	ctx := context.WithValue(context.Background(), rlsUserKey{}, 42)
+
+	err := db.WithTransaction(ctx, func(ctx context.Context, tx *Tx) error {
+		if err := tx.SetLocal(ctx, "app.role", "editor"); err != nil {
+			return err
+		}
+		_, err := db.Exec(ctx, "DELETE FROM documents WHERE id = ?", 7)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"BEGIN",
+		"SELECT set_config($1, $2, true)",
+		"SELECT set_config($1, $2, true)",
+		"DELETE FROM documents WHERE id = $1",
+		"COMMIT",
+	}
+	if got := rec.TxLog(); !reflect.DeepEqual(got, want) {
+		t.Errorf("log:\n%q\nwant:\n%q", got, want)
+	}
+	statements := rec.Statements()
+	if !reflect.DeepEqual(statements[0].Args, []interface{}{"app.current_user", "42"}) || !reflect.DeepEqual(statements[1].Args, []interface{}{"app.role", "editor"}) {
+		t.Errorf("set_config args = %v, %v", statements[0].Args, statements[1].Args)
+	}
+
+	failing := context.WithValue(context.Background(), rlsUserKey{}, -1)
+	_, err = db.Begin(failing)
+	if err == nil || err.Error() != "begin hook failed: invalid user -1" {
+		t.Errorf("err = %v, want the hook's error", err)
+	}
+	if log := rec.TxLog(); log[len(log)-1] != "ROLLBACK" {
+		t.Errorf("failed hook did not roll back: %q", log)
+	}
+}
+
+func TestSetLocalUnsupported(t *testing.T) {
+	db, _ := newRecordingDB(t, "mysql")
+
+	err := db.WithTransaction(context.Background(), func(ctx context.Context, tx *Tx) error {
+		return tx.SetLocal(ctx, "app.current_user", 1)
+	})
+	var unsupported *UnsupportedError
+	if !errors.As(err, &unsupported) || unsupported.Feature != FeatureLocalSettings {
+		t.Errorf("err = %v, want an UnsupportedError", err)
+	}
+}
+
+func TestWithTransactionRetryOnSerializationFailure(t *testing.T) {
+	db, rec := newRecordingDB(t, "postgres")
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	conflict := &SerializationError{Err: errors.New("could not serialize access")}
+
+	calls := 0
+	err := db.WithTransactionRetry(context.Background(), opts, 3, func(ctx context.Context, tx *Tx) error {
+		calls++
+		if calls < 3 {
+			return conflict
+		}
+		_, err := db.Exec(ctx, "UPDATE accounts SET balance = balance - 1")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"BEGIN", "ROLLBACK", "BEGIN", "ROLLBACK", "BEGIN", "UPDATE accounts SET balance = balance - 1", "COMMIT"}
+	if got := rec.TxLog(); !reflect.DeepEqual(got, want) {
+		t.Errorf("tx log = %v, want %v", got, want)
+	}
+	if len(rec.txOptions) != 3 {
+		t.Fatalf("BeginTx called %d times, want 3", len(rec.txOptions))
+	}
+	for i, got := range rec.txOptions {
+		if got.Isolation != driver.IsolationLevel(sql.LevelSerializable) {
+			t.Errorf("attempt %d isolation = %v, want serializable", i+1, got.Isolation)
+		}
+	}
+}
+
+func TestWithTransactionRetryGivesUp(t *testing.T) {
+	db, _ := newRecordingDB(t, "postgres")
+	conflict := &SerializationError{Err: errors.New("could not serialize access")}
+	failure := errors.New("insufficient funds")
+
+	for _, tc := range []struct {
+		err   error
+		calls int
+	}{
+		{conflict, 2},
+		{failure, 1},
+	} {
+		calls := 0
+		err := db.WithTransactionRetry(context.Background(), nil, 2, func(ctx context.Context, tx *Tx) error {
+			calls++
+			return tc.err
+		})
+		if !errors.Is(err, tc.err) || calls != tc.calls {
+			t.Errorf("err = %v after %d calls, want %v after %d", err, calls, tc.err, tc.calls)
+		}
+	}
+
+	calls := 0
+	err := db.WithTransaction(context.Background(), func(ctx context.Context, tx *Tx) error {
+		return db.WithTransactionRetry(ctx, nil, 3, func(ctx context.Context, tx *Tx) error {
+			calls++
+			return conflict
+		})
+	})
+	if !IsSerializationFailure(err) || calls != 1 {
+		t.Errorf("nested err = %v after %d calls, want one attempt", err, calls)
+	}
+}
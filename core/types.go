@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 )
 
@@ -17,64 +18,221 @@ type QueryBuilder interface {
 	Where(field, operator string, value interface{}) QueryBuilder
 	WhereIn(field string, values []interface{}) QueryBuilder
 	WhereNot(field, operator string, value interface{}) QueryBuilder
+	WhereRaw(condition string, args ...interface{}) QueryBuilder
+	WhereJSON(column, path, operator string, value interface{}) QueryBuilder
+	WhereFullText(columns []string, query string) QueryBuilder
+	WhereInSubquery(field string, sub QueryBuilder) QueryBuilder
+	WhereExists(sub QueryBuilder) QueryBuilder
+	WhereNotExists(sub QueryBuilder) QueryBuilder
+	WhereColumn(first, operator, second string) QueryBuilder
+	WhereHas(relation string, constrain func(QueryBuilder)) QueryBuilder
 	OrderBy(field, direction string) QueryBuilder
+	GroupBy(fields ...string) QueryBuilder
+	Having(field, operator string, value interface{}) QueryBuilder
 	Limit(limit int) QueryBuilder
 	Offset(offset int) QueryBuilder
+	ForUpdate() QueryBuilder
+	ForShare() QueryBuilder
 	Select(fields ...string) QueryBuilder
 	Include(relations ...string) QueryBuilder
-	
+	JoinInclude(relations ...string) QueryBuilder
+	Join(table, first, operator, second string) QueryBuilder
+	LeftJoin(table, first, operator, second string) QueryBuilder
+	Unscoped() QueryBuilder
+	Cache(ttl time.Duration) QueryBuilder
+	When(condition bool, fn func(QueryBuilder) QueryBuilder) QueryBuilder
+
 	All(ctx context.Context) ([]interface{}, error)
+	AllAsMaps(ctx context.Context) ([]map[string]interface{}, error)
 	First(ctx context.Context) (interface{}, error)
 	Last(ctx context.Context) (interface{}, error)
 	Count(ctx context.Context) (int64, error)
+	Paginate(ctx context.Context, page, perPage int) (*Page, error)
+	Value(ctx context.Context, dest interface{}) error
+	ValueOr(ctx context.Context, dest interface{}, fallback interface{}) error
 	Exists(ctx context.Context) (bool, error)
+	Explain(ctx context.Context) (string, error)
+	ExplainAnalyze(ctx context.Context) (string, error)
+	Update(ctx context.Context, values map[string]interface{}) (int64, error)
+	Increment(ctx context.Context, column string, by int) (int64, error)
+	Decrement(ctx context.Context, column string, by int) (int64, error)
 }
 
 type Driver interface {
 	Connect(dsn string) (*sql.DB, error)
 	Migrate(schema *Schema) error
+	// Deprecated: Comet no longer calls BuildQuery; QueryExecutor builds
+	// queries and DB rebinds their placeholders when they run. Implementations
+	// can return BuildSelect(query, dialect, rowLocking).
 	BuildQuery(query *Query) (string, []interface{})
 	GetDialect() string
+	Supports(feature string) bool
+	TranslateError(err error) error
 }
 
+const (
+	FeatureReturning       = "returning"
+	FeatureRowLocking      = "row_locking"
+	FeaturePartialIndexes  = "partial_indexes"
+	FeatureArrays          = "arrays"
+	FeatureInsertIgnore    = "insert_ignore"
+	FeatureReplace         = "replace"
+	FeatureLocalSettings   = "local_settings"
+	FeatureWindowFunctions = "window_functions"
+	FeatureCopy            = "copy"
+)
+
 type Schema struct {
 	Models []ModelSchema `json:"models"`
+	Enums  []EnumSchema  `json:"enums"`
+}
+
+type EnumSchema struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
 }
 
 type ModelSchema struct {
-	Name      string        `json:"name"`
-	TableName string        `json:"table_name"`
-	Fields    []FieldSchema `json:"fields"`
-	Relations []Relation    `json:"relations"`
+	Name        string        `json:"name"`
+	TableName   string        `json:"table_name"`
+	Fields      []FieldSchema `json:"fields"`
+	Relations   []Relation    `json:"relations"`
+	Indexes     []Index       `json:"indexes"`
+	Checks      []string      `json:"checks"`
+	Scopes      []Scope       `json:"scopes"`
+	Projections []Projection  `json:"projections"`
+	Comment     string        `json:"comment"`
+	Timestamps  []FieldSchema `json:"timestamps"`
 }
 
 type FieldSchema struct {
 	Name         string      `json:"name"`
+	Column       string      `json:"column"`
 	Type         string      `json:"type"`
 	Optional     bool        `json:"optional"`
+	Array        bool        `json:"array"`
 	Unique       bool        `json:"unique"`
 	Primary      bool        `json:"primary"`
 	AutoGen      bool        `json:"auto_gen"`
 	Default      interface{} `json:"default"`
 	DatabaseType string      `json:"database_type"`
+	Check        string      `json:"check"`
+	Tenant       bool        `json:"tenant"`
+	NativeType   string      `json:"native_type"`
+	Precision    *int        `json:"precision"`
+	GoType       string      `json:"go_type"`
+	Enum         bool        `json:"enum"`
+	Searchable   bool        `json:"searchable"`
+	Computed     string      `json:"computed"`
+	Collation    string      `json:"collation"`
+	Comment      string      `json:"comment"`
+	Hidden       bool        `json:"hidden"`
+	ReadOnly     bool        `json:"read_only"`
+}
+
+func (f FieldSchema) ColumnName() string {
+	if f.Column != "" {
+		return f.Column
+	}
+	return ToSnakeCase(f.Name)
+}
+
+func (m ModelSchema) ColumnFor(name string) string {
+	for _, field := range m.Fields {
+		if field.Name == name || field.ColumnName() == name {
+			return field.ColumnName()
+		}
+	}
+	return name
+}
+
+func (m ModelSchema) TableFields() []FieldSchema {
+	fields := append([]FieldSchema(nil), m.Fields...)
+	return append(fields, m.Timestamps...)
+}
+
+func (m ModelSchema) PrimaryKey() *FieldSchema {
+	for i := range m.Fields {
+		if m.Fields[i].Primary {
+			return &m.Fields[i]
+		}
+	}
+	return nil
+}
+
+func (m ModelSchema) PrimaryKeyColumns() []string {
+	var columns []string
+	for _, field := range m.Fields {
+		if field.Primary {
+			columns = append(columns, field.ColumnName())
+		}
+	}
+	return columns
+}
+
+func (m ModelSchema) IndexColumns(index Index) []string {
+	columns := make([]string, len(index.Fields))
+	for i, name := range index.Fields {
+		columns[i] = m.ColumnFor(name)
+	}
+	return columns
+}
+
+type SyncResult struct {
+	Inserted int64
+	Updated  int64
+	Deleted  int64
 }
 
 type Relation struct {
-	Name      string   `json:"name"`
-	Type      string   `json:"type"`
-	Model     string   `json:"model"`
-	Fields    []string `json:"fields"`
+	Name       string   `json:"name"`
+	FieldName  string   `json:"field_name"`
+	Type       string   `json:"type"`
+	Model      string   `json:"model"`
+	Fields     []string `json:"fields"`
 	References []string `json:"references"`
+	Models     []string `json:"models"`
+	JoinTable  string   `json:"join_table"`
+}
+
+type Scope struct {
+	Name      string `json:"name"`
+	Condition string `json:"condition"`
+}
+
+type Projection struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"`
+}
+
+type Index struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"`
+	Unique bool     `json:"unique"`
+	Where  string   `json:"where"`
 }
 
 type Query struct {
-	Table     string
-	Fields    []string
-	Wheres    []WhereClause
-	Orders    []OrderClause
-	LimitVal  *int
-	OffsetVal *int
-	Includes  []string
+	Table        string
+	Fields       []string
+	Joins        []JoinClause
+	Wheres       []WhereClause
+	Groups       []string
+	Havings      []WhereClause
+	Orders       []OrderClause
+	LimitVal     *int
+	OffsetVal    *int
+	Includes     []string
+	JoinIncludes []string
+	Lock         string
+}
+
+type JoinClause struct {
+	Type     string
+	Table    string
+	First    string
+	Operator string
+	Second   string
 }
 
 type WhereClause struct {
@@ -82,6 +240,9 @@ type WhereClause struct {
 	Operator string
 	Value    interface{}
 	Not      bool
+	Path     string
+	Subquery QueryBuilder
+	Column   string
 }
 
 type OrderClause struct {
@@ -113,35 +274,172 @@ func (b *BaseModel) Touch() {
 }
 
 type DB struct {
-	conn   *sql.DB
-	driver Driver
+	conn      *sql.DB
+	driver    Driver
+	options   DBOptions
+	unhealthy int32
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+type DBOptions struct {
+	OnConnect           func(*sql.DB) error
+	PingInterval        time.Duration
+	ReconnectBackoff    time.Duration
+	MaxReconnectBackoff time.Duration
 }
 
 func NewDB(driver Driver, dsn string) (*DB, error) {
+	return NewDBWithOptions(driver, dsn, DBOptions{})
+}
+
+func NewDBWithOptions(driver Driver, dsn string, options DBOptions) (*DB, error) {
 	conn, err := driver.Connect(dsn)
 	if err != nil {
 		return nil, err
 	}
-	
-	return &DB{
-		conn:   conn,
-		driver: driver,
-	}, nil
+
+	if options.OnConnect != nil {
+		if err := options.OnConnect(conn); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("connect hook failed: %v", err)
+		}
+	}
+
+	db := &DB{
+		conn:    conn,
+		driver:  driver,
+		options: options,
+	}
+
+	if options.PingInterval > 0 {
+		db.startHealthCheck()
+	}
+
+	return db, nil
 }
 
 func (db *DB) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	return db.conn.QueryContext(ctx, query, args...)
+	if tx := db.txFrom(ctx); tx != nil {
+		return tx.Query(ctx, query, args...)
+	}
+	return db.conn.QueryContext(ctx, db.bind(ctx, query), args...)
 }
 
 func (db *DB) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	return db.conn.QueryRowContext(ctx, query, args...)
+	if tx := db.txFrom(ctx); tx != nil {
+		return tx.QueryRow(ctx, query, args...)
+	}
+	return db.conn.QueryRowContext(ctx, db.bind(ctx, query), args...)
 }
 
 func (db *DB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	return db.conn.ExecContext(ctx, query, args...)
+	if IsReadOnly(ctx) {
+		return nil, ErrReadOnly
+	}
+	if tx := db.txFrom(ctx); tx != nil {
+		return tx.Exec(ctx, query, args...)
+	}
+
+	result, err := db.conn.ExecContext(ctx, db.bind(ctx, query), args...)
+	if err != nil {
+		return nil, db.driver.TranslateError(err)
+	}
+	if table := writtenTable(query); table != "" {
+		InvalidateTable(table)
+	}
+	return result, nil
+}
+
+func (db *DB) ExecReturning(ctx context.Context, query string, args []interface{}, dest ...interface{}) error {
+	if IsReadOnly(ctx) {
+		return ErrReadOnly
+	}
+	if tx := db.txFrom(ctx); tx != nil {
+		return tx.ExecReturning(ctx, query, args, dest...)
+	}
+
+	if err := db.conn.QueryRowContext(ctx, db.bind(ctx, query), args...).Scan(dest...); err != nil {
+		return db.driver.TranslateError(err)
+	}
+	if table := writtenTable(query); table != "" {
+		InvalidateTable(table)
+	}
+	return nil
+}
+
+func (db *DB) txFrom(ctx context.Context) *Tx {
+	if tx := TxFromContext(ctx); tx != nil && tx.db == db {
+		return tx
+	}
+	return nil
+}
+
+func (db *DB) bind(ctx context.Context, query string) string {
+	return tagQuery(ctx, Rebind(query, db.Dialect()))
+}
+
+func (db *DB) SQL() *sql.DB {
+	return db.conn
+}
+
+func (db *DB) Dialect() string {
+	return db.driver.GetDialect()
+}
+
+func (db *DB) Supports(feature string) bool {
+	return db.driver.Supports(feature)
+}
+
+func (db *DB) Require(feature string) error {
+	if !db.Supports(feature) {
+		return &UnsupportedError{Feature: feature, Dialect: db.Dialect()}
+	}
+	return nil
+}
+
+func (db *DB) SetForeignKeys(ctx context.Context, enabled bool) error {
+	if IsReadOnly(ctx) {
+		return ErrReadOnly
+	}
+
+	var query string
+	switch db.Dialect() {
+	case "sqlite":
+		query = "PRAGMA foreign_keys = OFF"
+		if enabled {
+			query = "PRAGMA foreign_keys = ON"
+		}
+	case "mysql":
+		query = "SET FOREIGN_KEY_CHECKS = 0"
+		if enabled {
+			query = "SET FOREIGN_KEY_CHECKS = 1"
+		}
+	default:
+		return fmt.Errorf("toggling foreign keys is not supported for %s", db.Dialect())
+	}
+
+	if tx := db.txFrom(ctx); tx != nil {
+		if db.Dialect() == "sqlite" {
+			return fmt.Errorf("sqlite ignores foreign_keys inside a transaction, call SetForeignKeys before starting it")
+		}
+		_, err := tx.tx.ExecContext(ctx, query)
+		return err
+	}
+
+	if db.conn.Stats().MaxOpenConnections != 1 {
+		return fmt.Errorf("foreign keys can only be toggled on a single-connection pool (db.SQL().SetMaxOpenConns(1)) or inside a transaction")
+	}
+	_, err := db.conn.ExecContext(ctx, query)
+	return err
 }
 
 func (db *DB) Close() error {
+	if db.stop != nil {
+		close(db.stop)
+		<-db.stopped
+		db.stop = nil
+	}
 	return db.conn.Close()
 }
 
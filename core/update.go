@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type RawExpr struct {
+	SQL  string
+	Args []interface{}
+}
+
+func Raw(sql string, args ...interface{}) RawExpr {
+	return RawExpr{SQL: sql, Args: args}
+}
+
+func (qe *QueryExecutor) Update(ctx context.Context, values map[string]interface{}) (int64, error) {
+	db := qe.database()
+	if db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+
+	if len(values) == 0 {
+		return 0, fmt.Errorf("update needs at least one column")
+	}
+
+	q, err := qe.prepare(ctx, qe.scoped())
+	if err != nil {
+		return 0, err
+	}
+
+	if len(q.Joins) > 0 || len(q.Groups) > 0 || q.LimitVal != nil || q.OffsetVal != nil {
+		return 0, fmt.Errorf("update cannot be combined with joins, grouping, limit or offset")
+	}
+
+	query, args := buildUpdateQuery(q, values, qe.dialect())
+	result, err := db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (qe *QueryExecutor) Increment(ctx context.Context, column string, by int) (int64, error) {
+	return qe.Update(ctx, map[string]interface{}{
+		column: Raw(QuoteIdentifier(column, qe.dialect())+" + ?", by),
+	})
+}
+
+func (qe *QueryExecutor) Decrement(ctx context.Context, column string, by int) (int64, error) {
+	return qe.Increment(ctx, column, -by)
+}
+
+func buildUpdateQuery(q *Query, values map[string]interface{}, dialect string) (string, []interface{}) {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var args []interface{}
+	sets := make([]string, len(columns))
+	for i, column := range columns {
+		if raw, ok := values[column].(RawExpr); ok {
+			sets[i] = fmt.Sprintf("%s = %s", QuoteIdentifier(column, dialect), raw.SQL)
+			args = append(args, raw.Args...)
+			continue
+		}
+		sets[i] = fmt.Sprintf("%s = ?", QuoteIdentifier(column, dialect))
+		args = append(args, values[column])
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s", QuoteRef(q.Table, dialect), strings.Join(sets, ", "))
+	if where, whereArgs := buildWhere(q, dialect); where != "" {
+		query += " " + where
+		args = append(args, whereArgs...)
+	}
+	return query, args
+}
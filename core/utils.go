@@ -2,34 +2,42 @@ package core
 
 import (
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
+var (
+	identifierRefPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.([A-Za-z_][A-Za-z0-9_]*|\*))?$`)
+	aliasPattern         = regexp.MustCompile(`^(.+?)\s+(?i:as)\s+([A-Za-z_][A-Za-z0-9_]*)$`)
+)
+
 func ToSnakeCase(str string) string {
 	var result strings.Builder
-	
+
 	for i, r := range str {
 		if i > 0 && unicode.IsUpper(r) {
 			result.WriteRune('_')
 		}
 		result.WriteRune(unicode.ToLower(r))
 	}
-	
+
 	return result.String()
 }
 
 func ToPascalCase(str string) string {
 	parts := strings.Split(str, "_")
 	var result strings.Builder
-	
+
 	for _, part := range parts {
 		if len(part) > 0 {
 			result.WriteRune(unicode.ToUpper(rune(part[0])))
 			result.WriteString(part[1:])
 		}
 	}
-	
+
 	return result.String()
 }
 
@@ -45,9 +53,9 @@ func ToPlural(str string) string {
 	if strings.HasSuffix(str, "y") {
 		return str[:len(str)-1] + "ies"
 	}
-	if strings.HasSuffix(str, "s") || strings.HasSuffix(str, "x") || 
-	   strings.HasSuffix(str, "z") || strings.HasSuffix(str, "ch") || 
-	   strings.HasSuffix(str, "sh") {
+	if strings.HasSuffix(str, "s") || strings.HasSuffix(str, "x") ||
+		strings.HasSuffix(str, "z") || strings.HasSuffix(str, "ch") ||
+		strings.HasSuffix(str, "sh") {
 		return str + "es"
 	}
 	return str + "s"
@@ -62,7 +70,7 @@ func IsZeroValue(v interface{}) bool {
 	if v == nil {
 		return true
 	}
-	
+
 	rv := reflect.ValueOf(v)
 	switch rv.Kind() {
 	case reflect.String:
@@ -80,13 +88,30 @@ func IsZeroValue(v interface{}) bool {
 	case reflect.Ptr, reflect.Interface:
 		return rv.IsNil()
 	}
-	
+
 	return reflect.DeepEqual(v, reflect.Zero(rv.Type()).Interface())
 }
 
+func ValuesEqual(a, b interface{}) bool {
+	if at, ok := a.(time.Time); ok {
+		bt, ok := b.(time.Time)
+		return ok && at.Equal(bt)
+	}
+
+	if at, ok := a.(*time.Time); ok {
+		bt, ok := b.(*time.Time)
+		if !ok || at == nil || bt == nil {
+			return ok && at == nil && bt == nil
+		}
+		return at.Equal(*bt)
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
 func GetSQLType(goType string, driver string) string {
 	baseType := strings.TrimSuffix(goType, "?")
-	
+
 	switch driver {
 	case "postgres":
 		return getPostgresType(baseType)
@@ -112,7 +137,9 @@ func getPostgresType(goType string) string {
 	case "float64", "Float":
 		return "DOUBLE PRECISION"
 	case "time.Time", "DateTime":
-		return "TIMESTAMP"
+		return "TIMESTAMPTZ"
+	case "[]byte", "Bytes":
+		return "BYTEA"
 	default:
 		return "TEXT"
 	}
@@ -132,6 +159,8 @@ func getMySQLType(goType string) string {
 		return "DOUBLE"
 	case "time.Time", "DateTime":
 		return "TIMESTAMP"
+	case "[]byte", "Bytes":
+		return "BLOB"
 	default:
 		return "TEXT"
 	}
@@ -149,6 +178,8 @@ func getSQLiteType(goType string) string {
 		return "REAL"
 	case "time.Time", "DateTime":
 		return "DATETIME"
+	case "[]byte", "Bytes":
+		return "BLOB"
 	default:
 		return "TEXT"
 	}
@@ -158,15 +189,120 @@ func EscapeIdentifier(identifier string) string {
 	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
 }
 
+func QuoteIdentifier(identifier, dialect string) string {
+	if dialect == "mysql" {
+		return EscapeIdentifier(identifier)
+	}
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func QuoteLiteral(value, dialect string) string {
+	value = strings.ReplaceAll(value, "'", "''")
+	if dialect == "mysql" {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+	}
+	return "'" + value + "'"
+}
+
+func QuoteRef(ref, dialect string) string {
+	ref = strings.TrimSpace(ref)
+
+	if match := aliasPattern.FindStringSubmatch(ref); match != nil {
+		return QuoteRef(match[1], dialect) + " AS " + QuoteIdentifier(match[2], dialect)
+	}
+
+	if !identifierRefPattern.MatchString(ref) {
+		return ref
+	}
+
+	parts := strings.Split(ref, ".")
+	for i, part := range parts {
+		if part != "*" {
+			parts[i] = QuoteIdentifier(part, dialect)
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func EscapeLike(value string) string {
+	replacer := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+	return replacer.Replace(value)
+}
+
+func HavingRef(field string, selected []string, dialect string) string {
+	name := strings.TrimSpace(field)
+	for _, selectField := range selected {
+		if match := aliasPattern.FindStringSubmatch(strings.TrimSpace(selectField)); match != nil && match[2] == name {
+			return QuoteRef(match[1], dialect)
+		}
+	}
+	return QuoteRef(field, dialect)
+}
+
 func BuildPlaceholders(count int) string {
 	if count <= 0 {
 		return ""
 	}
-	
+
 	placeholders := make([]string, count)
 	for i := range placeholders {
 		placeholders[i] = "?"
 	}
-	
+
 	return strings.Join(placeholders, ", ")
 }
+
+func Rebind(query, dialect string) string {
+	if dialect != "postgres" || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(query) {
+				if query[end] == c {
+					if end+1 < len(query) && query[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			b.WriteString(query[i:min(end+1, len(query))])
+			i = end
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i - 1
+			}
+			b.WriteString(query[i : i+end+1])
+			i += end
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				b.WriteString(query[i:])
+				i = len(query)
+				continue
+			}
+			b.WriteString(query[i : i+end+4])
+			i += end + 3
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package core
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		dialect string
+		want    string
+	}{
+		{"sqlite untouched", "SELECT * FROM t WHERE a = ? AND b = ?", "sqlite", "SELECT * FROM t WHERE a = ? AND b = ?"},
+		{"mysql untouched", "SELECT * FROM t WHERE a = ?", "mysql", "SELECT * FROM t WHERE a = ?"},
+		{"postgres numbered", "SELECT * FROM t WHERE a = ? AND b IN (?, ?)", "postgres", "SELECT * FROM t WHERE a = $1 AND b IN ($2, $3)"},
+		{"string literal", "SELECT * FROM t WHERE a = '?' AND b = ?", "postgres", "SELECT * FROM t WHERE a = '?' AND b = $1"},
+		{"escaped quote", "SELECT * FROM t WHERE a = 'it''s ?' AND b = ?", "postgres", "SELECT * FROM t WHERE a = 'it''s ?' AND b = $1"},
+		{"quoted identifier", `SELECT "what?" FROM t WHERE a = ?`, "postgres", `SELECT "what?" FROM t WHERE a = $1`},
+		{"line comment", "SELECT * FROM t -- why?\nWHERE a = ?", "postgres", "SELECT * FROM t -- why?\nWHERE a = $1"},
+		{"block comment", "SELECT * FROM t WHERE a = ? /* tag? */", "postgres", "SELECT * FROM t WHERE a = $1 /* tag? */"},
+		{"unterminated comment", "SELECT ? /* tag?", "postgres", "SELECT $1 /* tag?"},
+		{"no placeholders", "SELECT 1", "postgres", "SELECT 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Rebind(tt.query, tt.dialect); got != tt.want {
+				t.Errorf("Rebind(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteRef(t *testing.T) {
+	tests := []struct {
+		ref     string
+		dialect string
+		want    string
+	}{
+		{"title", "postgres", `"title"`},
+		{"posts.title", "postgres", `"posts"."title"`},
+		{"posts.*", "postgres", `"posts".*`},
+		{"users.name AS author", "postgres", `"users"."name" AS "author"`},
+		{"users.name as author", "mysql", "`users`.`name` AS `author`"},
+		{"COUNT(*) AS total", "sqlite", `COUNT(*) AS "total"`},
+		{"LOWER(title)", "postgres", "LOWER(title)"},
+	}
+
+	for _, tt := range tests {
+		if got := QuoteRef(tt.ref, tt.dialect); got != tt.want {
+			t.Errorf("QuoteRef(%q, %s) = %s, want %s", tt.ref, tt.dialect, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+func (qe *QueryExecutor) Value(ctx context.Context, dest interface{}) error {
+	db := qe.database()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	target := reflect.ValueOf(dest)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return fmt.Errorf("value destination must be a non-nil pointer, got %T", dest)
+	}
+
+	qe.query.LimitVal = intPtr(1)
+
+	query, args, err := qe.compile(ctx, qe.scoped())
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	holder := reflect.New(target.Type())
+	scanDest := make([]interface{}, len(columns))
+	scanDest[0] = holder.Interface()
+	for i := 1; i < len(columns); i++ {
+		scanDest[i] = new(interface{})
+	}
+
+	if err := rows.Scan(scanDest...); err != nil {
+		return err
+	}
+	if holder.Elem().IsNil() {
+		return sql.ErrNoRows
+	}
+
+	target.Elem().Set(holder.Elem().Elem())
+	return nil
+}
+
+func (qe *QueryExecutor) ValueOr(ctx context.Context, dest interface{}, fallback interface{}) error {
+	err := qe.Value(ctx, dest)
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	value := reflect.ValueOf(fallback)
+	target := reflect.ValueOf(dest).Elem()
+	if !value.IsValid() || !value.Type().AssignableTo(target.Type()) {
+		return fmt.Errorf("fallback %T cannot be assigned to %s", fallback, target.Type())
+	}
+
+	target.Set(value)
+	return nil
+}
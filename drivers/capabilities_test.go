@@ -0,0 +1,112 @@
+package drivers
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+var allFeatures = []string{
+	core.FeatureReturning,
+	core.FeatureRowLocking,
+	core.FeaturePartialIndexes,
+	core.FeatureArrays,
+	core.FeatureInsertIgnore,
+	core.FeatureReplace,
+	core.FeatureLocalSettings,
+	core.FeatureWindowFunctions,
+	core.FeatureCopy,
+}
+
+func TestDriverCapabilities(t *testing.T) {
+	tests := map[string]struct {
+		driver    core.Driver
+		supported []string
+	}{
+		"postgres": {&PostgresDriver{}, []string{
+			core.FeatureReturning,
+			core.FeatureRowLocking,
+			core.FeaturePartialIndexes,
+			core.FeatureArrays,
+			core.FeatureLocalSettings,
+			core.FeatureWindowFunctions,
+			core.FeatureCopy,
+		}},
+		"mysql": {&MySQLDriver{}, []string{
+			core.FeatureRowLocking,
+			core.FeatureInsertIgnore,
+			core.FeatureReplace,
+		}},
+		"sqlite": {&SQLiteDriver{}, []string{
+			core.FeatureReturning,
+			core.FeaturePartialIndexes,
+		}},
+	}
+
+	for name, tt := range tests {
+		supported := make(map[string]bool)
+		for _, feature := range tt.supported {
+			supported[feature] = true
+		}
+		for _, feature := range allFeatures {
+			if got := tt.driver.Supports(feature); got != supported[feature] {
+				t.Errorf("%s: Supports(%s) = %v, want %v", name, feature, got, supported[feature])
+			}
+		}
+		if tt.driver.Supports("time_travel") {
+			t.Errorf("%s supports an unknown feature", name)
+		}
+	}
+}
+
+func TestRequireUnsupportedFeature(t *testing.T) {
+	db, err := core.NewDB(&SQLiteDriver{}, filepath.Join(t.TempDir(), "caps.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.Require(core.FeatureReturning); err != nil {
+		t.Errorf("Require(returning) = %v", err)
+	}
+
+	err = db.Require(core.FeatureInsertIgnore)
+	var unsupported *core.UnsupportedError
+	if !errors.As(err, &unsupported) || unsupported.Feature != core.FeatureInsertIgnore || unsupported.Dialect != "sqlite" {
+		t.Fatalf("err = %#v, want an UnsupportedError", err)
+	}
+	if err.Error() != "insert_ignore is not supported on sqlite" {
+		t.Errorf("message = %q", err.Error())
+	}
+}
+
+func TestBuildQuery(t *testing.T) {
+	limit := 10
+	query := &core.Query{
+		Table:    "posts",
+		Fields:   []string{"*"},
+		Wheres:   []core.WhereClause{{Field: "author_id", Operator: "=", Value: 1}, {Field: "id", Operator: "IN", Value: []interface{}{2, 3}}},
+		LimitVal: &limit,
+		Lock:     "FOR UPDATE",
+	}
+
+	tests := map[string]struct {
+		driver core.Driver
+		want   string
+	}{
+		"postgres": {&PostgresDriver{}, `SELECT * FROM "posts" WHERE "author_id" = $1 AND "id" IN ($2, $3) LIMIT 10 FOR UPDATE`},
+		"mysql":    {&MySQLDriver{}, "SELECT * FROM `posts` WHERE `author_id` = ? AND `id` IN (?, ?) LIMIT 10 FOR UPDATE"},
+		"sqlite":   {&SQLiteDriver{}, `SELECT * FROM "posts" WHERE "author_id" = ? AND "id" IN (?, ?) LIMIT 10`},
+	}
+	for name, tt := range tests {
+		sql, args := tt.driver.BuildQuery(query)
+		if sql != tt.want {
+			t.Errorf("%s: query = %s\nwant          %s", name, sql, tt.want)
+		}
+		if len(args) != 3 {
+			t.Errorf("%s: args = %v", name, args)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package drivers
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+type tableCreator interface {
+	CreateTable(model core.ModelSchema) string
+}
+
+var ddlDrivers = map[string]tableCreator{
+	"postgres": &PostgresDriver{},
+	"mysql":    &MySQLDriver{},
+	"sqlite":   &SQLiteDriver{},
+}
+
+func assertDDL(t *testing.T, model core.ModelSchema, want map[string][]string) {
+	t.Helper()
+	for dialect, fragments := range want {
+		ddl := ddlDrivers[dialect].CreateTable(model)
+		for _, fragment := range fragments {
+			if !strings.Contains(ddl, fragment) {
+				t.Errorf("%s DDL does not contain %q:\n%s", dialect, fragment, ddl)
+			}
+		}
+	}
+}
+
+var checkedModel = core.ModelSchema{
+	Name:      "Product",
+	TableName: "products",
+	Fields: []core.FieldSchema{
+		{Name: "id", Type: "Int", Primary: true, AutoGen: true},
+		{Name: "price", Type: "Int", Check: "price >= 0"},
+		{Name: "salePrice", Type: "Int", Optional: true},
+	},
+	Checks: []string{"sale_price IS NULL OR sale_price < price"},
+}
+
+func TestCheckConstraintDDL(t *testing.T) {
+	assertDDL(t, checkedModel, map[string][]string{
+		"postgres": {"price INTEGER NOT NULL CHECK (price >= 0)", ",\n  CHECK (sale_price IS NULL OR sale_price < price)\n)"},
+		"mysql":    {"price INT NOT NULL CHECK (price >= 0)", ",\n  CHECK (sale_price IS NULL OR sale_price < price)\n)"},
+		"sqlite":   {"price INTEGER NOT NULL CHECK (price >= 0)", ",\n  CHECK (sale_price IS NULL OR sale_price < price)\n)"},
+	})
+}
+
+func TestSQLiteEnforcesCheckConstraints(t *testing.T) {
+	ctx := context.Background()
+	db, err := core.NewDB(&SQLiteDriver{}, filepath.Join(t.TempDir(), "check.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(ctx, (&SQLiteDriver{}).CreateTable(checkedModel)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		price, sale interface{}
+		ok          bool
+	}{
+		{10, nil, true},
+		{10, 5, true},
+		{-1, nil, false},
+		{10, 12, false},
+	}
+	for _, tt := range tests {
+		_, err := db.Exec(ctx, "INSERT INTO products (price, sale_price) VALUES (?, ?)", tt.price, tt.sale)
+		if (err == nil) != tt.ok {
+			t.Errorf("insert price=%v sale=%v: err = %v", tt.price, tt.sale, err)
+		}
+	}
+}
+
+var computedModel = core.ModelSchema{
+	Name:      "Person",
+	TableName: "people",
+	Fields: []core.FieldSchema{
+		{Name: "id", Type: "Int", Primary: true, AutoGen: true},
+		{Name: "firstName", Type: "String"},
+		{Name: "lastName", Type: "String"},
+		{Name: "fullName", Type: "String", Computed: "first_name || ' ' || last_name"},
+	},
+}
+
+func TestComputedColumnDDL(t *testing.T) {
+	assertDDL(t, computedModel, map[string][]string{
+		"postgres": {"full_name VARCHAR(255) GENERATED ALWAYS AS (first_name || ' ' || last_name) STORED"},
+		"mysql":    {"full_name VARCHAR(255) GENERATED ALWAYS AS (first_name || ' ' || last_name) STORED"},
+		"sqlite":   {"full_name TEXT GENERATED ALWAYS AS (first_name || ' ' || last_name) STORED"},
+	})
+
+	statements, err := (&SQLiteDriver{}).MigrationStatements(core.SchemaChange{
+		Type:  core.ChangeAddColumn,
+		Model: computedModel,
+		Field: computedModel.Fields[3],
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "ALTER TABLE people ADD COLUMN full_name TEXT GENERATED ALWAYS AS (first_name || ' ' || last_name) VIRTUAL"
+	if len(statements) != 1 || statements[0] != want {
+		t.Errorf("statements = %q, want %q", statements, want)
+	}
+}
+
+func collatedModel(collation string) core.ModelSchema {
+	return core.ModelSchema{
+		Name:      "Account",
+		TableName: "accounts",
+		Fields: []core.FieldSchema{
+			{Name: "id", Type: "Int", Primary: true, AutoGen: true},
+			{Name: "email", Type: "String", Unique: true, Collation: collation},
+		},
+	}
+}
+
+func TestCollationDDL(t *testing.T) {
+	assertDDL(t, collatedModel("NOCASE"), map[string][]string{
+		"sqlite": {"email TEXT COLLATE NOCASE UNIQUE NOT NULL"},
+	})
+	assertDDL(t, collatedModel("utf8mb4_unicode_ci"), map[string][]string{
+		"mysql": {"email VARCHAR(255) COLLATE utf8mb4_unicode_ci UNIQUE NOT NULL"},
+	})
+	assertDDL(t, collatedModel("C"), map[string][]string{
+		"postgres": {`email VARCHAR(255) COLLATE "C" UNIQUE NOT NULL`},
+	})
+
+	for dialect, driver := range ddlDrivers {
+		if ddl := driver.CreateTable(collatedModel("")); strings.Contains(ddl, "COLLATE") {
+			t.Errorf("%s DDL has a collation without @db.Collate:\n%s", dialect, ddl)
+		}
+	}
+}
+
+func TestSQLiteNocaseUniqueness(t *testing.T) {
+	ctx := context.Background()
+	db, err := core.NewDB(&SQLiteDriver{}, filepath.Join(t.TempDir(), "collate.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(ctx, (&SQLiteDriver{}).CreateTable(collatedModel("NOCASE"))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(ctx, "INSERT INTO accounts (email) VALUES (?)", "Ann@Example.com"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(ctx, "INSERT INTO accounts (email) VALUES (?)", "ann@example.com")
+	if !core.IsUniqueViolation(err) {
+		t.Errorf("insert differing only in case: err = %v", err)
+	}
+}
+
+func TestCompositePrimaryKeyDDL(t *testing.T) {
+	model := core.ModelSchema{
+		Name:      "Membership",
+		TableName: "memberships",
+		Fields: []core.FieldSchema{
+			{Name: "groupId", Type: "Int", Primary: true},
+			{Name: "userId", Type: "Int", Primary: true},
+			{Name: "role", Type: "String"},
+		},
+	}
+	assertDDL(t, model, map[string][]string{
+		"postgres": {"group_id INTEGER NOT NULL,", "user_id INTEGER NOT NULL,", ",\n  PRIMARY KEY (group_id, user_id)\n)"},
+		"mysql":    {"group_id INT NOT NULL,", "user_id INT NOT NULL,", ",\n  PRIMARY KEY (group_id, user_id)\n)"},
+		"sqlite":   {"group_id INTEGER NOT NULL,", "user_id INTEGER NOT NULL,", ",\n  PRIMARY KEY (group_id, user_id)\n)"},
+	})
+}
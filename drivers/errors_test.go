@@ -0,0 +1,188 @@
+package drivers
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+// sqliteConstraintError runs statement against a users table with a unique
+// email and a required name, returning the translated error.
+func sqliteConstraintError(t *testing.T, statement string) error {
+	t.Helper()
+	ctx := context.Background()
+	db := openSQLite(t, filepath.Join(t.TempDir(), "constraints.db"))
+
+	for _, setup := range []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT UNIQUE, name TEXT NOT NULL)",
+		"INSERT INTO users (email, name) VALUES ('ann@example.com', 'Ann')",
+	} {
+		if _, err := db.Exec(ctx, setup); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := db.Exec(ctx, statement)
+	if err == nil {
+		t.Fatalf("%s succeeded", statement)
+	}
+	return err
+}
+
+func assertFieldError(t *testing.T, name string, err error, want core.FieldError) {
+	t.Helper()
+	var validationErr *core.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("%s: %T is not a ValidationError: %v", name, err, err)
+		return
+	}
+	if len(validationErr.Errors) != 1 || validationErr.Errors[0] != want {
+		t.Errorf("%s: errors = %+v, want %+v", name, validationErr.Errors, want)
+	}
+	if validationErr.Unwrap() == nil {
+		t.Errorf("%s: the driver error is not kept", name)
+	}
+}
+
+func TestTranslateUniqueViolationToValidationError(t *testing.T) {
+	want := core.FieldError{Field: "email", Rule: core.RuleUnique, Message: "already exists"}
+
+	assertFieldError(t, "postgres", (&PostgresDriver{}).TranslateError(&pq.Error{
+		Code:   "23505",
+		Detail: "Key (email)=(ann@example.com) already exists.",
+	}), want)
+
+	assertFieldError(t, "mysql", (&MySQLDriver{}).TranslateError(&mysql.MySQLError{
+		Number:  1062,
+		Message: "Duplicate entry 'ann@example.com' for key 'users.email'",
+	}), want)
+
+	assertFieldError(t, "sqlite", sqliteConstraintError(t,
+		"INSERT INTO users (email, name) VALUES ('ann@example.com', 'Copy')"), want)
+}
+
+func TestTranslateNotNullViolationToValidationError(t *testing.T) {
+	want := core.FieldError{Field: "name", Rule: core.RuleRequired, Message: "is required"}
+
+	assertFieldError(t, "postgres", (&PostgresDriver{}).TranslateError(&pq.Error{
+		Code:   "23502",
+		Column: "name",
+	}), want)
+
+	assertFieldError(t, "mysql", (&MySQLDriver{}).TranslateError(&mysql.MySQLError{
+		Number:  1048,
+		Message: "Column 'name' cannot be null",
+	}), want)
+
+	assertFieldError(t, "sqlite", sqliteConstraintError(t,
+		"INSERT INTO users (email) VALUES ('bob@example.com')"), want)
+}
+
+func TestTranslateLeavesOtherErrors(t *testing.T) {
+	plain := errors.New("connection reset")
+	for name, driver := range map[string]interface{ TranslateError(error) error }{
+		"postgres": &PostgresDriver{},
+		"mysql":    &MySQLDriver{},
+		"sqlite":   &SQLiteDriver{},
+	} {
+		if err := driver.TranslateError(plain); err != plain {
+			t.Errorf("%s: %v was translated to %v", name, plain, err)
+		}
+	}
+}
+
+func TestUniqueViolationDetection(t *testing.T) {
+	errs := map[string]error{
+		"postgres": (&PostgresDriver{}).TranslateError(&pq.Error{
+			Code:   "23505",
+			Detail: "Key (email)=(ann@example.com) already exists.",
+		}),
+		"mysql": (&MySQLDriver{}).TranslateError(&mysql.MySQLError{
+			Number:  1062,
+			Message: "Duplicate entry 'ann@example.com' for key 'email'",
+		}),
+		"sqlite": sqliteConstraintError(t,
+			"INSERT INTO users (email, name) VALUES ('ann@example.com', 'Copy')"),
+	}
+
+	for name, err := range errs {
+		if !core.IsUniqueViolation(err) {
+			t.Errorf("%s: %v is not a unique violation", name, err)
+		}
+		if core.IsForeignKeyViolation(err) {
+			t.Errorf("%s: %v is reported as a foreign key violation", name, err)
+		}
+	}
+
+	if core.IsUniqueViolation((&PostgresDriver{}).TranslateError(&pq.Error{Code: "23502", Column: "name"})) {
+		t.Error("a not-null violation is reported as a unique violation")
+	}
+}
+
+func TestForeignKeyViolationDetection(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t, filepath.Join(t.TempDir(), "fk.db"))
+	if _, err := db.Exec(ctx, "INSERT INTO authors (id) VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(ctx, "INSERT INTO posts (author_id) VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+	_, sqliteDelete := db.Exec(ctx, "DELETE FROM authors WHERE id = 1")
+
+	const mysqlConstraint = "a foreign key constraint fails (`blog`.`posts`, CONSTRAINT `posts_ibfk_1` FOREIGN KEY (`author_id`) REFERENCES `authors` (`id`))"
+	errs := map[string]error{
+		"postgres insert": (&PostgresDriver{}).TranslateError(&pq.Error{
+			Code:   "23503",
+			Detail: `Key (author_id)=(42) is not present in table "authors".`,
+		}),
+		"mysql delete": (&MySQLDriver{}).TranslateError(&mysql.MySQLError{
+			Number:  1451,
+			Message: "Cannot delete or update a parent row: " + mysqlConstraint,
+		}),
+		"mysql insert": (&MySQLDriver{}).TranslateError(&mysql.MySQLError{
+			Number:  1452,
+			Message: "Cannot add or update a child row: " + mysqlConstraint,
+		}),
+		"sqlite insert": insertOrphan(ctx, db),
+		"sqlite delete": sqliteDelete,
+	}
+
+	for name, err := range errs {
+		if !core.IsForeignKeyViolation(err) {
+			t.Errorf("%s: %v is not a foreign key violation", name, err)
+		}
+		if core.IsUniqueViolation(err) {
+			t.Errorf("%s: %v is reported as a unique violation", name, err)
+		}
+	}
+
+	for _, name := range []string{"postgres insert", "mysql insert"} {
+		want := core.FieldError{Field: "author_id", Rule: core.RuleForeignKey, Message: "violates a foreign key constraint"}
+		assertFieldError(t, name, errs[name], want)
+	}
+}
+
+func TestSerializationFailureDetection(t *testing.T) {
+	for name, err := range map[string]error{
+		"postgres": (&PostgresDriver{}).TranslateError(&pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"}),
+		"mysql":    (&MySQLDriver{}).TranslateError(&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}),
+	} {
+		if !core.IsSerializationFailure(err) {
+			t.Errorf("%s: %v is not a serialization failure", name, err)
+		}
+		if errors.Unwrap(err) == nil {
+			t.Errorf("%s: the driver error is not kept", name)
+		}
+	}
+
+	if core.IsSerializationFailure((&PostgresDriver{}).TranslateError(&pq.Error{Code: "23505"})) {
+		t.Error("a unique violation is reported as a serialization failure")
+	}
+}
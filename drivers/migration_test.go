@@ -0,0 +1,95 @@
+package drivers
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+func addColumnChange(field core.FieldSchema) core.SchemaChange {
+	return core.SchemaChange{
+		Type:  core.ChangeAddColumn,
+		Model: core.ModelSchema{Name: "Post", TableName: "posts"},
+		Field: field,
+	}
+}
+
+func TestAddRequiredColumnBackfills(t *testing.T) {
+	change := addColumnChange(core.FieldSchema{Name: "views", Type: "Int"})
+
+	tests := []struct {
+		name   string
+		driver interface {
+			MigrationStatements(core.SchemaChange) ([]string, error)
+		}
+		want []string
+	}{
+		{"postgres", &PostgresDriver{}, []string{
+			"ALTER TABLE posts ADD COLUMN views INTEGER",
+			"UPDATE posts SET views = 0 WHERE views IS NULL",
+			"ALTER TABLE posts ALTER COLUMN views SET NOT NULL",
+		}},
+		{"mysql", &MySQLDriver{}, []string{
+			"ALTER TABLE posts ADD COLUMN views INT",
+			"UPDATE posts SET views = 0 WHERE views IS NULL",
+			"ALTER TABLE posts MODIFY COLUMN views INT NOT NULL",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements, err := tt.driver.MigrationStatements(change)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(statements, tt.want) {
+				t.Errorf("statements:\n%s\nwant:\n%s", strings.Join(statements, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestSQLiteAddRequiredColumn(t *testing.T) {
+	driver := &SQLiteDriver{}
+
+	_, err := driver.MigrationStatements(addColumnChange(core.FieldSchema{Name: "views", Type: "Int"}))
+	if err == nil || !strings.Contains(err.Error(), "Post.views") || !strings.Contains(err.Error(), "@default") {
+		t.Fatalf("err = %v, want an error asking for @default", err)
+	}
+
+	statements, err := driver.MigrationStatements(addColumnChange(core.FieldSchema{Name: "views", Type: "Int", Default: 7}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"ALTER TABLE posts ADD COLUMN views INTEGER NOT NULL DEFAULT 7"}
+	if !reflect.DeepEqual(statements, want) {
+		t.Fatalf("statements = %q, want %q", statements, want)
+	}
+
+	ctx := context.Background()
+	db, err := core.NewDB(driver, filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, statement := range append([]string{
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT NOT NULL)",
+		"INSERT INTO posts (title) VALUES ('existing')",
+	}, statements...) {
+		if _, err := db.Exec(ctx, statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	var views int
+	if err := db.QueryRow(ctx, "SELECT views FROM posts").Scan(&views); err != nil {
+		t.Fatal(err)
+	}
+	if views != 7 {
+		t.Errorf("existing row views = %d, want 7", views)
+	}
+}
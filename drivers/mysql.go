@@ -2,11 +2,13 @@ package drivers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/nitrix4ly/comet/core"
-	_ "github.com/go-sql-driver/mysql"
 )
 
 type MySQLDriver struct{}
@@ -16,12 +18,12 @@ func (d *MySQLDriver) Connect(dsn string) (*sql.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	
+
 	if err := db.Ping(); err != nil {
 		db.Close()
 		return nil, err
 	}
-	
+
 	return db, nil
 }
 
@@ -29,97 +31,199 @@ func (d *MySQLDriver) Migrate(schema *core.Schema) error {
 	return fmt.Errorf("migrations not implemented yet")
 }
 
+// Deprecated: queries are built by core.QueryExecutor; BuildQuery is kept
+// for callers of the Driver interface.
 func (d *MySQLDriver) BuildQuery(query *core.Query) (string, []interface{}) {
-	var parts []string
-	var args []interface{}
-	
-	fields := strings.Join(query.Fields, ", ")
-	parts = append(parts, fmt.Sprintf("SELECT %s FROM %s", fields, query.Table))
-	
-	if len(query.Wheres) > 0 {
-		var whereParts []string
-		
-		for _, where := range query.Wheres {
-			operator := where.Operator
-			if where.Not {
-				operator = "NOT " + operator
-			}
-			
-			if where.Operator == "IN" {
-				whereParts = append(whereParts, fmt.Sprintf("%s %s %v", where.Field, operator, where.Value))
-			} else {
-				whereParts = append(whereParts, fmt.Sprintf("%s %s ?", where.Field, operator))
-				args = append(args, where.Value)
-			}
-		}
-		parts = append(parts, "WHERE "+strings.Join(whereParts, " AND "))
-	}
-	
-	if len(query.Orders) > 0 {
-		var orderParts []string
-		for _, order := range query.Orders {
-			orderParts = append(orderParts, fmt.Sprintf("%s %s", order.Field, order.Direction))
-		}
-		parts = append(parts, "ORDER BY "+strings.Join(orderParts, ", "))
-	}
-	
-	if query.LimitVal != nil {
-		parts = append(parts, fmt.Sprintf("LIMIT %d", *query.LimitVal))
-	}
-	
-	if query.OffsetVal != nil {
-		parts = append(parts, fmt.Sprintf("OFFSET %d", *query.OffsetVal))
-	}
-	
-	return strings.Join(parts, " "), args
+	return core.BuildSelect(query, d.GetDialect(), d.Supports(core.FeatureRowLocking))
 }
 
 func (d *MySQLDriver) GetDialect() string {
 	return "mysql"
 }
 
+func (d *MySQLDriver) Supports(feature string) bool {
+	switch feature {
+	case core.FeatureRowLocking, core.FeatureInsertIgnore, core.FeatureReplace:
+		return true
+	}
+	return false
+}
+
+var (
+	mysqlDuplicateKey = regexp.MustCompile(`for key '([^']*)'`)
+	mysqlNullColumn   = regexp.MustCompile(`Column '([^']*)'`)
+	mysqlForeignKey   = regexp.MustCompile(`FOREIGN KEY \(([^)]*)\)`)
+)
+
+func (d *MySQLDriver) TranslateError(err error) error {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return err
+	}
+
+	switch myErr.Number {
+	case 1062:
+		var column string
+		if match := mysqlDuplicateKey.FindStringSubmatch(myErr.Message); match != nil {
+			column = match[1][strings.LastIndex(match[1], ".")+1:]
+		}
+		return core.NewConstraintError(core.RuleUnique, column, err)
+	case 1048:
+		var column string
+		if match := mysqlNullColumn.FindStringSubmatch(myErr.Message); match != nil {
+			column = match[1]
+		}
+		return core.NewConstraintError(core.RuleRequired, column, err)
+	case 1451, 1452:
+		var column string
+		if match := mysqlForeignKey.FindString(myErr.Message); match != "" {
+			column = core.KeyColumn(match)
+		}
+		return core.NewConstraintError(core.RuleForeignKey, column, err)
+	case 1213:
+		return &core.SerializationError{Err: err}
+	}
+	return err
+}
+
 func (d *MySQLDriver) CreateTable(model core.ModelSchema) string {
 	var columns []string
-	
-	for _, field := range model.Fields {
+
+	keys := model.PrimaryKeyColumns()
+	for _, field := range model.TableFields() {
+		if len(keys) > 1 {
+			field.Primary = false
+		}
 		column := d.buildColumnDefinition(field)
 		columns = append(columns, column)
 	}
-	
+
+	if len(keys) > 1 {
+		columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(keys, ", ")))
+	}
+
+	for _, check := range model.Checks {
+		columns = append(columns, fmt.Sprintf("CHECK (%s)", check))
+	}
+
 	sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n  %s\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
 		model.TableName,
 		strings.Join(columns, ",\n  "))
-	
+
+	if model.Comment != "" {
+		sql += " COMMENT=" + core.QuoteLiteral(model.Comment, "mysql")
+	}
+
 	return sql
 }
 
+func (d *MySQLDriver) CreateIndexes(model core.ModelSchema) []string {
+	var statements []string
+
+	for _, index := range model.Indexes {
+		if index.Where != "" && !d.Supports(core.FeaturePartialIndexes) {
+			continue
+		}
+
+		kind := "INDEX"
+		if index.Unique {
+			kind = "UNIQUE INDEX"
+		}
+
+		statements = append(statements, fmt.Sprintf("CREATE %s %s ON %s (%s)",
+			kind,
+			index.Name,
+			model.TableName,
+			strings.Join(model.IndexColumns(index), ", ")))
+	}
+
+	return statements
+}
+
+func (d *MySQLDriver) MigrationStatements(change core.SchemaChange) ([]string, error) {
+	switch change.Type {
+	case core.ChangeCreateTable:
+		for _, index := range change.Model.Indexes {
+			if index.Unique && index.Where != "" {
+				return nil, fmt.Errorf("%s: mysql has no partial indexes and cannot enforce unique index %s where %s, drop the where: predicate or enforce it in the application", change.Model.Name, index.Name, index.Where)
+			}
+		}
+		return append([]string{d.CreateTable(change.Model)}, d.CreateIndexes(change.Model)...), nil
+	case core.ChangeDropTable:
+		return []string{fmt.Sprintf("DROP TABLE IF EXISTS %s", change.Model.TableName)}, nil
+	case core.ChangeDropColumn:
+		return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", change.Model.TableName, change.Field.ColumnName())}, nil
+	case core.ChangeAddColumn:
+		if !core.RequiresBackfill(change.Field) {
+			return []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", change.Model.TableName, d.buildColumnDefinition(change.Field))}, nil
+		}
+
+		nullable := change.Field
+		nullable.Optional = true
+
+		required := change.Field
+		required.Unique = false
+
+		return []string{
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", change.Model.TableName, d.buildColumnDefinition(nullable)),
+			fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s IS NULL", change.Model.TableName, change.Field.ColumnName(), core.BackfillValue(change.Field, "mysql"), change.Field.ColumnName()),
+			fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", change.Model.TableName, d.buildColumnDefinition(required)),
+		}, nil
+	}
+
+	return nil, nil
+}
+
 func (d *MySQLDriver) buildColumnDefinition(field core.FieldSchema) string {
 	var parts []string
-	
-	parts = append(parts, field.Name)
-	
+
+	parts = append(parts, field.ColumnName())
+
 	sqlType := core.GetSQLType(field.Type, "mysql")
+	if field.Array {
+		sqlType = "TEXT"
+	}
+	if field.Type == "Bytes" && field.Unique {
+		sqlType = "VARBINARY(255)"
+	}
+	if field.Precision != nil {
+		sqlType += fmt.Sprintf("(%d)", *field.Precision)
+	}
 	if field.Primary && field.AutoGen {
 		sqlType = "INT AUTO_INCREMENT"
 	}
 	parts = append(parts, sqlType)
-	
+
+	if field.Collation != "" {
+		parts = append(parts, "COLLATE "+field.Collation)
+	}
+
+	if field.Computed != "" {
+		parts = append(parts, fmt.Sprintf("GENERATED ALWAYS AS (%s) STORED", field.Computed))
+		if field.Comment != "" {
+			parts = append(parts, "COMMENT "+core.QuoteLiteral(field.Comment, "mysql"))
+		}
+		return strings.Join(parts, " ")
+	}
+
 	if field.Primary {
 		parts = append(parts, "PRIMARY KEY")
 	}
-	
+
 	if field.Unique && !field.Primary {
 		parts = append(parts, "UNIQUE")
 	}
-	
+
 	if !field.Optional && !field.Primary {
 		parts = append(parts, "NOT NULL")
 	}
-	
+
 	if field.Default != nil {
 		switch v := field.Default.(type) {
 		case string:
-			if v == "CURRENT_TIMESTAMP" {
+			if v == "CURRENT_TIMESTAMP" && field.Precision != nil {
+				parts = append(parts, fmt.Sprintf("DEFAULT CURRENT_TIMESTAMP(%d)", *field.Precision))
+			} else if v == "CURRENT_TIMESTAMP" {
 				parts = append(parts, "DEFAULT CURRENT_TIMESTAMP")
 			} else {
 				parts = append(parts, fmt.Sprintf("DEFAULT '%s'", v))
@@ -130,6 +234,14 @@ func (d *MySQLDriver) buildColumnDefinition(field core.FieldSchema) string {
 			parts = append(parts, fmt.Sprintf("DEFAULT %v", v))
 		}
 	}
-	
+
+	if field.Comment != "" {
+		parts = append(parts, "COMMENT "+core.QuoteLiteral(field.Comment, "mysql"))
+	}
+
+	if field.Check != "" {
+		parts = append(parts, fmt.Sprintf("CHECK (%s)", field.Check))
+	}
+
 	return strings.Join(parts, " ")
 }
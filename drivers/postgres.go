@@ -2,11 +2,12 @@ package drivers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/lib/pq"
 	"github.com/nitrix4ly/comet/core"
-	_ "github.com/lib/pq"
 )
 
 type PostgresDriver struct{}
@@ -16,12 +17,12 @@ func (d *PostgresDriver) Connect(dsn string) (*sql.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	
+
 	if err := db.Ping(); err != nil {
 		db.Close()
 		return nil, err
 	}
-	
+
 	return db, nil
 }
 
@@ -29,95 +30,189 @@ func (d *PostgresDriver) Migrate(schema *core.Schema) error {
 	return fmt.Errorf("migrations not implemented yet")
 }
 
+// Deprecated: queries are built by core.QueryExecutor; BuildQuery is kept
+// for callers of the Driver interface.
 func (d *PostgresDriver) BuildQuery(query *core.Query) (string, []interface{}) {
-	var parts []string
-	var args []interface{}
-	
-	fields := strings.Join(query.Fields, ", ")
-	parts = append(parts, fmt.Sprintf("SELECT %s FROM %s", fields, query.Table))
-	
-	if len(query.Wheres) > 0 {
-		var whereParts []string
-		argIndex := 1
-		
-		for _, where := range query.Wheres {
-			operator := where.Operator
-			if where.Not {
-				operator = "NOT " + operator
-			}
-			
-			if where.Operator == "IN" {
-				whereParts = append(whereParts, fmt.Sprintf("%s %s %v", where.Field, operator, where.Value))
-			} else {
-				whereParts = append(whereParts, fmt.Sprintf("%s %s $%d", where.Field, operator, argIndex))
-				args = append(args, where.Value)
-				argIndex++
-			}
-		}
-		parts = append(parts, "WHERE "+strings.Join(whereParts, " AND "))
-	}
-	
-	if len(query.Orders) > 0 {
-		var orderParts []string
-		for _, order := range query.Orders {
-			orderParts = append(orderParts, fmt.Sprintf("%s %s", order.Field, order.Direction))
-		}
-		parts = append(parts, "ORDER BY "+strings.Join(orderParts, ", "))
-	}
-	
-	if query.LimitVal != nil {
-		parts = append(parts, fmt.Sprintf("LIMIT %d", *query.LimitVal))
-	}
-	
-	if query.OffsetVal != nil {
-		parts = append(parts, fmt.Sprintf("OFFSET %d", *query.OffsetVal))
-	}
-	
-	return strings.Join(parts, " "), args
+	return core.BuildSelect(query, d.GetDialect(), d.Supports(core.FeatureRowLocking))
 }
 
 func (d *PostgresDriver) GetDialect() string {
 	return "postgres"
 }
 
+func (d *PostgresDriver) Supports(feature string) bool {
+	switch feature {
+	case core.FeatureReturning, core.FeatureRowLocking, core.FeaturePartialIndexes, core.FeatureArrays, core.FeatureLocalSettings, core.FeatureWindowFunctions, core.FeatureCopy:
+		return true
+	}
+	return false
+}
+
+func (d *PostgresDriver) TranslateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case "23505":
+		return core.NewConstraintError(core.RuleUnique, core.KeyColumn(pqErr.Detail), err)
+	case "23502":
+		return core.NewConstraintError(core.RuleRequired, pqErr.Column, err)
+	case "23503":
+		return core.NewConstraintError(core.RuleForeignKey, core.KeyColumn(pqErr.Detail), err)
+	case "40001":
+		return &core.SerializationError{Err: err}
+	}
+	return err
+}
+
 func (d *PostgresDriver) CreateTable(model core.ModelSchema) string {
 	var columns []string
-	
-	for _, field := range model.Fields {
+
+	keys := model.PrimaryKeyColumns()
+	for _, field := range model.TableFields() {
+		if len(keys) > 1 {
+			field.Primary = false
+		}
 		column := d.buildColumnDefinition(field)
 		columns = append(columns, column)
 	}
-	
+
+	if len(keys) > 1 {
+		columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(keys, ", ")))
+	}
+
+	for _, check := range model.Checks {
+		columns = append(columns, fmt.Sprintf("CHECK (%s)", check))
+	}
+
 	sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n  %s\n)",
 		model.TableName,
 		strings.Join(columns, ",\n  "))
-	
+
 	return sql
 }
 
+func (d *PostgresDriver) CreateComments(model core.ModelSchema) []string {
+	var statements []string
+
+	if model.Comment != "" {
+		statements = append(statements, fmt.Sprintf("COMMENT ON TABLE %s IS %s", model.TableName, core.QuoteLiteral(model.Comment, "postgres")))
+	}
+
+	for _, field := range model.Fields {
+		if field.Comment != "" {
+			statements = append(statements, d.columnComment(model.TableName, field))
+		}
+	}
+
+	return statements
+}
+
+func (d *PostgresDriver) columnComment(table string, field core.FieldSchema) string {
+	return fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s", table, field.ColumnName(), core.QuoteLiteral(field.Comment, "postgres"))
+}
+
+func (d *PostgresDriver) CreateIndexes(model core.ModelSchema) []string {
+	var statements []string
+
+	for _, index := range model.Indexes {
+		kind := "INDEX"
+		if index.Unique {
+			kind = "UNIQUE INDEX"
+		}
+
+		statement := fmt.Sprintf("CREATE %s IF NOT EXISTS %s ON %s (%s)",
+			kind,
+			index.Name,
+			model.TableName,
+			strings.Join(model.IndexColumns(index), ", "))
+
+		if index.Where != "" {
+			statement += " WHERE " + index.Where
+		}
+
+		statements = append(statements, statement)
+	}
+
+	return statements
+}
+
+func (d *PostgresDriver) MigrationStatements(change core.SchemaChange) ([]string, error) {
+	switch change.Type {
+	case core.ChangeCreateTable:
+		statements := append([]string{d.CreateTable(change.Model)}, d.CreateComments(change.Model)...)
+		return append(statements, d.CreateIndexes(change.Model)...), nil
+	case core.ChangeDropTable:
+		return []string{fmt.Sprintf("DROP TABLE IF EXISTS %s", change.Model.TableName)}, nil
+	case core.ChangeDropColumn:
+		return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", change.Model.TableName, change.Field.ColumnName())}, nil
+	case core.ChangeAddColumn:
+		var statements []string
+		if !core.RequiresBackfill(change.Field) {
+			statements = []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", change.Model.TableName, d.buildColumnDefinition(change.Field))}
+		} else {
+			nullable := change.Field
+			nullable.Optional = true
+
+			statements = []string{
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", change.Model.TableName, d.buildColumnDefinition(nullable)),
+				fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s IS NULL", change.Model.TableName, change.Field.ColumnName(), core.BackfillValue(change.Field, "postgres"), change.Field.ColumnName()),
+				fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", change.Model.TableName, change.Field.ColumnName()),
+			}
+		}
+
+		if change.Field.Comment != "" {
+			statements = append(statements, d.columnComment(change.Model.TableName, change.Field))
+		}
+		return statements, nil
+	}
+
+	return nil, nil
+}
+
 func (d *PostgresDriver) buildColumnDefinition(field core.FieldSchema) string {
 	var parts []string
-	
-	parts = append(parts, field.Name)
-	
+
+	parts = append(parts, field.ColumnName())
+
 	sqlType := core.GetSQLType(field.Type, "postgres")
+	if field.NativeType != "" {
+		sqlType = field.NativeType
+	}
+	if field.Precision != nil {
+		sqlType += fmt.Sprintf("(%d)", *field.Precision)
+	}
+	if field.Array {
+		sqlType += "[]"
+	}
 	if field.Primary && field.AutoGen {
 		sqlType = "SERIAL"
 	}
 	parts = append(parts, sqlType)
-	
+
+	if field.Collation != "" {
+		parts = append(parts, "COLLATE "+core.QuoteIdentifier(field.Collation, "postgres"))
+	}
+
+	if field.Computed != "" {
+		parts = append(parts, fmt.Sprintf("GENERATED ALWAYS AS (%s) STORED", field.Computed))
+		return strings.Join(parts, " ")
+	}
+
 	if field.Primary {
 		parts = append(parts, "PRIMARY KEY")
 	}
-	
+
 	if field.Unique && !field.Primary {
 		parts = append(parts, "UNIQUE")
 	}
-	
+
 	if !field.Optional && !field.Primary {
 		parts = append(parts, "NOT NULL")
 	}
-	
+
 	if field.Default != nil {
 		switch v := field.Default.(type) {
 		case string:
@@ -132,6 +227,10 @@ func (d *PostgresDriver) buildColumnDefinition(field core.FieldSchema) string {
 			parts = append(parts, fmt.Sprintf("DEFAULT %v", v))
 		}
 	}
-	
+
+	if field.Check != "" {
+		parts = append(parts, fmt.Sprintf("CHECK (%s)", field.Check))
+	}
+
 	return strings.Join(parts, " ")
 }
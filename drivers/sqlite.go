@@ -2,14 +2,20 @@ package drivers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/mattn/go-sqlite3"
 	"github.com/nitrix4ly/comet/core"
-	_ "github.com/mattn/go-sqlite3"
 )
 
-type SQLiteDriver struct{}
+type SQLiteDriver struct {
+	JournalMode string
+	BusyTimeout time.Duration
+}
 
 func (d *SQLiteDriver) Connect(dsn string) (*sql.DB, error) {
 	if strings.HasPrefix(dsn, "sqlite://") {
@@ -18,120 +24,215 @@ func (d *SQLiteDriver) Connect(dsn string) (*sql.DB, error) {
 	if strings.HasPrefix(dsn, "file:") {
 		dsn = strings.TrimPrefix(dsn, "file:")
 	}
-	
+
+	if !hasDSNParam(dsn, "_foreign_keys", "_fk") {
+		dsn = addDSNParam(dsn, "_foreign_keys", "1")
+	}
+	if d.JournalMode != "" && !hasDSNParam(dsn, "_journal_mode", "_journal") {
+		dsn = addDSNParam(dsn, "_journal_mode", strings.ToUpper(d.JournalMode))
+	}
+	if d.BusyTimeout > 0 && !hasDSNParam(dsn, "_busy_timeout", "_timeout") {
+		dsn = addDSNParam(dsn, "_busy_timeout", strconv.FormatInt(d.BusyTimeout.Milliseconds(), 10))
+	}
+
 	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	if err := db.Ping(); err != nil {
 		db.Close()
 		return nil, err
 	}
-	
-	_, err = db.Exec("PRAGMA foreign_keys = ON")
-	if err != nil {
-		db.Close()
-		return nil, err
-	}
-	
+
 	return db, nil
 }
 
+func hasDSNParam(dsn string, names ...string) bool {
+	pos := strings.IndexRune(dsn, '?')
+	if pos < 0 {
+		return false
+	}
+
+	for _, param := range strings.Split(dsn[pos+1:], "&") {
+		key := strings.SplitN(param, "=", 2)[0]
+		for _, name := range names {
+			if key == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func addDSNParam(dsn, name, value string) string {
+	separator := "?"
+	if strings.ContainsRune(dsn, '?') {
+		separator = "&"
+	}
+	return dsn + separator + name + "=" + value
+}
+
 func (d *SQLiteDriver) Migrate(schema *core.Schema) error {
 	return fmt.Errorf("migrations not implemented yet")
 }
 
+// Deprecated: queries are built by core.QueryExecutor; BuildQuery is kept
+// for callers of the Driver interface.
 func (d *SQLiteDriver) BuildQuery(query *core.Query) (string, []interface{}) {
-	var parts []string
-	var args []interface{}
-	
-	fields := strings.Join(query.Fields, ", ")
-	parts = append(parts, fmt.Sprintf("SELECT %s FROM %s", fields, query.Table))
-	
-	if len(query.Wheres) > 0 {
-		var whereParts []string
-		
-		for _, where := range query.Wheres {
-			operator := where.Operator
-			if where.Not {
-				operator = "NOT " + operator
-			}
-			
-			if where.Operator == "IN" {
-				whereParts = append(whereParts, fmt.Sprintf("%s %s %v", where.Field, operator, where.Value))
-			} else {
-				whereParts = append(whereParts, fmt.Sprintf("%s %s ?", where.Field, operator))
-				args = append(args, where.Value)
-			}
-		}
-		parts = append(parts, "WHERE "+strings.Join(whereParts, " AND "))
-	}
-	
-	if len(query.Orders) > 0 {
-		var orderParts []string
-		for _, order := range query.Orders {
-			orderParts = append(orderParts, fmt.Sprintf("%s %s", order.Field, order.Direction))
-		}
-		parts = append(parts, "ORDER BY "+strings.Join(orderParts, ", "))
+	return core.BuildSelect(query, d.GetDialect(), d.Supports(core.FeatureRowLocking))
+}
+
+func (d *SQLiteDriver) GetDialect() string {
+	return "sqlite"
+}
+
+func (d *SQLiteDriver) Supports(feature string) bool {
+	switch feature {
+	case core.FeatureReturning, core.FeaturePartialIndexes:
+		return true
 	}
-	
-	if query.LimitVal != nil {
-		parts = append(parts, fmt.Sprintf("LIMIT %d", *query.LimitVal))
+	return false
+}
+
+func (d *SQLiteDriver) TranslateError(err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) || sqliteErr.Code != sqlite3.ErrConstraint {
+		return err
 	}
-	
-	if query.OffsetVal != nil {
-		parts = append(parts, fmt.Sprintf("OFFSET %d", *query.OffsetVal))
+
+	switch sqliteErr.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		return core.NewConstraintError(core.RuleUnique, sqliteColumn(sqliteErr.Error()), err)
+	case sqlite3.ErrConstraintNotNull:
+		return core.NewConstraintError(core.RuleRequired, sqliteColumn(sqliteErr.Error()), err)
+	case sqlite3.ErrConstraintForeignKey:
+		return core.NewConstraintError(core.RuleForeignKey, "", err)
 	}
-	
-	return strings.Join(parts, " "), args
+	return err
 }
 
-func (d *SQLiteDriver) GetDialect() string {
-	return "sqlite"
+func sqliteColumn(message string) string {
+	i := strings.Index(message, "failed: ")
+	if i < 0 {
+		return ""
+	}
+	column := strings.Split(message[i+len("failed: "):], ",")[0]
+	return strings.TrimSpace(column[strings.LastIndex(column, ".")+1:])
 }
 
 func (d *SQLiteDriver) CreateTable(model core.ModelSchema) string {
 	var columns []string
-	
-	for _, field := range model.Fields {
+
+	keys := model.PrimaryKeyColumns()
+	for _, field := range model.TableFields() {
+		if len(keys) > 1 {
+			field.Primary = false
+		}
 		column := d.buildColumnDefinition(field)
 		columns = append(columns, column)
 	}
-	
+
+	if len(keys) > 1 {
+		columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(keys, ", ")))
+	}
+
+	for _, check := range model.Checks {
+		columns = append(columns, fmt.Sprintf("CHECK (%s)", check))
+	}
+
 	sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n  %s\n)",
 		model.TableName,
 		strings.Join(columns, ",\n  "))
-	
+
 	return sql
 }
 
+func (d *SQLiteDriver) CreateIndexes(model core.ModelSchema) []string {
+	var statements []string
+
+	for _, index := range model.Indexes {
+		kind := "INDEX"
+		if index.Unique {
+			kind = "UNIQUE INDEX"
+		}
+
+		statement := fmt.Sprintf("CREATE %s IF NOT EXISTS %s ON %s (%s)",
+			kind,
+			index.Name,
+			model.TableName,
+			strings.Join(model.IndexColumns(index), ", "))
+
+		if index.Where != "" {
+			statement += " WHERE " + index.Where
+		}
+
+		statements = append(statements, statement)
+	}
+
+	return statements
+}
+
+func (d *SQLiteDriver) MigrationStatements(change core.SchemaChange) ([]string, error) {
+	switch change.Type {
+	case core.ChangeCreateTable:
+		return append([]string{d.CreateTable(change.Model)}, d.CreateIndexes(change.Model)...), nil
+	case core.ChangeDropTable:
+		return []string{fmt.Sprintf("DROP TABLE IF EXISTS %s", change.Model.TableName)}, nil
+	case core.ChangeDropColumn:
+		return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", change.Model.TableName, change.Field.ColumnName())}, nil
+	case core.ChangeAddColumn:
+		if change.Field.Computed != "" {
+			definition := strings.TrimSuffix(d.buildColumnDefinition(change.Field), " STORED") + " VIRTUAL"
+			return []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", change.Model.TableName, definition)}, nil
+		}
+		if core.RequiresBackfill(change.Field) {
+			return nil, fmt.Errorf("%s.%s: sqlite cannot add a required column without a default to an existing table, declare @default or rebuild the table", change.Model.Name, change.Field.Name)
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", change.Model.TableName, d.buildColumnDefinition(change.Field))}, nil
+	}
+
+	return nil, nil
+}
+
 func (d *SQLiteDriver) buildColumnDefinition(field core.FieldSchema) string {
 	var parts []string
-	
-	parts = append(parts, field.Name)
-	
+
+	parts = append(parts, field.ColumnName())
+
 	sqlType := core.GetSQLType(field.Type, "sqlite")
+	if field.Array {
+		sqlType = "TEXT"
+	}
 	if field.Primary && field.AutoGen {
 		sqlType = "INTEGER"
 	}
 	parts = append(parts, sqlType)
-	
+
+	if field.Collation != "" {
+		parts = append(parts, "COLLATE "+field.Collation)
+	}
+
+	if field.Computed != "" {
+		parts = append(parts, fmt.Sprintf("GENERATED ALWAYS AS (%s) STORED", field.Computed))
+		return strings.Join(parts, " ")
+	}
+
 	if field.Primary {
 		parts = append(parts, "PRIMARY KEY")
 		if field.AutoGen {
 			parts = append(parts, "AUTOINCREMENT")
 		}
 	}
-	
+
 	if field.Unique && !field.Primary {
 		parts = append(parts, "UNIQUE")
 	}
-	
+
 	if !field.Optional && !field.Primary {
 		parts = append(parts, "NOT NULL")
 	}
-	
+
 	if field.Default != nil {
 		switch v := field.Default.(type) {
 		case string:
@@ -150,6 +251,10 @@ func (d *SQLiteDriver) buildColumnDefinition(field core.FieldSchema) string {
 			parts = append(parts, fmt.Sprintf("DEFAULT %v", v))
 		}
 	}
-	
+
+	if field.Check != "" {
+		parts = append(parts, fmt.Sprintf("CHECK (%s)", field.Check))
+	}
+
 	return strings.Join(parts, " ")
 }
@@ -0,0 +1,176 @@
+package drivers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+func openSQLite(t *testing.T, dsn string) *core.DB {
+	t.Helper()
+	db, err := core.NewDB(&SQLiteDriver{}, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, statement := range []string{
+		"CREATE TABLE IF NOT EXISTS authors (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE IF NOT EXISTS posts (id INTEGER PRIMARY KEY, author_id INTEGER REFERENCES authors (id))",
+	} {
+		if _, err := db.Exec(context.Background(), statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func insertOrphan(ctx context.Context, db *core.DB) error {
+	_, err := db.Exec(ctx, "INSERT INTO posts (author_id) VALUES (?)", 42)
+	return err
+}
+
+func TestSQLiteForeignKeysToggle(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t, filepath.Join(t.TempDir(), "fk.db"))
+
+	if err := insertOrphan(ctx, db); !core.IsForeignKeyViolation(err) {
+		t.Fatalf("foreign keys should be enforced by default, got %v", err)
+	}
+
+	if err := db.SetForeignKeys(ctx, false); err == nil {
+		t.Fatal("SetForeignKeys on a multi-connection pool should fail")
+	}
+
+	db.SQL().SetMaxOpenConns(1)
+	if err := db.SetForeignKeys(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := insertOrphan(ctx, db); err != nil {
+		t.Fatalf("insert with foreign keys off: %v", err)
+	}
+
+	if err := db.SetForeignKeys(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := insertOrphan(ctx, db); !core.IsForeignKeyViolation(err) {
+		t.Fatalf("foreign keys should be enforced again, got %v", err)
+	}
+
+	err := db.WithTransaction(ctx, func(ctx context.Context, tx *core.Tx) error {
+		return db.SetForeignKeys(ctx, false)
+	})
+	if err == nil {
+		t.Error("SetForeignKeys inside a sqlite transaction should fail")
+	}
+}
+
+func TestSQLiteForeignKeysDSN(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t, "file:"+filepath.Join(t.TempDir(), "fk.db")+"?_foreign_keys=0")
+
+	if err := insertOrphan(ctx, db); err != nil {
+		t.Fatalf("insert with _foreign_keys=0: %v", err)
+	}
+}
+
+// holdWriteLock opens a write transaction on its own connection and commits
+// it after delay, so other writers have to wait for the lock.
+func holdWriteLock(t *testing.T, db *core.DB, delay time.Duration) <-chan error {
+	t.Helper()
+	tx, err := db.SQL().Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("INSERT INTO authors DEFAULT VALUES"); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		time.Sleep(delay)
+		done <- tx.Commit()
+	}()
+	return done
+}
+
+func TestSQLiteLockedWithoutBusyTimeout(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t, "file:"+filepath.Join(t.TempDir(), "locked.db")+"?_busy_timeout=0")
+
+	done := holdWriteLock(t, db, 200*time.Millisecond)
+	_, err := db.Exec(ctx, "INSERT INTO authors DEFAULT VALUES")
+	if err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("write while locked = %v, want database is locked", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSQLiteWALAndBusyTimeout(t *testing.T) {
+	ctx := context.Background()
+	db, err := core.NewDB(&SQLiteDriver{JournalMode: "wal", BusyTimeout: 5 * time.Second}, filepath.Join(t.TempDir(), "wal.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var mode string
+	if err := db.SQL().QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatal(err)
+	}
+	if mode != "wal" {
+		t.Errorf("journal_mode = %q, want wal", mode)
+	}
+	if _, err := db.Exec(ctx, "CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+
+	done := holdWriteLock(t, db, 200*time.Millisecond)
+	if _, err := db.Exec(ctx, "INSERT INTO authors DEFAULT VALUES"); err != nil {
+		t.Errorf("write while locked should wait for the lock: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	const writers, inserts = 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < inserts; i++ {
+				err := db.WithTransaction(ctx, func(ctx context.Context, tx *core.Tx) error {
+					_, err := db.Exec(ctx, "INSERT INTO authors (name) VALUES (?)", fmt.Sprintf("%d-%d", w, i))
+					return err
+				})
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent writer: %v", err)
+	}
+
+	var count int
+	if err := db.SQL().QueryRow("SELECT COUNT(*) FROM authors WHERE name IS NOT NULL").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != writers*inserts {
+		t.Errorf("count = %d, want %d", count, writers*inserts)
+	}
+}
@@ -0,0 +1,93 @@
+package gen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const arraySchema = `
+model Article {
+  Id     Int       @id @auto
+  Title  String
+  Tags   String[]
+  Scores Int[]
+  Flags  Boolean[]
+}
+`
+
+func TestArrayFieldGeneration(t *testing.T) {
+	g := NewGenerator()
+	dir := generate(t, g, arraySchema)
+
+	article := readGenerated(t, dir, "article.go")
+	for _, want := range []string{`\tTags\s+\[\]string\s`, `\tScores\s+\[\]int64\s`, `\tFlags\s+\[\]bool\s`} {
+		if !regexp.MustCompile(want).MatchString(article) {
+			t.Errorf("article.go does not declare %s", want)
+		}
+	}
+	for _, want := range []string{
+		"pq.Array(m.Tags)",
+		"pq.Array(&m.Tags)",
+		`"github.com/lib/pq"`,
+	} {
+		if !strings.Contains(article, want) {
+			t.Errorf("article.go does not contain %s", want)
+		}
+	}
+
+	ddl := strings.Join(dialectStatements(t, "postgres", g.Schema()), "\n")
+	for _, want := range []string{"tags VARCHAR(255)[] NOT NULL", "scores INTEGER[] NOT NULL", "flags BOOLEAN[] NOT NULL"} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("postgres DDL does not contain %q:\n%s", want, ddl)
+		}
+	}
+	ddl = strings.Join(dialectStatements(t, "sqlite", g.Schema()), "\n")
+	if !strings.Contains(ddl, "tags TEXT NOT NULL") {
+		t.Errorf("sqlite DDL does not store arrays as TEXT:\n%s", ddl)
+	}
+}
+
+const arrayProgram = `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	article := &models.Article{
+		Title:  "Arrays",
+		Tags:   []string{"go", "sql, quoted", ""},
+		Scores: []int64{3, -1},
+		Flags:  []bool{true, false},
+	}
+	must(article.Save(ctx))
+
+	found, err := models.ArticleQuery.FindById(ctx, article.Id)
+	must(err)
+	fmt.Printf("%q %v %v\n", found.Tags, found.Scores, found.Flags)
+
+	found.Tags = append(found.Tags, "more")
+	must(found.Save(ctx))
+	again, err := models.ArticleQuery.FindById(ctx, article.Id)
+	must(err)
+	fmt.Println(len(again.Tags), again.Tags[3])
+}
+`
+
+func TestArrayRoundTrip(t *testing.T) {
+	for _, provider := range []string{"sqlite", "postgres"} {
+		t.Run(provider, func(t *testing.T) {
+			output := runGeneratedOn(t, provider, NewGenerator(), arraySchema, arrayProgram)
+
+			want := "[\"go\" \"sql, quoted\" \"\"] [3 -1] [true false]\n4 more"
+			if output != want {
+				t.Errorf("output:\n%s\nwant:\n%s", output, want)
+			}
+		})
+	}
+}
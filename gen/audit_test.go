@@ -0,0 +1,125 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const auditSchema = `
+model Post {
+  Id    Int    @id @auto
+  Title String
+}
+`
+
+func TestAuditEvents(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), auditSchema, `package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	var events []core.AuditEvent
+	core.SetAuditLogger(core.AuditLoggerFunc(func(ctx context.Context, e core.AuditEvent) error {
+		events = append(events, e)
+		return nil
+	}))
+
+	post := &models.Post{Title: "Draft"}
+	must(post.Save(ctx))
+	post.Title = "Final"
+	must(post.Save(ctx))
+	must(post.Delete(ctx))
+
+	for _, e := range events {
+		fmt.Println(e.Table, e.Operation, e.PrimaryKey, e.Changes["title"].Old, e.Changes["title"].New)
+	}
+}
+`)
+
+	want := strings.Join([]string{
+		"posts create 1 <nil> Draft",
+		"posts update 1 Draft Final",
+		"posts delete 1 Final <nil>",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
+
+func TestAuditFailureRollsBackWrite(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), auditSchema, `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func count(ctx context.Context, title string) int64 {
+	n, err := models.PostQuery.Find().Where("title", "=", title).Count(ctx)
+	must(err)
+	return n
+}
+
+func main() {
+	ctx := setup()
+
+	failing := true
+	core.SetAuditLogger(core.AuditLoggerFunc(func(ctx context.Context, e core.AuditEvent) error {
+		if failing {
+			return errors.New("audit store down")
+		}
+		return nil
+	}))
+
+	post := &models.Post{Title: "Draft"}
+	fmt.Println(post.Save(ctx), post.IsNew(), post.Id, count(ctx, "Draft"))
+
+	failing = false
+	must(post.Save(ctx))
+	failing = true
+
+	post.Title = "Final"
+	fmt.Println(post.Save(ctx), count(ctx, "Draft"), count(ctx, "Final"))
+	fmt.Println(post.Delete(ctx), count(ctx, "Draft"))
+
+	failing = false
+	must(post.Save(ctx))
+	fmt.Println(count(ctx, "Draft"), count(ctx, "Final"))
+
+	err := core.GetDB().WithTransaction(ctx, func(ctx context.Context, tx *core.Tx) error {
+		failing = true
+		fmt.Println(post.Delete(ctx))
+		failing = false
+		return (&models.Post{Title: "Other"}).Save(ctx)
+	})
+	must(err)
+	fmt.Println(count(ctx, "Final"), count(ctx, "Other"))
+}
+`)
+
+	want := strings.Join([]string{
+		"audit store down true 0 0",
+		"audit store down 1 0",
+		"audit store down 1",
+		"0 1",
+		"audit store down",
+		"1 1",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
@@ -0,0 +1,123 @@
+package gen
+
+import (
+	"testing"
+)
+
+const batchSchema = `
+model Item {
+  Id   Int    @id @auto
+  Name String
+}
+`
+
+const batchSeed = `
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		_, err := models.ItemQuery.Create(ctx, &models.Item{Name: name})
+		must(err)
+	}
+`
+
+func TestDeleteByIds(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), batchSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+`+batchSeed+`
+	deleted, err := models.ItemQuery.DeleteByIds(ctx, []int{1, 3, 5, 42})
+	must(err)
+	fmt.Println(deleted)
+
+	none, err := models.ItemQuery.DeleteByIds(ctx, nil)
+	must(err)
+	fmt.Println(none)
+
+	rows, err := models.ItemQuery.Find().OrderBy("id", "ASC").All(ctx)
+	must(err)
+	for _, row := range rows {
+		fmt.Print(row.(*models.Item).Name)
+	}
+	fmt.Println()
+}
+`)
+
+	if output != "3\n0\nbd" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestFindByIds(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), batchSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+`+batchSeed+`
+	items, err := models.ItemQuery.FindByIds(ctx, []int{4, 42, 1, 4, 2})
+	must(err)
+	for _, item := range items {
+		fmt.Print(item.Id, item.Name, " ")
+	}
+	fmt.Println(len(items))
+
+	empty, err := models.ItemQuery.FindByIds(ctx, nil)
+	must(err)
+	fmt.Println(len(empty))
+}
+`)
+
+	if output != "4d 1a 2b 3\n0" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestLoadByIds(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), batchSchema+`
+model Country {
+  Code String @id
+  Name String
+}
+`, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+`+batchSeed+`
+	items, err := models.ItemQuery.LoadByIds(ctx, []int{5, 2, 42, 2})
+	must(err)
+	fmt.Println(len(items), items[5].Name, items[2].Name, items[42] == nil)
+
+	empty, err := models.ItemQuery.LoadByIds(ctx, nil)
+	must(err)
+	fmt.Println(len(empty), empty != nil)
+
+	for code, name := range map[string]string{"nz": "New Zealand", "pe": "Peru"} {
+		_, err := models.CountryQuery.Create(ctx, &models.Country{Code: code, Name: name})
+		must(err)
+	}
+	countries, err := models.CountryQuery.LoadByIds(ctx, []string{"pe", "xx"})
+	must(err)
+	fmt.Println(len(countries), countries["pe"].Name)
+}
+`)
+
+	if output != "2 e b true\n0 true\n1 Peru" {
+		t.Errorf("output = %q", output)
+	}
+}
@@ -0,0 +1,86 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const bytesSchema = `
+model Attachment {
+  Id        Int    @id @auto
+  Name      String
+  Content   Bytes
+  Checksum  Bytes  @unique
+  Thumbnail Bytes?
+}
+`
+
+func TestBytesColumnTypes(t *testing.T) {
+	schema, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), bytesSchema)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for provider, want := range map[string][]string{
+		"sqlite":   {"content BLOB NOT NULL", "checksum BLOB UNIQUE NOT NULL", "thumbnail BLOB,"},
+		"postgres": {"content BYTEA NOT NULL", "checksum BYTEA UNIQUE NOT NULL", "thumbnail BYTEA,"},
+		"mysql":    {"content BLOB NOT NULL", "checksum VARBINARY(255) UNIQUE NOT NULL", "thumbnail BLOB,"},
+	} {
+		ddl := strings.Join(dialectStatements(t, provider, schema), "\n")
+		for _, fragment := range want {
+			if !strings.Contains(ddl, fragment) {
+				t.Errorf("%s DDL does not contain %q:\n%s", provider, fragment, ddl)
+			}
+		}
+	}
+
+	attachment := readGenerated(t, generate(t, NewGenerator(), bytesSchema), "attachment.go")
+	for _, want := range []string{"Content   []byte", "Thumbnail *[]byte"} {
+		if !strings.Contains(attachment, want) {
+			t.Errorf("attachment.go does not contain %q", want)
+		}
+	}
+}
+
+func TestBytesRoundTrip(t *testing.T) {
+	program := `package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	content := make([]byte, 256)
+	for i := range content {
+		content[i] = byte(i)
+	}
+	saved, err := models.AttachmentQuery.Create(ctx, &models.Attachment{Name: "all", Content: content, Checksum: []byte{0, 0xff, 0}})
+	must(err)
+
+	loaded, err := models.AttachmentQuery.FindById(ctx, saved.Id)
+	must(err)
+	fmt.Println(bytes.Equal(loaded.Content, content), bytes.Equal(loaded.Checksum, []byte{0, 0xff, 0}), loaded.Thumbnail == nil)
+
+	thumbnail := []byte("\x89PNG\r\n")
+	loaded.Thumbnail = &thumbnail
+	must(loaded.Save(ctx))
+
+	found, err := models.AttachmentQuery.Find().Where("checksum", "=", []byte{0, 0xff, 0}).First(ctx)
+	must(err)
+	fmt.Println(bytes.Equal(*found.(*models.Attachment).Thumbnail, thumbnail))
+}
+`
+	for _, provider := range []string{"sqlite", "postgres", "mysql"} {
+		t.Run(provider, func(t *testing.T) {
+			output := runGeneratedOn(t, provider, NewGenerator(), bytesSchema, program)
+			if want := "true true true\ntrue"; output != want {
+				t.Errorf("output:\n%s\nwant:\n%s", output, want)
+			}
+		})
+	}
+}
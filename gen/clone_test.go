@@ -0,0 +1,69 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const cloneSchema = `
+model Template {
+  Id       Int      @id @auto
+  Name     String
+  Subtitle String?
+  Tags     String[]
+}
+`
+
+func TestCloneGeneration(t *testing.T) {
+	template := readGenerated(t, generate(t, NewGenerator(), cloneSchema), "template.go")
+	for _, want := range []string{
+		"func (m *Template) Clone() *Template {",
+		"\tc.Id = 0\n",
+		"\tc.CreatedAt = time.Time{}\n",
+		"\tc.isNew = true\n",
+		"\tc.Tags = append([]string(nil), m.Tags...)\n",
+	} {
+		if !strings.Contains(template, want) {
+			t.Errorf("template.go does not contain %q", want)
+		}
+	}
+}
+
+func TestCloneInsertsDistinctRow(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), cloneSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	subtitle := "weekly"
+	original := &models.Template{Name: "report", Subtitle: &subtitle, Tags: []string{"a", "b"}}
+	must(original.Save(ctx))
+
+	clone := original.Clone()
+	fmt.Println(clone.Id, clone.CreatedAt.IsZero(), clone.IsNew())
+
+	*clone.Subtitle = "monthly"
+	clone.Tags[0] = "z"
+	clone.Name = "report copy"
+	must(clone.Save(ctx))
+	fmt.Println(clone.Id != original.Id, clone.CreatedAt.IsZero())
+
+	count, err := models.TemplateQuery.Find().Count(ctx)
+	must(err)
+	loaded, err := models.TemplateQuery.FindById(ctx, original.Id)
+	must(err)
+	fmt.Println(count, *original.Subtitle, original.Tags, loaded.Name, *loaded.Subtitle, loaded.Tags)
+}
+`)
+
+	want := "0 true true\ntrue false\n2 weekly [a b] report weekly [a b]"
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
@@ -0,0 +1,90 @@
+package gen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const columnsSchema = `
+model Post {
+  Id          Int     @id @auto
+  Title       String
+  IsPublished Boolean @default(false)
+}
+`
+
+func TestColumnConstants(t *testing.T) {
+	post := readGenerated(t, generate(t, NewGenerator(), columnsSchema), "post.go")
+
+	for _, want := range []string{
+		`var PostColumns = struct \{`,
+		`\tIsPublished +string\n`,
+		`\tId: +"id",\n`,
+		`\tTitle: +"title",\n`,
+		`\tIsPublished: +"is_published",\n`,
+		`\tCreatedAt: +"created_at",\n`,
+		`\tUpdatedAt: +"updated_at",\n`,
+	} {
+		if !regexp.MustCompile(want).MatchString(post) {
+			t.Errorf("post.go does not match %s", want)
+		}
+	}
+}
+
+func TestTableAndColumnConstantsMatchSchema(t *testing.T) {
+	g := NewGenerator()
+	g.SetNamingStrategy(legacyNaming{})
+	dir := generate(t, g, namingSchema)
+
+	for _, model := range g.Schema().Models {
+		source := readGenerated(t, dir, strings.ToLower(model.Name)+".go")
+		if want := "const " + model.Name + "Table = \"" + model.TableName + "\""; !strings.Contains(source, want) {
+			t.Errorf("%s: missing %s", model.Name, want)
+		}
+		if !strings.Contains(source, "return "+model.Name+"Table") {
+			t.Errorf("%s: TableName does not return the constant", model.Name)
+		}
+
+		for _, field := range model.Fields {
+			want := `\t` + regexp.QuoteMeta(field.Name) + `: +"` + regexp.QuoteMeta(g.naming.ColumnName(field.Name)) + `",\n`
+			if !regexp.MustCompile(want).MatchString(source) {
+				t.Errorf("%s: %sColumns.%s does not match column %s", model.Name, model.Name, field.Name, g.naming.ColumnName(field.Name))
+			}
+		}
+	}
+}
+
+func TestColumnConstantsInQueries(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), columnsSchema, `package main
+
+import (
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	for _, post := range []*models.Post{{Title: "draft"}, {Title: "live", IsPublished: true}} {
+		must(post.Save(ctx))
+	}
+
+	rows, err := models.PostQuery.Find().Where(models.PostColumns.IsPublished, "=", true).All(ctx)
+	must(err)
+	fmt.Println(len(rows), rows[0].(*models.Post).Title)
+
+	var title string
+	query := "SELECT " + models.PostColumns.Title + " FROM " + models.PostTable + " WHERE " + models.PostColumns.IsPublished + " = ?"
+	must(core.GetDB().SQL().QueryRowContext(ctx, query, false).Scan(&title))
+	fmt.Println(title)
+}
+`)
+
+	if output != "1 live\ndraft" {
+		t.Errorf("output = %q", output)
+	}
+}
@@ -0,0 +1,61 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const commentSchema = `
+model User {
+  Id    Int    @id @auto
+  Email String @unique @comment("Login address, it's unique")
+  Name  String
+
+  @@comment("Stores registered users")
+}
+`
+
+func TestCommentDDL(t *testing.T) {
+	schema, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), commentSchema)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Models[0].Comment != "Stores registered users" {
+		t.Errorf("model comment = %q", schema.Models[0].Comment)
+	}
+
+	postgres := dialectStatements(t, "postgres", schema)
+	if want := []string{
+		"COMMENT ON TABLE users IS 'Stores registered users'",
+		"COMMENT ON COLUMN users.email IS 'Login address, it''s unique'",
+	}; len(postgres) != 3 || postgres[1] != want[0] || postgres[2] != want[1] {
+		t.Errorf("postgres statements = %q, want CREATE TABLE followed by %q", postgres, want)
+	}
+
+	mysql := strings.Join(dialectStatements(t, "mysql", schema), "\n")
+	for _, want := range []string{
+		"email VARCHAR(255) UNIQUE NOT NULL COMMENT 'Login address, it''s unique',",
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Stores registered users'",
+	} {
+		if !strings.Contains(mysql, want) {
+			t.Errorf("mysql DDL does not contain %q:\n%s", want, mysql)
+		}
+	}
+
+	if sqlite := strings.Join(dialectStatements(t, "sqlite", schema), "\n"); strings.Contains(sqlite, "COMMENT") {
+		t.Errorf("sqlite DDL has comments:\n%s", sqlite)
+	}
+}
+
+func TestParseInvalidComment(t *testing.T) {
+	_, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), `
+model User {
+  Id Int @id @auto
+
+  @@comment(Stores users)
+}
+`)})
+	if err == nil || !strings.Contains(err.Error(), "invalid comment") {
+		t.Errorf("err = %v, want invalid comment", err)
+	}
+}
@@ -0,0 +1,57 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const computedSchema = `
+model Person {
+  Id        Int    @id @auto
+  FirstName String
+  LastName  String
+  FullName  String @computed("first_name || ' ' || last_name")
+}
+`
+
+func TestComputedColumnIsReadOnly(t *testing.T) {
+	person := readGenerated(t, generate(t, NewGenerator(), computedSchema), "person.go")
+	if strings.Contains(person, "func (m *Person) SetFullName(") {
+		t.Error("computed field has a setter")
+	}
+	if !strings.Contains(person, `columns := []string{"first_name", "last_name", "created_at", "updated_at"}`) {
+		t.Error("insert columns should leave out full_name")
+	}
+}
+
+func TestComputedColumnScan(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), computedSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	person := &models.Person{FirstName: "Ada", LastName: "Lovelace", FullName: "ignored"}
+	must(person.Save(ctx))
+	fmt.Println(person.FullName)
+
+	person.SetLastName("King")
+	must(person.Save(ctx))
+	must(person.Reload(ctx))
+	fmt.Println(person.FullName)
+
+	found, err := models.PersonQuery.Find().Where("full_name", "=", "Ada King").First(ctx)
+	must(err)
+	fmt.Println(found.(*models.Person).Id == person.Id)
+}
+`)
+
+	if output != "Ada Lovelace\nAda King\ntrue" {
+		t.Errorf("output = %q", output)
+	}
+}
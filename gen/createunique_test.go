@@ -0,0 +1,82 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateUniqueConcurrent(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), `
+model Account {
+  Id    Int    @id @auto
+  Email String @unique
+  Name  String
+}
+`, `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = models.AccountQuery.CreateUnique(ctx, &models.Account{Email: "ann@example.com", Name: fmt.Sprint(i)}, "email")
+		}(i)
+	}
+	wg.Wait()
+
+	created, violations := 0, 0
+	for _, err := range errs {
+		var validation *core.ValidationError
+		switch {
+		case err == nil:
+			created++
+		case core.IsUniqueViolation(err) && errors.As(err, &validation) && validation.Errors[0].Field == "email":
+			violations++
+		default:
+			fmt.Println("unexpected:", err)
+		}
+	}
+	fmt.Println(created, violations)
+
+	count, err := models.AccountQuery.Find().Count(ctx)
+	must(err)
+	fmt.Println(count)
+
+	err = core.GetDB().WithTransaction(ctx, func(ctx context.Context, tx *core.Tx) error {
+		_, err := models.AccountQuery.CreateUnique(ctx, &models.Account{Email: "ann@example.com", Name: "again"}, "email")
+		fmt.Println(core.IsUniqueViolation(err))
+		_, err = models.AccountQuery.Create(ctx, &models.Account{Email: "bob@example.com", Name: "Bob"})
+		return err
+	})
+	must(err)
+
+	_, err = models.AccountQuery.CreateUnique(ctx, &models.Account{Email: "eve@example.com"}, "name")
+	fmt.Println(err)
+}
+`)
+
+	want := strings.Join([]string{
+		"1 1",
+		"1",
+		"true",
+		"'name' is not a unique column of Account",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
@@ -0,0 +1,88 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const noteSchema = `
+model Note {
+  Id   Int    @id @auto
+  Body String
+}
+`
+
+func TestExecAndQuerySmoke(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), noteSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	result, err := models.Exec(ctx, "INSERT INTO notes (body, created_at, updated_at) VALUES (?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP), (?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)", "a", "b")
+	must(err)
+	affected, err := result.RowsAffected()
+	must(err)
+	fmt.Println(affected)
+
+	_, err = models.Exec(ctx, "VACUUM")
+	must(err)
+
+	rows, err := models.Query(ctx, "SELECT body FROM notes WHERE id > ? ORDER BY id", 0)
+	must(err)
+	defer rows.Close()
+	for rows.Next() {
+		var body string
+		must(rows.Scan(&body))
+		fmt.Print(body)
+	}
+	must(rows.Err())
+	fmt.Println()
+}
+`)
+
+	if output != "2\nab" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestOperationsAfterClose(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), noteSchema, `package main
+
+import (
+	"context"
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	note := &models.Note{Body: "kept"}
+	must(note.Save(ctx))
+
+	must(models.Close())
+	fmt.Println(models.Close())
+
+	_, err := models.NoteQuery.FindById(ctx, note.Id)
+	fmt.Println(err)
+	_, err = models.NoteQuery.Find().All(ctx)
+	fmt.Println(err)
+	fmt.Println((&models.Note{Body: "lost"}).Save(ctx))
+	_, err = models.Exec(ctx, "DELETE FROM notes")
+	fmt.Println(err)
+	fmt.Println(models.Transaction(ctx, func(ctx context.Context) error { return nil }))
+}
+`)
+
+	want := "<nil>" + strings.Repeat("\ndatabase not initialized", 5)
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
@@ -0,0 +1,71 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const defaultNowSchema = `
+model Event {
+  Id          Int       @id @auto
+  Name        String
+  PublishedAt DateTime  @default(now())
+  ReviewedAt  DateTime? @default(now())
+  ClosedAt    DateTime?
+}
+`
+
+func TestDefaultNowGeneration(t *testing.T) {
+	event := readGenerated(t, generate(t, NewGenerator(), defaultNowSchema), "event.go")
+	for _, want := range []string{
+		"if m.PublishedAt.IsZero() {",
+		"if m.ReviewedAt == nil {",
+	} {
+		if !strings.Contains(event, want) {
+			t.Errorf("event.go does not contain %s", want)
+		}
+	}
+	if strings.Contains(event, "if m.ClosedAt == nil {") {
+		t.Error("event.go fills ClosedAt, which has no now() default")
+	}
+}
+
+func TestDefaultNowCustomTimestamp(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), defaultNowSchema, `package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+	before := time.Now().Add(-time.Minute)
+
+	event, err := models.EventQuery.Create(ctx, &models.Event{Name: "launch"})
+	must(err)
+	fmt.Println(event.PublishedAt.After(before), event.ReviewedAt != nil, event.ClosedAt == nil)
+
+	var published time.Time
+	var closed sql.NullTime
+	must(core.GetDB().SQL().QueryRowContext(ctx, "SELECT published_at, closed_at FROM events WHERE id = ?", event.Id).Scan(&published, &closed))
+	fmt.Println(published.After(before), closed.Valid)
+
+	fixed := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	event, err = models.EventQuery.Create(ctx, &models.Event{Name: "archive", PublishedAt: fixed})
+	must(err)
+	found, err := models.EventQuery.FindById(ctx, event.Id)
+	must(err)
+	fmt.Println(found.PublishedAt.Equal(fixed))
+}
+`)
+
+	if output != "true true true\ntrue false\ntrue" {
+		t.Errorf("output = %q", output)
+	}
+}
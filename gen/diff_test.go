@@ -0,0 +1,80 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEqualAndDiffGeneration(t *testing.T) {
+	g := NewGenerator()
+	g.SetNamingStrategy(legacyNaming{})
+	post := readGenerated(t, generate(t, g, namingSchema), "post.go")
+
+	for _, want := range []string{
+		"func (m *Post) Equal(other *Post) bool {",
+		"func (m *Post) Diff(other *Post) map[string]interface{} {",
+		"func (m *Post) DiffAll(other *Post) map[string]interface{} {",
+		`changes["c_title"] = other.Title`,
+		`changes["c_created_at"] = other.CreatedAt`,
+	} {
+		if !strings.Contains(post, want) {
+			t.Errorf("post.go does not contain %q", want)
+		}
+	}
+	if strings.Contains(post, "other.isNew") {
+		t.Error("Diff compares isNew")
+	}
+}
+
+func TestEqualAndDiff(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), cloneSchema, `package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gentest/models"
+)
+
+func keys(changes map[string]interface{}) []string {
+	var names []string
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func main() {
+	ctx := setup()
+
+	subtitle := "weekly"
+	saved := &models.Template{Name: "report", Subtitle: &subtitle, Tags: []string{"a"}}
+	must(saved.Save(ctx))
+
+	loaded, err := models.TemplateQuery.FindById(ctx, saved.Id)
+	must(err)
+	fmt.Println(saved.Equal(loaded), len(saved.Diff(loaded)), saved.IsNew() == loaded.IsNew())
+
+	other := "monthly"
+	loaded.Subtitle = &other
+	loaded.Tags = append(loaded.Tags, "b")
+	loaded.UpdatedAt = loaded.UpdatedAt.Add(time.Hour)
+	changes := saved.Diff(loaded)
+	fmt.Println(saved.Equal(loaded), keys(changes), *changes["subtitle"].(*string), changes["tags"])
+	fmt.Println(keys(saved.DiffAll(loaded)))
+	fmt.Println(keys(saved.Diff(nil)))
+}
+`)
+
+	want := strings.Join([]string{
+		"true 0 true",
+		"false [subtitle tags] monthly [a b]",
+		"[subtitle tags updated_at]",
+		"[id name subtitle tags]",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
@@ -1,8 +1,9 @@
 package gen
 
 import (
-	"os"
+	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -10,295 +11,2284 @@ import (
 )
 
 type Generator struct {
-	parser *Parser
+	parser       *Parser
+	naming       core.NamingStrategy
+	schema       *core.Schema
+	only         []string
+	force        bool
+	splitQueries bool
+	skipped      []string
+	manifests    map[string]*manifest
+	enums        map[string][]string
+	models       map[string]core.ModelSchema
 }
 
 func NewGenerator() *Generator {
 	return &Generator{
 		parser: NewParser(),
+		naming: core.DefaultNamingStrategy{},
 	}
 }
 
+func (g *Generator) SetNamingStrategy(naming core.NamingStrategy) {
+	g.naming = naming
+	g.parser.SetNamingStrategy(naming)
+}
+
+func (g *Generator) SetModelFilter(names []string) {
+	g.only = names
+}
+
+func (g *Generator) SetSplitQueries(split bool) {
+	g.splitQueries = split
+}
+
+func (g *Generator) Schema() *core.Schema {
+	return g.schema
+}
+
 func (g *Generator) GenerateFromFile(schemaFile, outputDir string) error {
-	schema, err := g.parser.ParseFile(schemaFile)
+	return g.GenerateFromFiles([]string{schemaFile}, outputDir)
+}
+
+func (g *Generator) GenerateFromFiles(schemaFiles []string, outputDir string) error {
+	schema, err := g.parser.ParseFiles(schemaFiles)
+	if err != nil {
+		return err
+	}
+	g.schema = schema
+
+	g.enums = make(map[string][]string, len(schema.Enums))
+	for _, enum := range schema.Enums {
+		g.enums[enum.Name] = enum.Values
+	}
+	if len(schema.Enums) > 0 {
+		if err := g.generateEnums(schema.Enums, outputDir); err != nil {
+			return err
+		}
+	}
+
+	g.models = make(map[string]core.ModelSchema, len(schema.Models))
+	for _, model := range schema.Models {
+		g.models[model.Name] = model
+	}
+
+	selected, err := g.selectedModels()
 	if err != nil {
 		return err
 	}
 
 	for _, model := range schema.Models {
+		if selected != nil && !selected[model.Name] {
+			continue
+		}
 		if err := g.generateModel(model, outputDir); err != nil {
 			return err
 		}
+		if err := g.generateFactory(model, outputDir); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+func (g *Generator) selectedModels() (map[string]bool, error) {
+	if len(g.only) == 0 {
+		return nil, nil
+	}
+
+	selected := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if selected[name] {
+			return
+		}
+		selected[name] = true
+		for _, relation := range g.models[name].Relations {
+			if _, ok := g.models[relation.Model]; ok {
+				visit(relation.Model)
+			}
+			for _, target := range relation.Models {
+				if _, ok := g.models[target]; ok {
+					visit(target)
+				}
+			}
+		}
+	}
+
+	for _, name := range g.only {
+		if _, ok := g.models[name]; !ok {
+			return nil, fmt.Errorf("unknown model '%s'", name)
+		}
+		visit(name)
+	}
+	return selected, nil
+}
+
 func (g *Generator) GenerateHelpers(outputDir string) error {
 	return g.generateBaseFiles(outputDir)
 }
 
 func (g *Generator) generateModel(model core.ModelSchema, outputDir string) error {
-	filename := filepath.Join(outputDir, strings.ToLower(model.Name)+".go")
-	
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
+	base := filepath.Join(outputDir, strings.ToLower(model.Name))
+
+	parse := func(text string) *template.Template {
+		return template.Must(template.New("model").Funcs(templateFuncs).Funcs(template.FuncMap{
+			"Column": g.naming.ColumnName,
+		}).Parse(text))
 	}
-	defer file.Close()
 
-	tmpl := template.Must(template.New("model").Parse(modelTemplate))
-	
 	data := struct {
-		Model          core.ModelSchema
-		PackageName    string
-		GoType         func(string) string
-		DatabaseType   func(string) string
-		IsOptional     func(core.FieldSchema) bool
-		HasTimestamps  func() bool
+		Model         core.ModelSchema
+		PackageName   string
+		GoType        func(string) string
+		FieldType     func(core.FieldSchema) string
+		Bind          func(core.FieldSchema) string
+		ScanDest      func(core.FieldSchema) string
+		Truncate      func(core.FieldSchema) string
+		HasArrays     bool
+		Imports       []string
+		HasUnique     bool
+		TenantField   *core.FieldSchema
+		Columns       []columnName
+		InsertFields  []core.FieldSchema
+		KeyColumns    []string
+		PrimaryField  *core.FieldSchema
+		ColumnDests   []columnDest
+		HasMany       []hasManyRelation
+		BelongsTo     []belongsToRelation
+		Polymorphic   []polymorphicRelation
+		Projections   []projection
+		SearchColumns []string
+		UniqueColumns []string
+		Relations     []relationLink
+		Computed      []string
+		EmptyKey      string
+		KeyWhere      string
+		DatabaseType  func(string) string
+		IsOptional    func(core.FieldSchema) bool
+		IsTimestamp   func(core.FieldSchema) bool
+		HasTimestamps func() bool
 	}{
-		Model:       model,
-		PackageName: "models",
-		GoType:      g.getGoType,
+		Model:         model,
+		PackageName:   "models",
+		GoType:        g.getGoType,
+		FieldType:     g.getFieldType,
+		Bind:          g.bindExpr,
+		ScanDest:      g.scanDest,
+		Truncate:      truncateExpr,
+		HasArrays:     hasArrayFields(model),
+		Imports:       customImports(model),
+		HasUnique:     hasUniqueFields(model),
+		TenantField:   tenantField(model),
+		Columns:       g.modelColumns(model),
+		InsertFields:  insertFields(model),
+		KeyColumns:    g.keyColumns(model),
+		PrimaryField:  primaryField(model),
+		ColumnDests:   g.columnDests(model),
+		HasMany:       g.hasManyRelations(model),
+		BelongsTo:     g.belongsToRelations(model),
+		Polymorphic:   g.polymorphicRelations(model),
+		Projections:   g.projections(model),
+		SearchColumns: g.searchColumns(model),
+		UniqueColumns: g.uniqueColumns(model),
+		Relations:     g.relationLinks(model),
+		Computed:      g.computedColumns(model),
+		EmptyKey:      g.emptyKeyCondition(model),
+		KeyWhere:      g.keyWhere(model),
 		DatabaseType: func(t string) string {
 			return core.GetSQLType(t, "postgres")
 		},
 		IsOptional: func(f core.FieldSchema) bool {
 			return f.Optional
 		},
+		IsTimestamp: func(f core.FieldSchema) bool {
+			return f.Type == "DateTime" && f.GoType == "" && (f.Default == "CURRENT_TIMESTAMP" || f.Default == "now()")
+		},
 		HasTimestamps: func() bool {
 			return true
 		},
 	}
 
-	return tmpl.Execute(file, data)
+	if !g.splitQueries {
+		if err := g.removeGenerated(base + "_query.go"); err != nil {
+			return err
+		}
+		return g.render(base+".go", parse(modelTemplate+queryTemplate), data)
+	}
+
+	if err := g.renderPruned(base+".go", parse(modelTemplate), data); err != nil {
+		return err
+	}
+	return g.renderPruned(base+"_query.go", parse(modelHeaderTemplate+queryTemplate), data)
 }
 
 func (g *Generator) generateBaseFiles(outputDir string) error {
 	if err := g.generateDBFile(outputDir); err != nil {
 		return err
 	}
-	
+
+	if err := g.generateFactoryHelpers(outputDir); err != nil {
+		return err
+	}
+
 	return g.generateConfigFile(outputDir)
 }
 
 func (g *Generator) generateDBFile(outputDir string) error {
 	filename := filepath.Join(outputDir, "db.go")
-	
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
 
 	tmpl := template.Must(template.New("db").Parse(dbTemplate))
-	
+
 	data := struct {
 		PackageName string
 	}{
 		PackageName: "models",
 	}
 
-	return tmpl.Execute(file, data)
+	return g.render(filename, tmpl, data)
 }
 
 func (g *Generator) generateConfigFile(outputDir string) error {
 	filename := filepath.Join(outputDir, "config.go")
-	
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
 
 	tmpl := template.Must(template.New("config").Parse(configTemplate))
-	
+
 	data := struct {
 		PackageName string
 	}{
 		PackageName: "models",
 	}
 
-	return tmpl.Execute(file, data)
+	return g.render(filename, tmpl, data)
 }
 
-func (g *Generator) getGoType(fieldType string) string {
+func (g *Generator) getFieldType(field core.FieldSchema) string {
+	if field.Array {
+		return "[]" + g.getArrayElemType(field.Type)
+	}
+	if field.GoType != "" {
+		_, name := customGoType(field.GoType)
+		if field.Optional {
+			return "*" + name
+		}
+		return name
+	}
+	if field.Optional {
+		return "*" + g.getGoType(field.Type)
+	}
+	return g.getGoType(field.Type)
+}
+
+func customGoType(spec string) (string, string) {
+	i := strings.LastIndex(spec, ".")
+	importPath := spec[:i]
+
+	pkg := importPath[strings.LastIndex(importPath, "/")+1:]
+	if j := strings.Index(pkg, "."); j > 0 {
+		pkg = pkg[:j]
+	}
+	return importPath, pkg + "." + spec[i+1:]
+}
+
+// headerImports are the packages modelHeaderTemplate always imports, so a
+// custom type from one of them must not be imported a second time.
+var headerImports = []string{"context", "database/sql", "fmt", "strings", "time", "github.com/nitrix4ly/comet/core"}
+
+func customImports(model core.ModelSchema) []string {
+	seen := map[string]bool{}
+	for _, importPath := range headerImports {
+		seen[importPath] = true
+	}
+	var imports []string
+	for _, field := range model.Fields {
+		if field.GoType == "" {
+			continue
+		}
+		importPath, _ := customGoType(field.GoType)
+		if !seen[importPath] {
+			seen[importPath] = true
+			imports = append(imports, importPath)
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+func (g *Generator) getArrayElemType(fieldType string) string {
 	switch fieldType {
 	case "Int":
-		return "int"
-	case "String":
-		return "string"
+		return "int64"
 	case "Boolean":
 		return "bool"
 	case "Float":
 		return "float64"
-	case "DateTime":
-		return "time.Time"
 	default:
 		return "string"
 	}
 }
 
-const modelTemplate = `package {{.PackageName}}
+func (g *Generator) bindExpr(field core.FieldSchema) string {
+	if field.Array {
+		return "pq.Array(m." + field.Name + ")"
+	}
+	return "m." + field.Name
+}
 
-import (
-	"context"
-	"database/sql"
-	"fmt"
-	"time"
+func (g *Generator) scanDest(field core.FieldSchema) string {
+	if field.Array {
+		return "pq.Array(&m." + field.Name + ")"
+	}
+	return "&m." + field.Name
+}
 
-	"github.com/nitrix4ly/comet/core"
-)
+func truncateExpr(field core.FieldSchema) string {
+	if field.Precision == nil {
+		return ""
+	}
 
-type {{.Model.Name}} struct {
-{{- range .Model.Fields}}
-	{{.Name}} {{if .Optional}}*{{end}}{{$.GoType .Type}} ` + "`json:\"{{.Name | ToSnakeCase}}\" db:\"{{.Name | ToSnakeCase}}\"`" + `
-{{- end}}
-{{- if .HasTimestamps}}
-	CreatedAt time.Time ` + "`json:\"created_at\" db:\"created_at\"`" + `
-	UpdatedAt time.Time ` + "`json:\"updated_at\" db:\"updated_at\"`" + `
-{{- end}}
-	isNew bool ` + "`json:\"-\"`" + `
+	switch *field.Precision {
+	case 0:
+		return ".Truncate(time.Second)"
+	case 3:
+		return ".Truncate(time.Millisecond)"
+	case 6:
+		return ".Truncate(time.Microsecond)"
+	}
+	unit := 1
+	for i := *field.Precision; i < 9; i++ {
+		unit *= 10
+	}
+	return fmt.Sprintf(".Truncate(%d * time.Nanosecond)", unit)
 }
 
-func (m *{{.Model.Name}}) TableName() string {
-	return "{{.Model.TableName}}"
+func hasArrayFields(model core.ModelSchema) bool {
+	for _, field := range model.Fields {
+		if field.Array {
+			return true
+		}
+	}
+	return false
 }
 
-func (m *{{.Model.Name}}) IsNew() bool {
-	return m.isNew{{range .Model.Fields}}{{if .Primary}} || m.{{.Name}} == 0{{end}}{{end}}
+func hasUniqueFields(model core.ModelSchema) bool {
+	for _, field := range model.Fields {
+		if field.Unique && !field.Array {
+			return true
+		}
+	}
+	return false
 }
 
-func (m *{{.Model.Name}}) Save(ctx context.Context) error {
-	db := core.GetDB()
-	if db == nil {
-		return fmt.Errorf("database not initialized")
+type columnName struct {
+	Name   string
+	Column string
+}
+
+type projection struct {
+	Name   string
+	Method string
+	Fields []projectionField
+}
+
+type projectionField struct {
+	Name   string
+	Column string
+	Type   string
+	Dest   string
+}
+
+func (g *Generator) projections(model core.ModelSchema) []projection {
+	var result []projection
+	for _, p := range model.Projections {
+		method := p.Name
+		if rest := strings.TrimPrefix(p.Name, model.Name); rest != p.Name && rest != "" && rest[0] >= 'A' && rest[0] <= 'Z' {
+			method = rest
+		}
+
+		proj := projection{
+			Name:   p.Name,
+			Method: core.ToPlural(method),
+		}
+		for _, name := range p.Fields {
+			field := projectionField{
+				Name:   core.ToPascalCase(name),
+				Column: g.naming.ColumnName(name),
+				Type:   "time.Time",
+			}
+			for _, f := range model.Fields {
+				if f.Name == name {
+					field.Column = g.naming.ColumnName(f.Name)
+					field.Type = g.getFieldType(f)
+					break
+				}
+			}
+			field.Dest = "&m." + field.Name
+			if strings.HasPrefix(field.Type, "[]") && field.Type != "[]byte" {
+				field.Dest = "pq.Array(&m." + field.Name + ")"
+			}
+			proj.Fields = append(proj.Fields, field)
+		}
+		result = append(result, proj)
 	}
+	return result
+}
 
-	now := time.Now()
-	if m.IsNew() {
-{{- if .HasTimestamps}}
-		m.CreatedAt = now
-{{- end}}
-		return m.insert(ctx, db)
+func (g *Generator) modelColumns(model core.ModelSchema) []columnName {
+	var columns []columnName
+	seen := make(map[string]bool)
+
+	add := func(name, column string) {
+		if seen[column] {
+			return
+		}
+		seen[column] = true
+		columns = append(columns, columnName{
+			Name:   core.ToPascalCase(name),
+			Column: column,
+		})
 	}
-	
-{{- if .HasTimestamps}}
-	m.UpdatedAt = now
-{{- end}}
-	return m.update(ctx, db)
+
+	for _, field := range model.Fields {
+		add(field.Name, g.naming.ColumnName(field.Name))
+	}
+	add("CreatedAt", g.naming.ColumnName("createdAt"))
+	add("UpdatedAt", g.naming.ColumnName("updatedAt"))
+
+	return columns
 }
 
-func (m *{{.Model.Name}}) Delete(ctx context.Context) error {
-	db := core.GetDB()
-	if db == nil {
-		return fmt.Errorf("database not initialized")
+type columnDest struct {
+	Column string
+	Dest   string
+}
+
+func (g *Generator) columnDests(model core.ModelSchema) []columnDest {
+	var dests []columnDest
+	seen := make(map[string]bool)
+
+	for _, field := range model.Fields {
+		column := g.naming.ColumnName(field.Name)
+		if seen[column] {
+			continue
+		}
+		seen[column] = true
+		dests = append(dests, columnDest{Column: column, Dest: g.scanDest(field)})
 	}
 
-	query := "DELETE FROM {{.Model.TableName}} WHERE {{range .Model.Fields}}{{if .Primary}}{{.Name | ToSnakeCase}} = ?{{end}}{{end}}"
-	_, err := db.Exec(ctx, query{{range .Model.Fields}}{{if .Primary}}, m.{{.Name}}{{end}}{{end}})
-	return err
+	for _, name := range []string{"CreatedAt", "UpdatedAt"} {
+		column := g.naming.ColumnName(core.ToCamelCase(name))
+		if !seen[column] {
+			dests = append(dests, columnDest{Column: column, Dest: "&m." + name})
+		}
+	}
+
+	return dests
 }
 
-func (m *{{.Model.Name}}) insert(ctx context.Context, db *core.DB) error {
-	query := ` + "`INSERT INTO {{.Model.TableName}} (" +
-		`{{range $i, $field := .Model.Fields}}{{if not .Primary}}{{if $i}}, {{end}}{{.Name | ToSnakeCase}}{{end}}{{end}}` +
-		`{{if .HasTimestamps}}, created_at, updated_at{{end}}) VALUES (` +
-		`{{range $i, $field := .Model.Fields}}{{if not .Primary}}{{if $i}}?, {{else}}?{{end}}{{end}}{{end}}` +
-		`{{if .HasTimestamps}}, ?, ?{{end}})`+"`"+`
-	
-	result, err := db.Exec(ctx, query{{range .Model.Fields}}{{if not .Primary}}, m.{{.Name}}{{end}}{{end}}{{if .HasTimestamps}}, m.CreatedAt, m.UpdatedAt{{end}})
-	if err != nil {
-		return err
+type hasManyRelation struct {
+	Name     string
+	Model    string
+	Column   string
+	Key      string
+	Adder    string
+	Setter   string
+	Optional bool
+	Inverse  string
+}
+
+type belongsToRelation struct {
+	Name     string
+	Model    string
+	Setter   string
+	Key      string
+	Optional bool
+}
+
+func (g *Generator) hasManyRelations(model core.ModelSchema) []hasManyRelation {
+	var relations []hasManyRelation
+	targets := make(map[string]int)
+	for _, relation := range model.Relations {
+		if relation.Type == "hasMany" {
+			targets[relation.Model]++
+		}
 	}
 
-{{range .Model.Fields}}{{if .Primary}}{{if .AutoGen}}
-	id, err := result.LastInsertId()
-	if err != nil {
-		return err
+	for _, relation := range model.Relations {
+		if relation.Type != "hasMany" {
+			continue
+		}
+
+		if inverse, ok := g.inverseRelation(model, relation); ok {
+			r := hasManyRelation{
+				Name:   core.ToPascalCase(relation.FieldName),
+				Model:  relation.Model,
+				Column: g.naming.ColumnName(inverse.Fields[0]),
+				Key:    inverse.References[0],
+			}
+
+			child := g.models[relation.Model]
+			if fk := findField(&child, inverse.Fields[0]); fk != nil && !fk.Primary && fk.Computed == "" {
+				r.Adder = "Add" + relation.Model
+				if targets[relation.Model] > 1 {
+					r.Adder = "AddTo" + r.Name
+				}
+				r.Setter = "Set" + core.ToPascalCase(fk.Name)
+				r.Optional = fk.Optional
+				r.Inverse = core.ToPascalCase(inverse.FieldName)
+			}
+			relations = append(relations, r)
+		}
 	}
-	m.{{.Name}} = {{$.GoType .Type}}(id)
-{{end}}{{end}}{{end}}
-	m.isNew = false
-	return nil
+	return relations
 }
 
-func (m *{{.Model.Name}}) update(ctx context.Context, db *core.DB) error {
-	query := ` + "`UPDATE {{.Model.TableName}} SET " +
-		`{{range $i, $field := .Model.Fields}}{{if not .Primary}}{{if $i}}, {{end}}{{.Name | ToSnakeCase}} = ?{{end}}{{end}}` +
-		`{{if .HasTimestamps}}, updated_at = ?{{end}} WHERE ` +
-		`{{range .Model.Fields}}{{if .Primary}}{{.Name | ToSnakeCase}} = ?{{end}}{{end}}`+"`"+`
-	
-	_, err := db.Exec(ctx, query{{range .Model.Fields}}{{if not .Primary}}, m.{{.Name}}{{end}}{{end}}{{if .HasTimestamps}}, m.UpdatedAt{{end}}{{range .Model.Fields}}{{if .Primary}}, m.{{.Name}}{{end}}{{end}})
-	return err
+func (g *Generator) belongsToRelations(model core.ModelSchema) []belongsToRelation {
+	var relations []belongsToRelation
+	for _, relation := range model.Relations {
+		if relation.Type != "belongsTo" || len(relation.Fields) != 1 || len(relation.References) != 1 {
+			continue
+		}
+		if _, ok := g.models[relation.Model]; !ok {
+			continue
+		}
+
+		fk := findField(&model, relation.Fields[0])
+		if fk == nil || fk.Primary || fk.Computed != "" {
+			continue
+		}
+
+		relations = append(relations, belongsToRelation{
+			Name:     core.ToPascalCase(relation.FieldName),
+			Model:    relation.Model,
+			Setter:   "Set" + core.ToPascalCase(fk.Name),
+			Key:      relation.References[0],
+			Optional: fk.Optional,
+		})
+	}
+	return relations
 }
 
-var {{.Model.Name}}Query = &{{.Model.Name}}QueryBuilder{}
+func (g *Generator) inverseRelation(model core.ModelSchema, relation core.Relation) (core.Relation, bool) {
+	child, ok := g.models[relation.Model]
+	if !ok {
+		return core.Relation{}, false
+	}
 
-type {{.Model.Name}}QueryBuilder struct{}
+	for _, inverse := range child.Relations {
+		if inverse.Type != "belongsTo" || inverse.Name != relation.Name || inverse.Model != model.Name {
+			continue
+		}
+		if len(inverse.Fields) == 1 && len(inverse.References) == 1 {
+			return inverse, true
+		}
+	}
+	return core.Relation{}, false
+}
 
-func (q *{{.Model.Name}}QueryBuilder) Find() core.QueryBuilder {
-	return core.NewQueryExecutor("{{.Model.TableName}}", "{{.Model.Name}}", scan{{.Model.Name}})
+type relationLink struct {
+	Name         string
+	Model        string
+	Table        string
+	LocalKey     string
+	ForeignKey   string
+	LocalField   string
+	ForeignField string
+	Many         bool
 }
 
-func (q *{{.Model.Name}}QueryBuilder) FindById(ctx context.Context, id {{range .Model.Fields}}{{if .Primary}}{{$.GoType .Type}}{{end}}{{end}}) (*{{.Model.Name}}, error) {
-	result, err := q.Find().Where("{{range .Model.Fields}}{{if .Primary}}{{.Name | ToSnakeCase}}{{end}}{{end}}", "=", id).First(ctx)
-	if err != nil {
-		return nil, err
+func (g *Generator) relationLinks(model core.ModelSchema) []relationLink {
+	var links []relationLink
+	for _, relation := range model.Relations {
+		target, ok := g.models[relation.Model]
+		if !ok {
+			continue
+		}
+
+		link := relationLink{
+			Name:  core.ToPascalCase(relation.FieldName),
+			Model: target.Name,
+			Table: target.TableName,
+		}
+
+		switch relation.Type {
+		case "belongsTo":
+			if len(relation.Fields) != 1 || len(relation.References) != 1 {
+				continue
+			}
+			link.LocalKey = g.naming.ColumnName(relation.Fields[0])
+			link.ForeignKey = g.naming.ColumnName(relation.References[0])
+			link.LocalField = relation.Fields[0]
+			link.ForeignField = relation.References[0]
+		case "hasMany", "hasOne":
+			inverse, ok := g.inverseRelation(model, relation)
+			if !ok {
+				continue
+			}
+			link.LocalKey = g.naming.ColumnName(inverse.References[0])
+			link.ForeignKey = g.naming.ColumnName(inverse.Fields[0])
+			link.LocalField = inverse.References[0]
+			link.ForeignField = inverse.Fields[0]
+			link.Many = relation.Type == "hasMany"
+		default:
+			continue
+		}
+
+		if findField(&model, link.LocalField) == nil || findField(&target, link.ForeignField) == nil {
+			link.LocalField, link.ForeignField = "", ""
+		}
+		links = append(links, link)
 	}
-	return result.(*{{.Model.Name}}), nil
+	return links
 }
 
-func (q *{{.Model.Name}}QueryBuilder) Raw(query string, args ...interface{}) core.QueryBuilder {
-	return core.NewQueryExecutor("{{.Model.TableName}}", "{{.Model.Name}}", scan{{.Model.Name}})
+type polymorphicRelation struct {
+	Name      string
+	TypeField string
+	IdField   string
+	Targets   []polymorphicTarget
 }
 
-func scan{{.Model.Name}}(rows *sql.Rows) (interface{}, error) {
-	var m {{.Model.Name}}
-	err := rows.Scan(
-{{- range .Model.Fields}}
-		&m.{{.Name}},
-{{- end}}
-{{- if .HasTimestamps}}
-		&m.CreatedAt,
-		&m.UpdatedAt,
-{{- end}}
-	)
-	if err != nil {
-		return nil, err
+type polymorphicTarget struct {
+	Model string
+	Key   string
+}
+
+func (g *Generator) polymorphicRelations(model core.ModelSchema) []polymorphicRelation {
+	var relations []polymorphicRelation
+	for _, relation := range model.Relations {
+		if relation.Type != "polymorphic" {
+			continue
+		}
+
+		var targets []polymorphicTarget
+		for _, name := range relation.Models {
+			if primary := primaryField(g.models[name]); primary != nil {
+				targets = append(targets, polymorphicTarget{Model: name, Key: primary.Name})
+			}
+		}
+
+		relations = append(relations, polymorphicRelation{
+			Name:      core.ToPascalCase(relation.FieldName),
+			TypeField: relation.Fields[0],
+			IdField:   relation.Fields[1],
+			Targets:   targets,
+		})
 	}
-	return &m, nil
+	return relations
 }
-`
 
-const dbTemplate = `package {{.PackageName}}
+func primaryField(model core.ModelSchema) *core.FieldSchema {
+	var primary *core.FieldSchema
+	for i := range model.Fields {
+		if model.Fields[i].Primary {
+			if primary != nil {
+				return nil
+			}
+			primary = &model.Fields[i]
+		}
+	}
+	return primary
+}
 
-import (
-	"github.com/nitrix4ly/comet/core"
-	"github.com/nitrix4ly/comet/drivers"
-)
+func (g *Generator) keyWhere(model core.ModelSchema) string {
+	var conditions []string
+	for _, field := range model.Fields {
+		if field.Primary {
+			conditions = append(conditions, g.naming.ColumnName(field.Name)+" = ?")
+		}
+	}
+	return strings.Join(conditions, " AND ")
+}
 
-func InitDB(driverName, dsn string) error {
-	var driver core.Driver
-	
-	switch driverName {
-	case "postgres":
-		driver = &drivers.PostgresDriver{}
-	case "mysql":
-		driver = &drivers.MySQLDriver{}
-	case "sqlite":
-		driver = &drivers.SQLiteDriver{}
-	default:
-		driver = &drivers.SQLiteDriver{}
+func (g *Generator) emptyKeyCondition(model core.ModelSchema) string {
+	var checks []string
+	for _, field := range model.Fields {
+		if !field.Primary {
+			continue
+		}
+
+		value := "m." + field.Name
+		switch g.getGoType(field.Type) {
+		case "int", "float64":
+			checks = append(checks, value+" == 0")
+		case "string":
+			checks = append(checks, value+` == ""`)
+		case "time.Time":
+			checks = append(checks, value+".IsZero()")
+		default:
+			checks = append(checks, "core.IsZeroValue("+value+")")
+		}
 	}
-	
-	db, err := core.NewDB(driver, dsn)
-	if err != nil {
-		return err
+
+	if len(checks) > 1 {
+		return "(" + strings.Join(checks, " && ") + ")"
 	}
-	
-	core.SetDB(db)
-	return nil
+	return strings.Join(checks, "")
+}
+
+func insertFields(model core.ModelSchema) []core.FieldSchema {
+	var fields []core.FieldSchema
+	for _, field := range model.Fields {
+		if !(field.Primary && field.AutoGen) && field.Computed == "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func (g *Generator) keyColumns(model core.ModelSchema) []string {
+	var columns []string
+	seen := make(map[string]bool)
+	add := func(field core.FieldSchema) {
+		column := g.naming.ColumnName(field.Name)
+		if !field.Optional && !field.Array && !seen[column] {
+			seen[column] = true
+			columns = append(columns, column)
+		}
+	}
+	for _, field := range model.Fields {
+		if field.Primary || field.Unique {
+			add(field)
+		}
+	}
+	for _, index := range model.Indexes {
+		if index.Unique && len(index.Fields) == 1 {
+			if field := findField(&model, index.Fields[0]); field != nil {
+				add(*field)
+			}
+		}
+	}
+	return columns
+}
+
+func (g *Generator) uniqueColumns(model core.ModelSchema) []string {
+	var columns []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		column := g.naming.ColumnName(name)
+		if !seen[column] {
+			seen[column] = true
+			columns = append(columns, column)
+		}
+	}
+	for _, field := range model.Fields {
+		if field.Unique && !field.Array {
+			add(field.Name)
+		}
+	}
+	for _, index := range model.Indexes {
+		if index.Unique {
+			for _, name := range index.Fields {
+				add(name)
+			}
+		}
+	}
+	return columns
+}
+
+func (g *Generator) searchColumns(model core.ModelSchema) []string {
+	var columns []string
+	for _, field := range model.Fields {
+		if field.Searchable {
+			columns = append(columns, g.naming.ColumnName(field.Name))
+		}
+	}
+	return columns
+}
+
+func (g *Generator) computedColumns(model core.ModelSchema) []string {
+	if primaryField(model) == nil {
+		return nil
+	}
+
+	var columns []string
+	for _, field := range model.Fields {
+		if field.Computed != "" {
+			columns = append(columns, g.naming.ColumnName(field.Name))
+		}
+	}
+	return columns
+}
+
+func tenantField(model core.ModelSchema) *core.FieldSchema {
+	for i := range model.Fields {
+		if model.Fields[i].Tenant {
+			return &model.Fields[i]
+		}
+	}
+	return nil
+}
+
+func (g *Generator) getGoType(fieldType string) string {
+	switch fieldType {
+	case "Int":
+		return "int"
+	case "String":
+		return "string"
+	case "Boolean":
+		return "bool"
+	case "Float":
+		return "float64"
+	case "DateTime":
+		return "time.Time"
+	case "Bytes":
+		return "[]byte"
+	default:
+		if _, ok := g.enums[fieldType]; ok {
+			return fieldType
+		}
+		return "string"
+	}
+}
+
+const modelHeaderTemplate = `package {{.PackageName}}
+
+import (
+	"context"
+	"database/sql"
+{{- if or .HasUnique .UniqueColumns .PrimaryField}}
+	"errors"
+{{- end}}
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nitrix4ly/comet/core"
+{{- if .HasArrays}}
+	"github.com/lib/pq"
+{{- end}}
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+`
+
+const modelTemplate = modelHeaderTemplate + `
+type {{.Model.Name}} struct {
+{{- range .Model.Fields}}
+	{{.Name}} {{call $.FieldType .}} ` + "`json:\"{{if .Hidden}}-{{else}}{{.Name | Column}}{{end}}\" db:\"{{.Name | Column}}\"`" + `
+{{- end}}
+{{- if .HasTimestamps}}
+	CreatedAt time.Time ` + "`json:\"{{Column \"createdAt\"}}\" db:\"{{Column \"createdAt\"}}\"`" + `
+	UpdatedAt time.Time ` + "`json:\"{{Column \"updatedAt\"}}\" db:\"{{Column \"updatedAt\"}}\"`" + `
+{{- end}}
+{{- range .Relations}}
+	{{.Name}} {{if .Many}}[]{{end}}*{{.Model}} ` + "`json:\"{{.Name | ToSnakeCase}},omitempty\" db:\"-\"`" + `
+{{- end}}
+	isNew bool ` + "`json:\"-\"`" + `
+	original *{{.Model.Name}} ` + "`json:\"-\"`" + `
+	dirty map[string]bool ` + "`json:\"-\"`" + `
+}
+
+const {{.Model.Name}}Table = "{{.Model.TableName}}"
+
+var {{.Model.Name}}Columns = struct {
+{{- range .Columns}}
+	{{.Name}} string
+{{- end}}
+}{
+{{- range .Columns}}
+	{{.Name}}: "{{.Column}}",
+{{- end}}
+}
+{{- if .Relations}}
+
+func init() {
+{{- range .Relations}}
+	core.RegisterRelation("{{$.Model.Name}}", "{{.Name}}", core.RelationInfo{
+		Table:      "{{.Table}}",
+		LocalKey:   "{{.LocalKey}}",
+		ForeignKey: "{{.ForeignKey}}",
+{{- if .Many}}
+		Many:       true,
+{{- end}}
+		Query: func() core.QueryBuilder {
+			return {{.Model}}Query.Find()
+		},
+{{- if not .Many}}
+		New: func() core.Joinable {
+			return &{{.Model}}{}
+		},
+{{- end}}
+{{- if .LocalField}}
+		LocalValue: func(model interface{}) interface{} {
+			return model.(*{{$.Model.Name}}).{{.LocalField}}
+		},
+		ForeignValue: func(related interface{}) interface{} {
+			return related.(*{{.Model}}).{{.ForeignField}}
+		},
+{{- end}}
+	})
+{{- end}}
+}
+{{- end}}
+
+func (m *{{.Model.Name}}) TableName() string {
+	return {{.Model.Name}}Table
+}
+
+func (m *{{.Model.Name}}) IsNew() bool {
+	return m.isNew{{with .EmptyKey}} || {{.}}{{end}}
+}
+
+func (m *{{.Model.Name}}) Save(ctx context.Context) error {
+	return m.save(ctx, core.GetDB(), nil)
+}
+
+func (m *{{.Model.Name}}) save(ctx context.Context, db *core.DB, returning []string) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if core.IsReadOnly(ctx) {
+		return core.ErrReadOnly
+	}
+	if err := m.Validate(); err != nil {
+		return err
+	}
+{{- if .TenantField}}
+	if err := m.checkTenant(ctx); err != nil {
+		return err
+	}
+{{- end}}
+
+	now := time.Now()
+	if m.IsNew() {
+{{- if .HasTimestamps}}
+		m.CreatedAt = now
+{{- end}}
+		m.setDefaultTimes(now)
+		return m.insert(ctx, db, returning)
+	}
+	
+{{- if .HasTimestamps}}
+	m.UpdatedAt = now
+{{- end}}
+	return m.update(ctx, db)
+}
+
+func (m *{{.Model.Name}}) Delete(ctx context.Context) error {
+	return m.delete(ctx, core.GetDB())
+}
+
+func (m *{{.Model.Name}}) delete(ctx context.Context, db *core.DB) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if core.IsReadOnly(ctx) {
+		return core.ErrReadOnly
+	}
+{{- if .TenantField}}
+	if err := m.checkTenant(ctx); err != nil {
+		return err
+	}
+{{- end}}
+
+	query := "DELETE FROM {{.Model.TableName}} WHERE {{.KeyWhere}}"
+	return m.withAudit(ctx, db, func(ctx context.Context) error {
+		if _, err := db.Exec(ctx, query{{range .Model.Fields}}{{if .Primary}}, m.{{.Name}}{{end}}{{end}}); err != nil {
+			return err
+		}
+		return m.audit(ctx, core.AuditDelete, {{.Model.Name | FirstLower}}AuditColumns)
+	})
+}
+
+func (m *{{.Model.Name}}) withAudit(ctx context.Context, db *core.DB, write func(ctx context.Context) error) error {
+	if !core.AuditEnabled() {
+		return write(ctx)
+	}
+	return db.WithTransaction(ctx, func(ctx context.Context, tx *core.Tx) error {
+		return write(ctx)
+	})
+}
+
+var {{.Model.Name | FirstLower}}AuditColumns = []string{ {{- range $i, $field := .InsertFields}}{{if $i}}, {{end}}"{{.Name | Column}}"{{end}}}
+
+func (m *{{.Model.Name}}) audit(ctx context.Context, operation string, columns []string) error {
+	if !core.AuditEnabled() {
+		return nil
+	}
+
+	changes := make(map[string]core.AuditChange, len(columns))
+	for _, column := range columns {
+		var change core.AuditChange
+		current, _ := m.columnValue(column)
+		switch operation {
+		case core.AuditCreate:
+			change.New = current
+		case core.AuditUpdate:
+			if m.original != nil {
+				change.Old, _ = m.original.columnValue(column)
+			}
+			change.New = current
+		case core.AuditDelete:
+			change.Old = current
+			if m.original != nil {
+				change.Old, _ = m.original.columnValue(column)
+			}
+		}
+		changes[column] = change
+	}
+
+	return core.Audit(ctx, core.AuditEvent{
+		Table:      "{{.Model.TableName}}",
+		Operation:  operation,
+		PrimaryKey: {{with .PrimaryField}}m.{{.Name}}{{else}}nil{{end}},
+		Changes:    changes,
+	})
+}
+
+{{with .TenantField -}}
+func (m *{{$.Model.Name}}) checkTenant(ctx context.Context) error {
+	tenant, err := {{$.Model.Name | FirstLower}}Tenant(ctx)
+	if err != nil {
+		return err
+	}
+	if m.IsNew() {
+		m.{{.Name}} = tenant
+		return nil
+	}
+	if m.{{.Name}} != tenant {
+		return core.ErrTenantMismatch
+	}
+	return nil
+}
+
+func {{$.Model.Name | FirstLower}}Tenant(ctx context.Context) ({{call $.GoType .Type}}, error) {
+	value, ok := core.TenantFrom(ctx)
+	if !ok {
+		return {{if eq (call $.GoType .Type) "string"}}""{{else}}0{{end}}, core.ErrMissingTenant
+	}
+	tenant, ok := value.({{call $.GoType .Type}})
+	if !ok {
+		return {{if eq (call $.GoType .Type) "string"}}""{{else}}0{{end}}, fmt.Errorf("tenant has type %T, {{$.Model.Name}} expects {{call $.GoType .Type}}", value)
+	}
+	return tenant, nil
+}
+
+{{end -}}
+func (m *{{.Model.Name}}) Validate() error {
+	var errs []core.FieldError
+{{- range .Model.Fields}}{{if .Enum}}
+{{- if .Optional}}
+	if m.{{.Name}} != nil && !m.{{.Name}}.IsValid() {
+		errs = append(errs, core.FieldError{Field: "{{.Name | Column}}", Rule: "enum", Message: fmt.Sprintf("invalid {{.Type}} %q", *m.{{.Name}})})
+	}
+{{- else}}
+	if !m.{{.Name}}.IsValid() {
+		errs = append(errs, core.FieldError{Field: "{{.Name | Column}}", Rule: "enum", Message: fmt.Sprintf("invalid {{.Type}} %q", m.{{.Name}})})
+	}
+{{- end}}
+{{- else if .GoType}}
+	if v, ok := interface{}({{if not .Optional}}&{{end}}m.{{.Name}}).(interface{ Validate() error }); ok{{if .Optional}} && m.{{.Name}} != nil{{end}} {
+		if err := v.Validate(); err != nil {
+			errs = append(errs, core.FieldError{Field: "{{.Name | Column}}", Rule: "type", Message: err.Error()})
+		}
+	}
+{{- end}}{{end}}
+	if len(errs) > 0 {
+		return &core.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func (m *{{.Model.Name}}) insertColumns() ([]string, []interface{}) {
+	columns := []string{ {{- range $i, $field := .InsertFields}}{{if $i}}, {{end}}"{{.Name | Column}}"{{end}}{{if .HasTimestamps}}, "{{Column "createdAt"}}", "{{Column "updatedAt"}}"{{end}}}
+	args := []interface{}{ {{- range $i, $field := .InsertFields}}{{if $i}}, {{end}}{{call $.Bind .}}{{end}}{{if .HasTimestamps}}, m.CreatedAt, m.UpdatedAt{{end}}}
+{{- with .PrimaryField}}{{if .AutoGen}}
+	if !core.IsZeroValue(m.{{.Name}}) {
+		columns = append([]string{"{{.Name | Column}}"}, columns...)
+		args = append([]interface{}{m.{{.Name}}}, args...)
+	}
+{{- end}}{{end}}
+	return columns, args
+}
+
+func (m *{{.Model.Name}}) insertStatement() (string, []interface{}) {
+	columns, args := m.insertColumns()
+	query := "INSERT INTO {{.Model.TableName}} (" + strings.Join(columns, ", ") + ") VALUES (" + core.BuildPlaceholders(len(columns)) + ")"
+	return query, args
+}
+
+func (m *{{.Model.Name}}) insert(ctx context.Context, db *core.DB, returning []string) error {
+	m.normalizeTimes()
+	query, args := m.insertStatement()
+
+	var columns []string
+{{- if .Computed}}
+	columns = append(columns, {{range $i, $column := .Computed}}{{if $i}}, {{end}}"{{$column}}"{{end}})
+{{- end}}
+{{- if not .PrimaryField}}
+	columns = append(columns, returning...)
+	returning = nil
+{{- end}}
+{{- with .PrimaryField}}{{if .AutoGen}}
+	generated := core.IsZeroValue(m.{{.Name}})
+	if generated && db.Supports(core.FeatureReturning) {
+		columns = append([]string{"{{.Name | Column}}"}, columns...)
+	}
+{{- end}}{{end}}
+	dest, err := m.columnDests(columns)
+	if err != nil {
+		return err
+	}
+{{- if .PrimaryField}}
+	returningDest, err := m.columnDests(returning)
+	if err != nil {
+		return err
+	}
+{{- end}}
+
+	err = m.withAudit(ctx, db, func(ctx context.Context) error {
+		if len(columns) > 0 && db.Supports(core.FeatureReturning) {
+			if err := db.ExecReturning(ctx, query+" RETURNING "+strings.Join(columns, ", "), args, dest...); err != nil {
+				return err
+			}
+		} else {
+{{- with .PrimaryField}}
+{{- if .AutoGen}}
+			result, err := db.Exec(ctx, query, args...)
+			if err != nil {
+				return err
+			}
+			if generated {
+				id, err := result.LastInsertId()
+				if err != nil {
+					return err
+				}
+				m.{{.Name}} = {{call $.GoType .Type}}(id)
+			}
+{{- else}}
+			if _, err := db.Exec(ctx, query, args...); err != nil {
+				return err
+			}
+{{- end}}
+
+			if len(columns) > 0 {
+				selectQuery := "SELECT " + strings.Join(columns, ", ") + " FROM {{$.Model.TableName}} WHERE {{.Name | Column}} = ?"
+				if err := db.QueryRow(ctx, selectQuery, m.{{.Name}}).Scan(dest...); err != nil {
+					return err
+				}
+			}
+{{- else}}
+			if len(columns) > 0 {
+				return fmt.Errorf("cannot read back columns of {{.Model.Name}} without a primary key")
+			}
+			if _, err := db.Exec(ctx, query, args...); err != nil {
+				return err
+			}
+{{- end}}
+		}
+{{- with .PrimaryField}}
+
+		if len(returning) > 0 {
+			selectQuery := "SELECT " + strings.Join(returning, ", ") + " FROM {{$.Model.TableName}} WHERE {{.Name | Column}} = ?"
+			if err := db.QueryRow(ctx, selectQuery, m.{{.Name}}).Scan(returningDest...); err != nil {
+				return err
+			}
+		}
+{{- end}}
+		return m.audit(ctx, core.AuditCreate, {{.Model.Name | FirstLower}}AuditColumns)
+	})
+	if err != nil {
+{{- with .PrimaryField}}{{if .AutoGen}}
+		if generated {
+			m.{{.Name}} = {{if eq (call $.GoType .Type) "string"}}""{{else}}0{{end}}
+		}
+{{- end}}{{end}}
+		return err
+	}
+
+	m.isNew = false
+	m.snapshot()
+	return nil
+}
+
+func (m *{{.Model.Name}}) mysqlInsert(ctx context.Context, db *core.DB, verb, feature string) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not initialized")
+	}
+	if err := db.Require(feature); err != nil {
+		return false, err
+	}
+	if core.IsReadOnly(ctx) {
+		return false, core.ErrReadOnly
+	}
+	if err := m.Validate(); err != nil {
+		return false, err
+	}
+{{- if .TenantField}}
+	if err := m.checkTenant(ctx); err != nil {
+		return false, err
+	}
+{{- end}}
+
+
+	now := time.Now()
+{{- if .HasTimestamps}}
+	m.CreatedAt = now
+{{- end}}
+	m.setDefaultTimes(now)
+	m.normalizeTimes()
+{{- with .PrimaryField}}{{if .AutoGen}}
+	generated := core.IsZeroValue(m.{{.Name}})
+{{- end}}{{end}}
+	query, args := m.insertStatement()
+
+	inserted := false
+	err := m.withAudit(ctx, db, func(ctx context.Context) error {
+		result, err := db.Exec(ctx, verb+strings.TrimPrefix(query, "INSERT"), args...)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil || affected == 0 {
+			return err
+		}
+{{- with .PrimaryField}}{{if .AutoGen}}
+
+		if generated {
+			id, err := result.LastInsertId()
+			if err != nil {
+				return err
+			}
+			m.{{.Name}} = {{call $.GoType .Type}}(id)
+		}
+{{- end}}{{end}}
+		inserted = true
+		return m.audit(ctx, core.AuditCreate, {{.Model.Name | FirstLower}}AuditColumns)
+	})
+	if err != nil {
+{{- with .PrimaryField}}{{if .AutoGen}}
+		if generated {
+			m.{{.Name}} = {{if eq (call $.GoType .Type) "string"}}""{{else}}0{{end}}
+		}
+{{- end}}{{end}}
+		return false, err
+	}
+	if !inserted {
+		return false, nil
+	}
+
+	m.isNew = false
+	m.snapshot()
+	return true, nil
+}
+
+func (m *{{.Model.Name}}) update(ctx context.Context, db *core.DB) error {
+	_, err := m.updateColumns(ctx, db, m.changedColumns())
+	return err
+}
+
+func (m *{{.Model.Name}}) UpdateFields(ctx context.Context, fields ...string) error {
+	return m.updateFields(ctx, core.GetDB(), fields)
+}
+
+func (m *{{.Model.Name}}) updateFields(ctx context.Context, db *core.DB, fields []string) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if core.IsReadOnly(ctx) {
+		return core.ErrReadOnly
+	}
+{{- if .TenantField}}
+	if err := m.checkTenant(ctx); err != nil {
+		return err
+	}
+{{- end}}
+	if m.IsNew() {
+		return fmt.Errorf("cannot update fields of {{.Model.Name}} that has not been saved")
+	}
+
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column := m.fieldColumn(field)
+		if _, ok := m.columnValue(column); !ok {
+			return fmt.Errorf("unknown field '%s' on {{.Model.Name}}", field)
+		}
+		columns = append(columns, column)
+	}
+
+{{- if .HasTimestamps}}
+	m.UpdatedAt = time.Now()
+{{- end}}
+	_, err := m.updateColumns(ctx, db, columns)
+	return err
+}
+
+func (m *{{.Model.Name}}) updateColumns(ctx context.Context, db *core.DB, columns []string) (int64, error) {
+	if len(columns) == 0 {
+		return 0, nil
+	}
+	m.normalizeTimes()
+
+	sets := make([]string, 0, len(columns)+1)
+	args := make([]interface{}, 0, len(columns)+2)
+	for _, column := range columns {
+		value, _ := m.columnValue(column)
+		sets = append(sets, column+" = ?")
+		args = append(args, value)
+	}
+{{- if .HasTimestamps}}
+	sets = append(sets, "{{Column "updatedAt"}} = ?")
+	args = append(args, m.UpdatedAt)
+{{- end}}
+	args = append(args{{range .Model.Fields}}{{if .Primary}}, m.{{.Name}}{{end}}{{end}})
+
+	query := "UPDATE {{.Model.TableName}} SET " + strings.Join(sets, ", ") + " WHERE {{.KeyWhere}}"
+	var affected int64
+	err := m.withAudit(ctx, db, func(ctx context.Context) error {
+		result, err := db.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		if affected, err = result.RowsAffected(); err != nil {
+			return err
+		}
+		return m.audit(ctx, core.AuditUpdate, columns)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	m.snapshot()
+	return affected, nil
+}
+
+func (m *{{.Model.Name}}) setDefaultTimes(now time.Time) {
+{{- range .Model.Fields}}{{if call $.IsTimestamp .}}
+{{- if .Optional}}
+	if m.{{.Name}} == nil {
+		value := now
+		m.{{.Name}} = &value
+	}
+{{- else}}
+	if m.{{.Name}}.IsZero() {
+		m.{{.Name}} = now
+	}
+{{- end}}
+{{- end}}{{end}}
+}
+
+func (m *{{.Model.Name}}) normalizeTimes() {
+{{- range .Model.Fields}}{{if and (eq .Type "DateTime") (not .GoType)}}
+{{- if .Optional}}
+	if m.{{.Name}} != nil {
+		t := m.{{.Name}}.UTC(){{call $.Truncate .}}
+		m.{{.Name}} = &t
+	}
+{{- else}}
+	m.{{.Name}} = m.{{.Name}}.UTC(){{call $.Truncate .}}
+{{- end}}
+{{- end}}{{end}}
+{{- if .HasTimestamps}}
+	m.CreatedAt = m.CreatedAt.UTC(){{range .Model.Timestamps}}{{if eq .Name "createdAt"}}{{call $.Truncate .}}{{end}}{{end}}
+	m.UpdatedAt = m.UpdatedAt.UTC(){{range .Model.Timestamps}}{{if eq .Name "updatedAt"}}{{call $.Truncate .}}{{end}}{{end}}
+{{- end}}
+}
+
+func (m *{{.Model.Name}}) changedColumns() []string {
+	var columns []string
+	if len(m.dirty) > 0 {
+{{- range .Model.Fields}}{{if not (or .Primary .Computed)}}
+		if m.dirty["{{.Name | Column}}"] {
+			columns = append(columns, "{{.Name | Column}}")
+		}
+{{- end}}{{end}}
+		return columns
+	}
+
+{{- range .Model.Fields}}{{if not (or .Primary .Computed)}}
+	if m.original == nil || !core.ValuesEqual(m.{{.Name}}, m.original.{{.Name}}) {
+		columns = append(columns, "{{.Name | Column}}")
+	}
+{{- end}}{{end}}
+	return columns
+}
+
+func (m *{{.Model.Name}}) columnValue(column string) (interface{}, bool) {
+	switch column {
+{{- range .Model.Fields}}{{if not (or .Primary .Computed)}}
+	case "{{.Name | Column}}":
+		return {{call $.Bind .}}, true
+{{- end}}{{end}}
+	}
+	return nil, false
+}
+
+func (m *{{.Model.Name}}) fieldColumn(field string) string {
+	switch field {
+{{- range .Model.Fields}}{{if not (or .Primary .Computed)}}
+	case "{{.Name}}"{{if ne .Name (ToPascalCase .Name)}}, "{{ToPascalCase .Name}}"{{end}}:
+		return "{{.Name | Column}}"
+{{- end}}{{end}}
+	}
+	return field
+}
+
+func (m *{{.Model.Name}}) columnDest(column string) (interface{}, bool) {
+	switch column {
+{{- range .ColumnDests}}
+	case "{{.Column}}":
+		return {{.Dest}}, true
+{{- end}}
+	}
+	return nil, false
+}
+
+func (m *{{.Model.Name}}) columnDests(columns []string) ([]interface{}, error) {
+	dest := make([]interface{}, len(columns))
+	for i, column := range columns {
+		ptr, ok := m.columnDest(column)
+		if !ok {
+			return nil, fmt.Errorf("unknown column '%s' on {{.Model.Name}}", column)
+		}
+		dest[i] = ptr
+	}
+	return dest, nil
+}
+
+func (m *{{.Model.Name}}) markDirty(column string) {
+	if m.dirty == nil {
+		m.dirty = make(map[string]bool)
+	}
+	m.dirty[column] = true
+}
+{{- range .Model.Fields}}{{if not (or .Primary .Computed)}}
+
+func (m *{{$.Model.Name}}) Set{{.Name | ToPascalCase}}(value {{call $.FieldType .}}) {
+	m.{{.Name}} = value
+	m.markDirty("{{.Name | Column}}")
+}
+{{- end}}{{end}}
+
+{{- range .HasMany}}
+
+func (m *{{$.Model.Name}}) {{.Name}}Query() core.QueryBuilder {
+	return {{.Model}}Query.Find().Where("{{.Column}}", "=", m.{{.Key}})
+}
+
+func (m *{{$.Model.Name}}) {{.Name}}Count(ctx context.Context) (int64, error) {
+	return m.{{.Name}}Query().Count(ctx)
+}
+
+func (m *{{$.Model.Name}}) Has{{.Name}}(ctx context.Context) (bool, error) {
+	return m.{{.Name}}Query().Exists(ctx)
+}
+{{- if .Adder}}
+
+func (m *{{$.Model.Name}}) {{.Adder}}(children ...*{{.Model}}) {
+	for _, child := range children {
+{{- if .Optional}}
+		key := m.{{.Key}}
+		child.{{.Setter}}(&key)
+{{- else}}
+		child.{{.Setter}}(m.{{.Key}})
+{{- end}}
+		child.{{.Inverse}} = m
+	}
+}
+{{- end}}
+{{- end}}
+{{- range .BelongsTo}}
+
+func (m *{{$.Model.Name}}) Set{{.Name}}(related *{{.Model}}) {
+{{- if .Optional}}
+	if related == nil {
+		m.Clear{{.Name}}()
+		return
+	}
+	key := related.{{.Key}}
+	m.{{.Setter}}(&key)
+{{- else}}
+	m.{{.Setter}}(related.{{.Key}})
+{{- end}}
+	m.{{.Name}} = related
+}
+{{- if .Optional}}
+
+func (m *{{$.Model.Name}}) Clear{{.Name}}() {
+	m.{{.Setter}}(nil)
+	m.{{.Name}} = nil
+}
+{{- end}}
+{{- end}}
+{{- range $relation := .Polymorphic}}
+
+func (m *{{$.Model.Name}}) {{.Name}}(ctx context.Context) (interface{}, error) {
+	switch m.{{.TypeField}} {
+{{- range .Targets}}
+	case "{{.Model}}":
+		result, err := {{.Model}}Query.FindById(ctx, m.{{$relation.IdField}})
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+{{- end}}
+	}
+	return nil, fmt.Errorf("unknown {{.Name | FirstLower}} type '%s'", m.{{.TypeField}})
+}
+
+func (m *{{$.Model.Name}}) Set{{.Name}}(target interface{}) error {
+	switch t := target.(type) {
+{{- range .Targets}}
+	case *{{.Model}}:
+		m.Set{{$relation.TypeField | ToPascalCase}}("{{.Model}}")
+		m.Set{{$relation.IdField | ToPascalCase}}(t.{{.Key}})
+{{- end}}
+	default:
+		return fmt.Errorf("cannot use %T as {{.Name | FirstLower}}", target)
+	}
+	return nil
+}
+{{- end}}
+
+{{- with .PrimaryField}}
+
+func (m *{{$.Model.Name}}) Reload(ctx context.Context) error {
+	return m.ReloadWith(ctx)
+}
+
+func (m *{{$.Model.Name}}) ReloadWith(ctx context.Context, relations ...string) error {
+	query := {{$.Model.Name}}Query.Find().Where("{{.Name | Column}}", "=", m.{{.Name}})
+	if len(relations) > 0 {
+		query = query.Include(relations...)
+	}
+
+	result, err := query.First(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return core.ErrNotFound
+		}
+		return err
+	}
+	*m = *result.(*{{$.Model.Name}})
+	return nil
+}
+{{- end}}
+
+func (m *{{.Model.Name}}) Clone() *{{.Model.Name}} {
+	c := m.copy()
+{{- range .Model.Fields}}
+{{- if .Primary}}
+	c.{{.Name}} = {{if eq (call $.GoType .Type) "string"}}""{{else}}0{{end}}
+{{- else if call $.IsTimestamp .}}
+	c.{{.Name}} = {{if .Optional}}nil{{else}}time.Time{}{{end}}
+{{- end}}
+{{- end}}
+{{- if .HasTimestamps}}
+	c.CreatedAt = time.Time{}
+	c.UpdatedAt = time.Time{}
+{{- end}}
+	c.isNew = true
+	return &c
+}
+
+func (m *{{.Model.Name}}) copy() {{.Model.Name}} {
+	c := *m
+	c.original = nil
+	c.dirty = nil
+{{- range .Model.Fields}}
+{{- if .Array}}
+	c.{{.Name}} = append({{call $.FieldType .}}(nil), m.{{.Name}}...)
+{{- else if and (eq .Type "Bytes") (not .GoType)}}
+{{- if .Optional}}
+	if m.{{.Name}} != nil {
+		value := append((*m.{{.Name}})[:0:0], *m.{{.Name}}...)
+		c.{{.Name}} = &value
+	}
+{{- else}}
+	c.{{.Name}} = append(m.{{.Name}}[:0:0], m.{{.Name}}...)
+{{- end}}
+{{- else if .Optional}}
+	if m.{{.Name}} != nil {
+		value := *m.{{.Name}}
+		c.{{.Name}} = &value
+	}
+{{- end}}
+{{- end}}
+	return c
+}
+
+func (m *{{.Model.Name}}) CopyModel() interface{} {
+	c := m.copy()
+	if m.original != nil {
+		original := m.original.copy()
+		c.original = &original
+	}
+	return &c
+}
+
+func (m *{{.Model.Name}}) snapshot() {
+	m.dirty = nil
+	original := m.copy()
+	m.original = &original
+}
+
+func (m *{{.Model.Name}}) Equal(other *{{.Model.Name}}) bool {
+	return len(m.Diff(other)) == 0
+}
+
+func (m *{{.Model.Name}}) Diff(other *{{.Model.Name}}) map[string]interface{} {
+	return m.diff(other, false)
+}
+
+func (m *{{.Model.Name}}) DiffAll(other *{{.Model.Name}}) map[string]interface{} {
+	return m.diff(other, true)
+}
+
+func (m *{{.Model.Name}}) diff(other *{{.Model.Name}}, timestamps bool) map[string]interface{} {
+	if other == nil {
+		other = &{{.Model.Name}}{}
+	}
+
+	changes := make(map[string]interface{})
+{{- range .Model.Fields}}
+	if !core.ValuesEqual(m.{{.Name}}, other.{{.Name}}) {
+		changes["{{.Name | Column}}"] = other.{{.Name}}
+	}
+{{- end}}
+{{- if .HasTimestamps}}
+	if timestamps {
+		if !core.ValuesEqual(m.CreatedAt, other.CreatedAt) {
+			changes["{{Column "createdAt"}}"] = other.CreatedAt
+		}
+		if !core.ValuesEqual(m.UpdatedAt, other.UpdatedAt) {
+			changes["{{Column "updatedAt"}}"] = other.UpdatedAt
+		}
+	}
+{{- end}}
+	return changes
+}
+`
+
+const queryTemplate = `
+var {{.Model.Name}}Query = &{{.Model.Name}}QueryBuilder{}
+
+type {{.Model.Name}}QueryBuilder struct {
+	db *core.DB
+}
+
+func {{.Model.Name}}QueryOn(db *core.DB) *{{.Model.Name}}QueryBuilder {
+	return &{{.Model.Name}}QueryBuilder{db: db}
+}
+
+func (q *{{.Model.Name}}QueryBuilder) database() *core.DB {
+	if q.db != nil {
+		return q.db
+	}
+	return core.GetDB()
+}
+
+func (q *{{.Model.Name}}QueryBuilder) Find() core.QueryBuilder {
+	return core.NewQueryExecutorOn(q.db, "{{.Model.TableName}}", "{{.Model.Name}}", scan{{.Model.Name}}).JoinModel(func() core.Joinable {
+		return &{{.Model.Name}}{}
+	}){{with .TenantField}}.ScopeTenant("{{.Name | Column}}"){{end}}
+}
+
+{{- range .Model.Scopes}}
+
+func (q *{{$.Model.Name}}QueryBuilder) {{.Name | ToPascalCase}}() core.QueryBuilder {
+	return q.Find().WhereRaw({{printf "%q" .Condition}})
+}
+{{- end}}
+
+{{- range .Projections}}
+
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`json:\"{{.Column}}\" db:\"{{.Column}}\"`" + `
+{{- end}}
+}
+
+func (m *{{.Name}}) CopyModel() interface{} {
+	c := *m
+	return &c
+}
+
+func scan{{.Name}}(rows *sql.Rows) (interface{}, error) {
+	m := &{{.Name}}{}
+	if err := rows.Scan({{range $i, $field := .Fields}}{{if $i}}, {{end}}{{.Dest}}{{end}}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (q *{{$.Model.Name}}QueryBuilder) {{.Method}}() core.QueryBuilder {
+	return core.NewQueryExecutorOn(q.db, "{{$.Model.TableName}}", "{{$.Model.Name}}", scan{{.Name}}){{with $.TenantField}}.ScopeTenant("{{.Name | Column}}"){{end}}.Select({{range $i, $field := .Fields}}{{if $i}}, {{end}}"{{.Column}}"{{end}})
+}
+
+func (q *{{$.Model.Name}}QueryBuilder) Find{{.Method}}(ctx context.Context) ([]*{{.Name}}, error) {
+	results, err := q.{{.Method}}().All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*{{.Name}}, len(results))
+	for i, result := range results {
+		items[i] = result.(*{{.Name}})
+	}
+	return items, nil
+}
+{{- end}}
+
+func (q *{{.Model.Name}}QueryBuilder) Create(ctx context.Context, m *{{.Model.Name}}) (*{{.Model.Name}}, error) {
+	m.isNew = true
+	if err := m.save(ctx, q.database(), nil); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (q *{{.Model.Name}}QueryBuilder) CreateReturning(ctx context.Context, m *{{.Model.Name}}, columns ...string) (*{{.Model.Name}}, error) {
+	m.isNew = true
+	if err := m.save(ctx, q.database(), columns); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{- with .PrimaryField}}
+
+func (q *{{$.Model.Name}}QueryBuilder) CreateWithId(ctx context.Context, id {{call $.GoType .Type}}, m *{{$.Model.Name}}) (*{{$.Model.Name}}, error) {
+	m.{{.Name}} = id
+	return q.Create(ctx, m)
+}
+{{- end}}
+
+func (q *{{.Model.Name}}QueryBuilder) InsertIgnore(ctx context.Context, m *{{.Model.Name}}) (bool, error) {
+	return m.mysqlInsert(ctx, q.database(), "INSERT IGNORE", core.FeatureInsertIgnore)
+}
+
+func (q *{{.Model.Name}}QueryBuilder) Replace(ctx context.Context, m *{{.Model.Name}}) error {
+	_, err := m.mysqlInsert(ctx, q.database(), "REPLACE", core.FeatureReplace)
+	return err
+}
+
+func (q *{{.Model.Name}}QueryBuilder) CopyFrom(ctx context.Context, records []*{{.Model.Name}}) error {
+	db := q.database()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+
+	now := time.Now()
+	var columns []string
+	rows := make([][]interface{}, len(records))
+	for i, m := range records {
+		if err := m.Validate(); err != nil {
+			return err
+		}
+{{- if .TenantField}}
+		if err := m.checkTenant(ctx); err != nil {
+			return err
+		}
+{{- end}}
+{{- if .HasTimestamps}}
+		m.CreatedAt = now
+{{- end}}
+		m.setDefaultTimes(now)
+		m.normalizeTimes()
+
+		var recordColumns []string
+		recordColumns, rows[i] = m.insertColumns()
+		if i == 0 {
+			columns = recordColumns
+		} else if len(recordColumns) != len(columns) {
+			return fmt.Errorf("cannot copy {{.Model.Name}} records with and without explicit ids together")
+		}
+	}
+
+	return db.CopyFrom(ctx, "{{.Model.TableName}}", columns, rows)
+}
+
+func (q *{{.Model.Name}}QueryBuilder) Update(ctx context.Context, m *{{.Model.Name}}) (*{{.Model.Name}}, error) {
+	if m.IsNew() {
+		return nil, fmt.Errorf("cannot update {{.Model.Name}} that has not been saved")
+	}
+	if err := m.save(ctx, q.database(), nil); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{- if .UniqueColumns}}
+
+var {{.Model.Name | FirstLower}}UniqueColumns = map[string]bool{ {{- range $i, $column := .UniqueColumns}}{{if $i}}, {{end}}"{{$column}}": true{{end}}}
+
+func (q *{{.Model.Name}}QueryBuilder) CreateUnique(ctx context.Context, m *{{.Model.Name}}, columns ...string) (*{{.Model.Name}}, error) {
+	db := q.database()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	for _, column := range columns {
+		if !{{.Model.Name | FirstLower}}UniqueColumns[column] {
+			return nil, fmt.Errorf("'%s' is not a unique column of {{.Model.Name}}", column)
+		}
+	}
+
+	if _, err := q.Create(ctx, m); err != nil {
+		var violation *core.ValidationError
+		if len(columns) == 1 && errors.As(err, &violation) {
+			for i, fieldErr := range violation.Errors {
+				if fieldErr.Rule == core.RuleUnique && !{{.Model.Name | FirstLower}}UniqueColumns[fieldErr.Field] {
+					violation.Errors[i].Field = columns[0]
+				}
+			}
+		}
+		return nil, err
+	}
+	return m, nil
+}
+{{- end}}
+
+func (q *{{.Model.Name}}QueryBuilder) UpdateMany(ctx context.Context, records []*{{.Model.Name}}) (int64, error) {
+	db := q.database()
+	if db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+	if core.IsReadOnly(ctx) {
+		return 0, core.ErrReadOnly
+	}
+	for _, m := range records {
+		if m.IsNew() {
+			return 0, fmt.Errorf("cannot update {{.Model.Name}} that has not been saved")
+		}
+		if err := m.Validate(); err != nil {
+			return 0, err
+		}
+{{- if .TenantField}}
+		if err := m.checkTenant(ctx); err != nil {
+			return 0, err
+		}
+{{- end}}
+	}
+
+	var affected int64
+	err := db.WithTransaction(ctx, func(ctx context.Context, tx *core.Tx) error {
+{{- if .HasTimestamps}}
+		now := time.Now()
+{{- end}}
+		for _, m := range records {
+			columns := m.changedColumns()
+			if len(columns) == 0 {
+				continue
+			}
+{{- if .HasTimestamps}}
+			m.UpdatedAt = now
+{{- end}}
+			n, err := m.updateColumns(ctx, db, columns)
+			if err != nil {
+				return err
+			}
+			affected += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+{{- with .PrimaryField}}
+
+func (q *{{$.Model.Name}}QueryBuilder) FindById(ctx context.Context, id {{call $.GoType .Type}}) (*{{$.Model.Name}}, error) {
+	result, err := q.Find().Where("{{.Name | Column}}", "=", id).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*{{$.Model.Name}}), nil
+}
+{{- end}}
+
+{{- range .Model.Fields}}{{if .Unique}}{{if not .Array}}
+
+func (q *{{$.Model.Name}}QueryBuilder) FindBy{{.Name | ToPascalCase}}(ctx context.Context, value {{call $.GoType .Type}}) (*{{$.Model.Name}}, error) {
+	result, err := q.Find().Where("{{.Name | Column}}", "=", value).First(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, core.ErrNotFound
+		}
+		return nil, err
+	}
+	return result.(*{{$.Model.Name}}), nil
+}
+
+func (q *{{$.Model.Name}}QueryBuilder) ExistsBy{{.Name | ToPascalCase}}(ctx context.Context, value {{call $.GoType .Type}}) (bool, error) {
+	return q.Find().Where("{{.Name | Column}}", "=", value).Exists(ctx)
+}
+{{- end}}{{end}}{{end}}
+
+{{- with .PrimaryField}}
+
+func (q *{{$.Model.Name}}QueryBuilder) LoadByIds(ctx context.Context, ids []{{call $.GoType .Type}}) (map[{{call $.GoType .Type}}]*{{$.Model.Name}}, error) {
+	byId := make(map[{{call $.GoType .Type}}]*{{$.Model.Name}}, len(ids))
+	if len(ids) == 0 {
+		return byId, nil
+	}
+
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+
+	results, err := q.Find().WhereIn("{{.Name | Column}}", values).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		m := result.(*{{$.Model.Name}})
+		byId[m.{{.Name}}] = m
+	}
+	return byId, nil
+}
+
+func (q *{{$.Model.Name}}QueryBuilder) FindByIds(ctx context.Context, ids []{{call $.GoType .Type}}) ([]*{{$.Model.Name}}, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	byId, err := q.LoadByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*{{$.Model.Name}}, 0, len(byId))
+	for _, id := range ids {
+		if m, ok := byId[id]; ok {
+			models = append(models, m)
+			delete(byId, id)
+		}
+	}
+	return models, nil
+}
+
+func (q *{{$.Model.Name}}QueryBuilder) DeleteByIds(ctx context.Context, ids []{{call $.GoType .Type}}) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	db := q.database()
+	if db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	query := "DELETE FROM {{$.Model.TableName}} WHERE {{.Name | Column}} IN (" + core.BuildPlaceholders(len(ids)) + ")"
+{{- with $.TenantField}}
+
+	tenant, err := {{$.Model.Name | FirstLower}}Tenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+	query += " AND {{.Name | Column}} = ?"
+	args = append(args, tenant)
+{{- end}}
+	result, err := db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+{{- end}}
+
+func (q *{{.Model.Name}}QueryBuilder) FindWhere(ctx context.Context, conditions *{{.Model.Name}}) ([]*{{.Model.Name}}, error) {
+	query := q.Find()
+	if conditions != nil {
+{{- range .Model.Fields}}{{if not .Array}}
+{{- if .Optional}}
+		if conditions.{{.Name}} != nil {
+			query = query.Where("{{.Name | Column}}", "=", *conditions.{{.Name}})
+		}
+{{- else}}
+		if !core.IsZeroValue(conditions.{{.Name}}) {
+			query = query.Where("{{.Name | Column}}", "=", conditions.{{.Name}})
+		}
+{{- end}}
+{{- end}}{{end}}
+	}
+
+	results, err := query.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*{{.Model.Name}}, len(results))
+	for i, result := range results {
+		models[i] = result.(*{{.Model.Name}})
+	}
+	return models, nil
+}
+
+{{if .SearchColumns -}}
+func (q *{{.Model.Name}}QueryBuilder) Search(ctx context.Context, term string) ([]*{{.Model.Name}}, error) {
+	pattern := "%" + core.EscapeLike(term) + "%"
+	results, err := q.Find().
+		WhereRaw("{{range $i, $column := .SearchColumns}}{{if $i}} OR {{end}}LOWER({{$column}}) LIKE LOWER(?) ESCAPE '!'{{end}}"{{range .SearchColumns}}, pattern{{end}}).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*{{.Model.Name}}, len(results))
+	for i, result := range results {
+		models[i] = result.(*{{.Model.Name}})
+	}
+	return models, nil
+}
+
+{{end -}}
+func (q *{{.Model.Name}}QueryBuilder) Sync(ctx context.Context, records []*{{.Model.Name}}, keyColumn string, deleteMissing bool) (core.SyncResult, error) {
+	var result core.SyncResult
+
+	db := q.database()
+	if db == nil {
+		return result, fmt.Errorf("database not initialized")
+	}
+
+	switch keyColumn {
+{{- if .KeyColumns}}
+	case {{range $i, $column := .KeyColumns}}{{if $i}}, {{end}}"{{$column}}"{{end}}:
+{{- end}}
+	default:
+		return result, fmt.Errorf("cannot sync {{.Model.Name}} on '%s', key must be the primary key or a required unique column", keyColumn)
+	}
+	if deleteMissing && len(records) == 0 {
+		return result, fmt.Errorf("refusing to sync {{.Model.Name}} with no records and deleteMissing set")
+	}
+
+	keys := make([]interface{}, 0, len(records))
+	byKey := make(map[interface{}]*{{.Model.Name}}, len(records))
+	for _, r := range records {
+		key, _ := r.columnValue(keyColumn)
+		if _, ok := byKey[key]; ok {
+			return result, fmt.Errorf("duplicate {{.Model.Name}} %s %v in sync input", keyColumn, key)
+		}
+		byKey[key] = r
+		keys = append(keys, key)
+	}
+
+	err := db.WithTransaction(ctx, func(ctx context.Context, tx *core.Tx) error {
+		existing := make(map[interface{}]*{{.Model.Name}})
+		if len(keys) > 0 {
+			rows, err := q.Find().WhereIn(keyColumn, keys).All(ctx)
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				current := row.(*{{.Model.Name}})
+				key, _ := current.columnValue(keyColumn)
+				existing[key] = current
+			}
+		}
+
+		for _, key := range keys {
+			r := byKey[key]
+			current, ok := existing[key]
+			if !ok {
+				r.isNew = true
+				if err := r.save(ctx, db, nil); err != nil {
+					return err
+				}
+				result.Inserted++
+				continue
+			}
+
+{{- range .Model.Fields}}{{if .Primary}}
+			r.{{.Name}} = current.{{.Name}}
+{{- end}}{{end}}
+{{- if .HasTimestamps}}
+			r.CreatedAt = current.CreatedAt
+			r.UpdatedAt = current.UpdatedAt
+{{- end}}
+			r.isNew = false
+			r.original = current.original
+			r.dirty = nil
+			if len(r.changedColumns()) == 0 {
+				continue
+			}
+			if err := r.save(ctx, db, nil); err != nil {
+				return err
+			}
+			result.Updated++
+		}
+
+		if !deleteMissing {
+			return nil
+		}
+
+		var conditions []string
+		var args []interface{}
+		if len(keys) > 0 {
+			conditions = append(conditions, keyColumn+" NOT IN ("+core.BuildPlaceholders(len(keys))+")")
+			args = append(args, keys...)
+		}
+{{- with .TenantField}}
+		tenant, err := {{$.Model.Name | FirstLower}}Tenant(ctx)
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, "{{.Name | Column}} = ?")
+		args = append(args, tenant)
+{{- end}}
+
+		query := "DELETE FROM {{.Model.TableName}}"
+		if len(conditions) > 0 {
+			query += " WHERE " + strings.Join(conditions, " AND ")
+		}
+		deleted, err := db.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		result.Deleted, err = deleted.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return core.SyncResult{}, err
+	}
+
+	return result, nil
+}
+
+func (q *{{.Model.Name}}QueryBuilder) Raw(query string, args ...interface{}) core.QueryBuilder {
+	return core.NewQueryExecutorOn(q.db, "{{.Model.TableName}}", "{{.Model.Name}}", scan{{.Model.Name}})
+}
+
+func (m *{{.Model.Name}}) ScanDest() []interface{} {
+	return []interface{}{
+{{- range .Model.Fields}}
+		{{call $.ScanDest .}},
+{{- end}}
+{{- if .HasTimestamps}}
+		&m.CreatedAt,
+		&m.UpdatedAt,
+{{- end}}
+	}
+}
+
+func (m *{{.Model.Name}}) AfterScan() {
+	m.normalizeTimes()
+	m.snapshot()
+}
+
+func (m *{{.Model.Name}}) SetRelation(name string, value interface{}) {
+	switch strings.ToLower(name) {
+{{- range .Relations}}
+	case "{{.Name | ToLower}}":
+{{- if .Many}}
+		items, _ := value.([]interface{})
+		m.{{.Name}} = make([]*{{.Model}}, 0, len(items))
+		for _, item := range items {
+			if related, ok := item.(*{{.Model}}); ok {
+				m.{{.Name}} = append(m.{{.Name}}, related)
+			}
+		}
+{{- else}}
+		m.{{.Name}}, _ = value.(*{{.Model}})
+{{- end}}
+{{- end}}
+	}
+}
+
+func scan{{.Model.Name}}(rows *sql.Rows) (interface{}, error) {
+	return Scan{{.Model.Name}}Rows(rows)
+}
+
+func Scan{{.Model.Name}}Rows(rows *sql.Rows) (*{{.Model.Name}}, error) {
+	m := &{{.Model.Name}}{}
+	if err := rows.Scan(m.ScanDest()...); err != nil {
+		return nil, err
+	}
+	m.AfterScan()
+	return m, nil
+}
+
+func Scan{{.Model.Name}}Row(row *sql.Row) (*{{.Model.Name}}, error) {
+	m := &{{.Model.Name}}{}
+	if err := row.Scan(m.ScanDest()...); err != nil {
+		return nil, err
+	}
+	m.AfterScan()
+	return m, nil
+}
+`
+
+const dbTemplate = `package {{.PackageName}}
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+	"github.com/nitrix4ly/comet/drivers"
+)
+
+func InitDB(driverName, dsn string) error {
+	return InitDBWithOptions(driverName, dsn, core.DBOptions{})
+}
+
+func InitDBWithOptions(driverName, dsn string, options core.DBOptions) error {
+	var driver core.Driver
+	
+	switch driverName {
+	case "postgres":
+		driver = &drivers.PostgresDriver{}
+	case "mysql":
+		driver = &drivers.MySQLDriver{}
+	case "sqlite":
+		driver = &drivers.SQLiteDriver{}
+	default:
+		driver = &drivers.SQLiteDriver{}
+	}
+	
+	db, err := core.NewDBWithOptions(driver, dsn, options)
+	if err != nil {
+		return err
+	}
+	
+	core.SetDB(db)
+	return nil
+}
+
+func Close() error {
+	db := core.GetDB()
+	if db == nil {
+		return nil
+	}
+	core.SetDB(nil)
+	return db.Close()
+}
+
+func Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db := core.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return db.Exec(ctx, query, args...)
+}
+
+func Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db := core.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return db.Query(ctx, query, args...)
+}
+
+func Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	db := core.GetDB()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	return db.WithTransaction(ctx, func(ctx context.Context, tx *core.Tx) error {
+		return fn(ctx)
+	})
 }
 `
 
@@ -0,0 +1,114 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const enumSchema = `
+enum Role {
+  USER
+  ADMIN
+}
+
+model Member {
+  Id     Int    @id @auto
+  Name   String
+  Role   Role   @default(USER)
+  Backup Role?
+}
+`
+
+func TestInvalidEnumFailsValidation(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), enumSchema, `package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	owner := models.Role("OWNER")
+	member := &models.Member{Name: "ann", Role: models.Role("root"), Backup: &owner}
+	err := member.Save(ctx)
+
+	var verr *core.ValidationError
+	fmt.Println(errors.As(err, &verr), member.IsNew())
+	for _, fieldErr := range verr.Errors {
+		fmt.Println(fieldErr.Field, fieldErr.Rule, fieldErr.Message)
+	}
+
+	count, err := models.MemberQuery.Find().Count(ctx)
+	must(err)
+	fmt.Println(count)
+
+	member.SetRole(models.RoleAdmin)
+	member.Backup = nil
+	must(member.Save(ctx))
+	fmt.Println(member.Id, models.RoleValues())
+}
+`)
+
+	want := strings.Join([]string{
+		"true true",
+		`role enum invalid Role "root"`,
+		`backup enum invalid Role "OWNER"`,
+		"0",
+		"1 [USER ADMIN]",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
+
+func TestEnumValuerScannerRoundTrip(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), enumSchema, `package main
+
+import (
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+	db := core.GetDB().SQL()
+
+	_, err := db.ExecContext(ctx, "INSERT INTO members (name, role, backup, created_at, updated_at) VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)", "ann", models.RoleAdmin, nil)
+	must(err)
+
+	var role models.Role
+	var backup *models.Role
+	must(db.QueryRowContext(ctx, "SELECT role, backup FROM members WHERE role = ?", models.RoleAdmin).Scan(&role, &backup))
+	fmt.Println(role, role == models.RoleAdmin, backup == nil)
+
+	err = db.QueryRowContext(ctx, "SELECT backup FROM members").Scan(&role)
+	fmt.Println(err)
+	err = db.QueryRowContext(ctx, "SELECT 42").Scan(&role)
+	fmt.Println(err != nil)
+
+	var raw []byte
+	must(db.QueryRowContext(ctx, "SELECT CAST(role AS BLOB) FROM members").Scan(&raw))
+	must(role.Scan(raw))
+	fmt.Println(role)
+}
+`)
+
+	want := strings.Join([]string{
+		"ADMIN true true",
+		`sql: Scan error on column index 0, name "backup": cannot scan NULL into Role`,
+		"true",
+		"ADMIN",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
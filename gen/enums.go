@@ -0,0 +1,87 @@
+package gen
+
+import (
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+func (g *Generator) generateEnums(enums []core.EnumSchema, outputDir string) error {
+	filename := filepath.Join(outputDir, "enums.go")
+
+	tmpl := template.Must(template.New("enums").Parse(enumsTemplate))
+
+	data := struct {
+		PackageName string
+		Enums       []core.EnumSchema
+		ConstName   func(enum, value string) string
+	}{
+		PackageName: "models",
+		Enums:       enums,
+		ConstName:   enumConstName,
+	}
+
+	return g.render(filename, tmpl, data)
+}
+
+func enumConstName(enum, value string) string {
+	return enum + core.ToPascalCase(strings.ToLower(value))
+}
+
+const enumsTemplate = `package {{.PackageName}}
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+{{range .Enums}}
+type {{.Name}} string
+
+const (
+{{- $enum := .Name}}
+{{- range .Values}}
+	{{call $.ConstName $enum .}} {{$enum}} = "{{.}}"
+{{- end}}
+)
+
+func {{.Name}}Values() []{{.Name}} {
+	return []{{.Name}}{
+{{- range .Values}}
+		{{call $.ConstName $enum .}},
+{{- end}}
+	}
+}
+
+func (e {{.Name}}) IsValid() bool {
+	switch e {
+	case {{range $i, $value := .Values}}{{if $i}}, {{end}}{{call $.ConstName $enum $value}}{{end}}:
+		return true
+	}
+	return false
+}
+
+func (e {{.Name}}) String() string {
+	return string(e)
+}
+
+func (e {{.Name}}) Value() (driver.Value, error) {
+	return string(e), nil
+}
+
+func (e *{{.Name}}) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		*e = {{.Name}}(v)
+	case []byte:
+		*e = {{.Name}}(v)
+	case nil:
+		return fmt.Errorf("cannot scan NULL into {{.Name}}")
+	default:
+		return fmt.Errorf("cannot scan %T into {{.Name}}", src)
+	}
+	return nil
+}
+{{end -}}
+`
@@ -0,0 +1,58 @@
+package gen
+
+import "testing"
+
+const explicitIdSchema = `
+model Import {
+  Id   Int    @id @auto
+  Name String
+}
+
+model Code {
+  Slug  String @id
+  Label String
+}
+`
+
+func TestInsertWithExplicitPrimaryKey(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), explicitIdSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	imported, err := models.ImportQuery.CreateWithId(ctx, 42, &models.Import{Name: "imported"})
+	must(err)
+	fmt.Println(imported.Id, imported.IsNew())
+
+	kept, err := models.ImportQuery.Create(ctx, &models.Import{Id: 7, Name: "kept"})
+	must(err)
+
+	generated, err := models.ImportQuery.Create(ctx, &models.Import{Name: "generated"})
+	must(err)
+	fmt.Println(kept.Id, generated.Id)
+
+	for _, id := range []int{7, 42} {
+		found, err := models.ImportQuery.FindById(ctx, id)
+		must(err)
+		fmt.Println(found.Id, found.Name)
+	}
+
+	_, err = models.CodeQuery.Create(ctx, &models.Code{Slug: "welcome", Label: "Welcome"})
+	must(err)
+	code, err := models.CodeQuery.FindById(ctx, "welcome")
+	must(err)
+	fmt.Println(code.Slug, code.Label)
+}
+`)
+
+	want := "42 false\n7 43\n7 kept\n42 imported\nwelcome Welcome"
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
@@ -0,0 +1,202 @@
+package gen
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+func (g *Generator) generateFactory(model core.ModelSchema, outputDir string) error {
+	filename := filepath.Join(outputDir, strings.ToLower(model.Name)+"_factory.go")
+
+	tmpl := template.Must(template.New("factory").Funcs(templateFuncs).Parse(factoryTemplate))
+
+	var fields []core.FieldSchema
+	imports := map[string]bool{}
+	needsSeq := false
+
+	for _, field := range model.Fields {
+		if field.Primary || field.Optional || field.Computed != "" || field.GoType != "" {
+			continue
+		}
+		fields = append(fields, field)
+
+		value := g.factoryValue(field)
+		if strings.Contains(value, "seq") {
+			needsSeq = true
+		}
+		for _, pkg := range []string{"fmt", "rand", "time"} {
+			if strings.Contains(value, pkg+".") {
+				imports[pkg] = true
+			}
+		}
+	}
+
+	var importList []string
+	if imports["fmt"] {
+		importList = append(importList, "fmt")
+	}
+	if imports["rand"] {
+		importList = append(importList, "math/rand")
+	}
+	if needsSeq {
+		importList = append(importList, "sync/atomic")
+	}
+	if imports["time"] {
+		importList = append(importList, "time")
+	}
+
+	data := struct {
+		Model        core.ModelSchema
+		PackageName  string
+		Imports      []string
+		NeedsSeq     bool
+		Fields       []core.FieldSchema
+		FactoryValue func(core.FieldSchema) string
+	}{
+		Model:        model,
+		PackageName:  "models",
+		Imports:      importList,
+		NeedsSeq:     needsSeq,
+		Fields:       fields,
+		FactoryValue: g.factoryValue,
+	}
+
+	return g.render(filename, tmpl, data)
+}
+
+func (g *Generator) generateFactoryHelpers(outputDir string) error {
+	filename := filepath.Join(outputDir, "factory.go")
+
+	tmpl := template.Must(template.New("factoryHelpers").Parse(factoryHelpersTemplate))
+
+	data := struct {
+		PackageName string
+	}{
+		PackageName: "models",
+	}
+
+	return g.render(filename, tmpl, data)
+}
+
+func (g *Generator) factoryValue(field core.FieldSchema) string {
+	if field.Array {
+		return "nil"
+	}
+
+	if field.Enum {
+		if value, ok := field.Default.(string); ok && value != "" {
+			return enumConstName(field.Type, value)
+		}
+		return enumConstName(field.Type, g.enums[field.Type][0])
+	}
+
+	if value, ok := field.Default.(bool); ok {
+		return fmt.Sprintf("%t", value)
+	}
+
+	column := core.ToSnakeCase(field.Name)
+
+	switch g.getGoType(field.Type) {
+	case "int":
+		if field.Unique {
+			return "int(seq)"
+		}
+		return "rand.Intn(1000)"
+	case "bool":
+		return "false"
+	case "float64":
+		return "rand.Float64() * 1000"
+	case "time.Time":
+		return "time.Now()"
+	case "[]byte":
+		return "[]byte(randomString(16))"
+	default:
+		if field.Unique {
+			return fmt.Sprintf(`fmt.Sprintf("%s-%%d-%%s", seq, randomString(8))`, column)
+		}
+		return "randomString(12)"
+	}
+}
+
+const factoryTemplate = `package {{.PackageName}}
+
+import (
+	"context"
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+{{if .NeedsSeq}}
+var {{.Model.Name | FirstLower}}FactorySeq int64
+{{end}}
+type {{.Model.Name}}Factory struct {
+	overrides []func(*{{.Model.Name}})
+}
+
+func New{{.Model.Name}}Factory() *{{.Model.Name}}Factory {
+	return &{{.Model.Name}}Factory{}
+}
+
+func (f *{{.Model.Name}}Factory) With(override func(*{{.Model.Name}})) *{{.Model.Name}}Factory {
+	f.overrides = append(f.overrides, override)
+	return f
+}
+
+func (f *{{.Model.Name}}Factory) Build() *{{.Model.Name}} {
+{{- if .NeedsSeq}}
+	seq := atomic.AddInt64(&{{.Model.Name | FirstLower}}FactorySeq, 1)
+{{end}}
+	m := &{{.Model.Name}}{
+{{- range .Fields}}
+		{{.Name}}: {{call $.FactoryValue .}},
+{{- end}}
+	}
+
+	for _, override := range f.overrides {
+		override(m)
+	}
+
+	return m
+}
+
+func (f *{{.Model.Name}}Factory) Create(ctx context.Context) (*{{.Model.Name}}, error) {
+	m := f.Build()
+	if err := m.Save(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (f *{{.Model.Name}}Factory) CreateMany(ctx context.Context, count int) ([]*{{.Model.Name}}, error) {
+	models := make([]*{{.Model.Name}}, 0, count)
+	for i := 0; i < count; i++ {
+		m, err := f.Create(ctx)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, m)
+	}
+	return models, nil
+}
+`
+
+const factoryHelpersTemplate = `package {{.PackageName}}
+
+import (
+	"math/rand"
+)
+
+const factoryAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = factoryAlphabet[rand.Intn(len(factoryAlphabet))]
+	}
+	return string(b)
+}
+`
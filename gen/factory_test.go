@@ -0,0 +1,94 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const factorySchema = `
+model User {
+  Id    Int     @id @auto
+  Email String  @unique
+  Name  String
+  Bio   String?
+  Age   Int
+  Posts Post[]  @relation("UserPosts")
+}
+
+model Post {
+  Id       Int    @id @auto
+  Title    String
+  AuthorId Int
+  Author   User   @relation("UserPosts", fields: [AuthorId], references: [Id])
+}
+`
+
+func TestFactoryFields(t *testing.T) {
+	dir := generate(t, NewGenerator(), factorySchema)
+
+	factory := readGenerated(t, dir, "user_factory.go")
+	for _, want := range []string{
+		"func NewUserFactory() *UserFactory",
+		`Email: fmt.Sprintf("email-%d-%s", seq, randomString(8)),`,
+		"Name:  randomString(12),",
+		"Age:   rand.Intn(1000),",
+	} {
+		if !strings.Contains(factory, want) {
+			t.Errorf("user_factory.go does not contain %s", want)
+		}
+	}
+	for _, unwanted := range []string{"Id:", "Bio:"} {
+		if strings.Contains(factory, unwanted) {
+			t.Errorf("user_factory.go sets %s", unwanted)
+		}
+	}
+}
+
+func TestFactoryExample(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), factorySchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	users, err := models.NewUserFactory().CreateMany(ctx, 3)
+	must(err)
+	emails := make(map[string]bool)
+	for _, u := range users {
+		emails[u.Email] = true
+	}
+	fmt.Println(len(users), len(emails), users[0].Id > 0, users[0].Bio == nil)
+
+	admin, err := models.NewUserFactory().
+		With(func(u *models.User) { u.Name = "Admin" }).
+		Create(ctx)
+	must(err)
+
+	draft := models.NewPostFactory().
+		With(func(p *models.Post) { p.AuthorId = admin.Id }).
+		Build()
+	fmt.Println(draft.IsNew(), draft.Title != "")
+	must(draft.Save(ctx))
+
+	count, err := models.UserQuery.Find().Count(ctx)
+	must(err)
+	posts, err := admin.PostsCount(ctx)
+	must(err)
+	fmt.Println(admin.Name, count, posts)
+}
+`)
+
+	want := strings.Join([]string{
+		"3 3 true true",
+		"true true",
+		"Admin 4 1",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
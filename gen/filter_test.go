@@ -0,0 +1,69 @@
+package gen
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const filterSchema = `
+model User {
+  Id    Int    @id @auto
+  Name  String
+  Posts Post[] @relation("UserPosts")
+}
+
+model Post {
+  Id     Int    @id @auto
+  Title  String
+  UserId Int
+  User   User   @relation("UserPosts", fields: [UserId], references: [Id])
+}
+
+model Invoice {
+  Id    Int @id @auto
+  Total Int
+}
+`
+
+func TestModelFilterGeneratesOnlyRequestedModels(t *testing.T) {
+	for _, tc := range []struct {
+		only []string
+		want []string
+	}{
+		{[]string{"Post"}, []string{"post.go", "post_factory.go", "user.go", "user_factory.go"}},
+		{[]string{"Invoice"}, []string{"invoice.go", "invoice_factory.go"}},
+		{nil, []string{"invoice.go", "invoice_factory.go", "post.go", "post_factory.go", "user.go", "user_factory.go"}},
+	} {
+		g := NewGenerator()
+		g.SetModelFilter(tc.only)
+		dir := t.TempDir()
+		if err := g.GenerateFromFiles([]string{writeSchema(t, t.TempDir(), filterSchema)}, dir); err != nil {
+			t.Fatal(err)
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var files []string
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), ".go") {
+				files = append(files, entry.Name())
+			}
+		}
+		if !reflect.DeepEqual(files, tc.want) {
+			t.Errorf("models %v generated %v, want %v", tc.only, files, tc.want)
+		}
+	}
+}
+
+func TestModelFilterUnknownModel(t *testing.T) {
+	g := NewGenerator()
+	g.SetModelFilter([]string{"Post", "Comment"})
+	err := g.GenerateFromFiles([]string{writeSchema(t, t.TempDir(), filterSchema)}, t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "unknown model 'Comment'") {
+		t.Errorf("err = %v, want unknown model 'Comment'", err)
+	}
+}
@@ -0,0 +1,66 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const findWhereSchema = `
+model Person {
+  Id       Int     @id @auto
+  Name     String
+  Active   Boolean
+  Age      Int
+  Nickname String?
+}
+`
+
+func TestFindWhereZeroAndSetFields(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), findWhereSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func names(people []*models.Person, err error) string {
+	must(err)
+	var names []string
+	for _, person := range people {
+		names = append(names, person.Name)
+	}
+	return fmt.Sprint(names)
+}
+
+func main() {
+	ctx := setup()
+
+	empty, cy := "", "cy"
+	for _, person := range []*models.Person{
+		{Name: "ann", Active: true, Age: 30, Nickname: &empty},
+		{Name: "bob", Active: false, Age: 0},
+		{Name: "cyrus", Active: true, Age: 30, Nickname: &cy},
+	} {
+		must(person.Save(ctx))
+	}
+
+	fmt.Println(names(models.PersonQuery.FindWhere(ctx, &models.Person{Age: 30})))
+	fmt.Println(names(models.PersonQuery.FindWhere(ctx, &models.Person{Age: 30, Name: "cyrus"})))
+	fmt.Println(names(models.PersonQuery.FindWhere(ctx, &models.Person{Active: false})))
+	fmt.Println(names(models.PersonQuery.FindWhere(ctx, &models.Person{Nickname: &empty})))
+	fmt.Println(names(models.PersonQuery.FindWhere(ctx, nil)))
+}
+`)
+
+	want := strings.Join([]string{
+		"[ann cyrus]",
+		"[cyrus]",
+		"[ann bob cyrus]",
+		"[ann]",
+		"[ann bob cyrus]",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
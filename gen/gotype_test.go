@@ -0,0 +1,86 @@
+package gen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCustomGoTypeGeneration(t *testing.T) {
+	contact := readGenerated(t, generate(t, NewGenerator(), `
+model Contact {
+  Id     Int     @id @auto
+  Email  String  @unique @gotype("github.com/me/types.Email")
+  Backup String? @gotype("github.com/me/types.Email")
+  Phone  String  @gotype("gopkg.in/phone.v2.Number")
+}
+`), "contact.go")
+
+	for _, want := range []string{
+		"\t\"github.com/me/types\"\n",
+		"\t\"gopkg.in/phone.v2\"\n",
+		"func (m *Contact) SetEmail(value types.Email)",
+	} {
+		if !strings.Contains(contact, want) {
+			t.Errorf("contact.go does not contain %q", want)
+		}
+	}
+	for _, field := range []string{`Email\s+types\.Email\s`, `Backup\s+\*types\.Email\s`, `Phone\s+phone\.Number\s`} {
+		if !regexp.MustCompile(field).MatchString(contact) {
+			t.Errorf("contact.go has no field matching %s", field)
+		}
+	}
+	if strings.Count(contact, "\"github.com/me/types\"") != 1 {
+		t.Error("contact.go imports github.com/me/types more than once")
+	}
+}
+
+func TestCustomGoTypeRoundTrip(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), `
+model Job {
+  Id      Int  @id @auto
+  Timeout Int  @gotype("time.Duration")
+  Retry   Int? @gotype("time.Duration")
+}
+`, `package main
+
+import (
+	"fmt"
+	"time"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	job, err := models.JobQuery.Create(ctx, &models.Job{Timeout: 90 * time.Second})
+	must(err)
+	loaded, err := models.JobQuery.FindById(ctx, job.Id)
+	must(err)
+	fmt.Println(loaded.Timeout, loaded.Retry == nil)
+}
+`)
+
+	if output != "1m30s true" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestParseInvalidCustomGoType(t *testing.T) {
+	for field, want := range map[string]string{
+		`Email String @gotype("Email")`:                   `invalid Go type 'Email'`,
+		`Code String @id @gotype("example.com/ids.Code")`: "@gotype cannot be combined with @id",
+		`Tags String[] @gotype("example.com/tags.List")`:  "@gotype cannot be combined with @id, @tenant, @computed or array fields",
+	} {
+		_, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), `
+model Contact {
+  Id Int @id @auto
+  `+field+`
+}
+`)})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %q", field, err, want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package gen
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nitrix4ly/comet/core"
+	"github.com/nitrix4ly/comet/drivers"
+)
+
+func writeSchema(t *testing.T, dir, schema string) string {
+	t.Helper()
+	file := filepath.Join(dir, "schema.cmt")
+	if err := os.WriteFile(file, []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func generate(t *testing.T, g *Generator, schema string) string {
+	t.Helper()
+	dir := t.TempDir()
+	out := filepath.Join(dir, "models")
+	if err := os.MkdirAll(out, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.GenerateFromFiles([]string{writeSchema(t, dir, schema)}, out); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateHelpers(out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func readGenerated(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+type ddlDriver interface {
+	MigrationStatements(change core.SchemaChange) ([]string, error)
+}
+
+var testDrivers = map[string]struct {
+	driver string
+	dsnEnv string
+	ddl    ddlDriver
+}{
+	"sqlite":   {"&drivers.SQLiteDriver{}", "", &drivers.SQLiteDriver{}},
+	"postgres": {"&drivers.PostgresDriver{}", "COMET_TEST_POSTGRES_URL", &drivers.PostgresDriver{}},
+	"mysql":    {"&drivers.MySQLDriver{}", "COMET_TEST_MYSQL_URL", &drivers.MySQLDriver{}},
+}
+
+func schemaStatements(t *testing.T, schema *core.Schema) []string {
+	t.Helper()
+	return dialectStatements(t, "sqlite", schema)
+}
+
+func dialectStatements(t *testing.T, provider string, schema *core.Schema) []string {
+	t.Helper()
+	driver := testDrivers[provider].ddl
+
+	var statements []string
+	for _, change := range core.DiffSchemas(nil, schema) {
+		changeStatements, err := driver.MigrationStatements(change)
+		if err != nil {
+			t.Fatal(err)
+		}
+		statements = append(statements, changeStatements...)
+	}
+	return statements
+}
+
+const harnessSource = `package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nitrix4ly/comet/core"
+	"github.com/nitrix4ly/comet/drivers"
+
+	"gentest/models"
+)
+
+var schemaStatements = %#v
+
+var testDir = %q
+
+func dsn(name string) string {
+	if url := %q; url != "" {
+		return url
+	}
+	return filepath.Join(testDir, name+".db")
+}
+
+func openDB(name string) *core.DB {
+	db, err := core.NewDB(%s, dsn(name))
+	must(err)
+	for _, statement := range schemaStatements {
+		_, err := db.Exec(context.Background(), statement)
+		must(err)
+	}
+	return db
+}
+
+func setup() context.Context {
+	core.SetDB(openDB("main"))
+	return context.Background()
+}
+
+func must(err error) {
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+}
+
+var _ = models.Close
+`
+
+// runGenerated generates models for schema into a throwaway module, creates
+// the tables on a fresh SQLite database and runs program (a main package
+// that calls setup) against them, returning its output.
+func runGenerated(t *testing.T, g *Generator, schema, program string) string {
+	t.Helper()
+	return runGeneratedOn(t, "sqlite", g, schema, program)
+}
+
+// runGeneratedOn is runGenerated against another provider. Postgres and MySQL
+// need a database URL in COMET_TEST_POSTGRES_URL or COMET_TEST_MYSQL_URL;
+// the schema's tables are dropped and recreated there.
+func runGeneratedOn(t *testing.T, provider string, g *Generator, schema, program string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds a generated module")
+	}
+
+	target := testDrivers[provider]
+	url := ""
+	if target.dsnEnv != "" {
+		if url = os.Getenv(target.dsnEnv); url == "" {
+			t.Skipf("%s is not set", target.dsnEnv)
+		}
+	}
+
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "models")
+	if err := os.MkdirAll(out, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateFromFiles([]string{writeSchema(t, t.TempDir(), schema)}, out); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateHelpers(out); err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var statements []string
+	if url != "" {
+		tables := append(append([]core.ModelSchema(nil), g.Schema().Models...), g.Schema().JoinTables()...)
+		for _, model := range tables {
+			drop := "DROP TABLE IF EXISTS " + model.TableName
+			if provider == "postgres" {
+				drop += " CASCADE"
+			}
+			statements = append(statements, drop)
+		}
+	}
+	statements = append(statements, dialectStatements(t, provider, g.Schema())...)
+
+	files := map[string]string{
+		"go.mod":     fmt.Sprintf("module gentest\n\ngo 1.21\n\nrequire github.com/nitrix4ly/comet v0.0.0\n\nreplace github.com/nitrix4ly/comet => %s\n", root),
+		"go.sum":     string(sum),
+		"harness.go": fmt.Sprintf(harnessSource, statements, dir, url, target.driver),
+		"main.go":    program,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated program failed: %v\n%s", err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
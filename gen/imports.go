@@ -0,0 +1,58 @@
+package gen
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+)
+
+func pruneImports(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	var unused []*ast.ImportSpec
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name != "_" && !used[name] {
+			unused = append(unused, spec)
+		}
+	}
+
+	for i := len(unused) - 1; i >= 0; i-- {
+		start := fset.Position(unused[i].Pos()).Offset
+		end := fset.Position(unused[i].End()).Offset
+
+		lineStart := bytes.LastIndexByte(src[:start], '\n') + 1
+		lineEnd := end
+		if next := bytes.IndexByte(src[end:], '\n'); next >= 0 {
+			lineEnd = end + next + 1
+		}
+		src = append(src[:lineStart:lineStart], src[lineEnd:]...)
+	}
+
+	return src, nil
+}
@@ -0,0 +1,71 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+const partialIndexSchema = `
+model Account {
+  id        Int       @id @auto
+  email     String
+  deletedAt DateTime?
+
+  @@unique([email], where: "deleted_at IS NULL")
+  @@index([deletedAt])
+}
+`
+
+func TestPartialUniqueIndexDDL(t *testing.T) {
+	g := NewGenerator()
+	generate(t, g, partialIndexSchema)
+
+	postgres := dialectStatements(t, "postgres", g.Schema())
+	for _, want := range []string{
+		"CREATE UNIQUE INDEX IF NOT EXISTS accounts_email_key ON accounts (email) WHERE deleted_at IS NULL",
+		"CREATE INDEX IF NOT EXISTS accounts_deletedAt_idx ON accounts (deleted_at)",
+	} {
+		if !containsStatement(postgres, want) {
+			t.Errorf("postgres DDL does not contain %q:\n%s", want, strings.Join(postgres, "\n"))
+		}
+	}
+
+	sqlite := dialectStatements(t, "sqlite", g.Schema())
+	if want := "CREATE UNIQUE INDEX IF NOT EXISTS accounts_email_key ON accounts (email) WHERE deleted_at IS NULL"; !containsStatement(sqlite, want) {
+		t.Errorf("sqlite DDL does not contain %q:\n%s", want, strings.Join(sqlite, "\n"))
+	}
+
+	_, err := testDrivers["mysql"].ddl.MigrationStatements(core.DiffSchemas(nil, g.Schema())[0])
+	if err == nil || !strings.Contains(err.Error(), "Account: mysql has no partial indexes and cannot enforce unique index accounts_email_key where deleted_at IS NULL") {
+		t.Errorf("mysql err = %v, want the partial unique index rejected", err)
+	}
+}
+
+func TestPartialPlainIndexSkippedOnMySQL(t *testing.T) {
+	g := NewGenerator()
+	generate(t, g, `
+model Account {
+  id        Int       @id @auto
+  email     String
+  deletedAt DateTime?
+
+  @@index([email], where: "deleted_at IS NULL")
+}
+`)
+
+	mysql := strings.Join(dialectStatements(t, "mysql", g.Schema()), "\n")
+	if strings.Contains(mysql, "INDEX") || strings.Contains(mysql, "WHERE") {
+		t.Errorf("mysql DDL has a partial index:\n%s", mysql)
+	}
+}
+
+func containsStatement(statements []string, want string) bool {
+	for _, statement := range statements {
+		if statement == want {
+			return true
+		}
+	}
+	return false
+}
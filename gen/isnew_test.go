@@ -0,0 +1,92 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const keySchema = `
+model Counter {
+  Id    Int    @id @auto
+  Value Int
+}
+
+model Token {
+  Code  String @id
+  Label String
+}
+
+model Membership {
+  GroupId Int    @id
+  UserId  Int    @id
+  Role    String
+}
+`
+
+func TestIsNewGeneration(t *testing.T) {
+	dir := generate(t, NewGenerator(), keySchema)
+	for file, want := range map[string]string{
+		"counter.go":    "return m.isNew || m.Id == 0\n",
+		"token.go":      "return m.isNew || m.Code == \"\"\n",
+		"membership.go": "return m.isNew || (m.GroupId == 0 && m.UserId == 0)\n",
+	} {
+		source := readGenerated(t, dir, file)
+		if !strings.Contains(source, want) {
+			t.Errorf("%s does not contain %q", file, want)
+		}
+		if file == "membership.go" {
+			if !strings.Contains(source, "WHERE group_id = ? AND user_id = ?") {
+				t.Errorf("membership.go does not match both key columns")
+			}
+			if strings.Contains(source, "FindById(") {
+				t.Errorf("membership.go has a single-key FindById")
+			}
+		}
+	}
+}
+
+func TestSaveWithNonIntegerKeys(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), keySchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	token := &models.Token{Label: "draft"}
+	fmt.Println(token.IsNew())
+	token.Code = "abc"
+	fmt.Println(token.IsNew())
+	token, err := models.TokenQuery.Create(ctx, token)
+	must(err)
+	token.Label = "final"
+	must(token.Save(ctx))
+	found, err := models.TokenQuery.FindById(ctx, "abc")
+	must(err)
+	fmt.Println(token.IsNew(), found.Label)
+
+	membership := &models.Membership{}
+	fmt.Println(membership.IsNew())
+	membership, err = models.MembershipQuery.Create(ctx, &models.Membership{GroupId: 1, UserId: 2, Role: "member"})
+	must(err)
+	membership.Role = "owner"
+	must(membership.Save(ctx))
+	count, err := models.MembershipQuery.Find().Where("role", "=", "owner").Count(ctx)
+	must(err)
+	fmt.Println(membership.IsNew(), count)
+	must(membership.Delete(ctx))
+	count, err = models.MembershipQuery.Find().Count(ctx)
+	must(err)
+	fmt.Println(count)
+}
+`)
+
+	want := "true\nfalse\nfalse final\ntrue\nfalse 1\n0"
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
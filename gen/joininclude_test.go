@@ -0,0 +1,40 @@
+package gen
+
+import "testing"
+
+func TestJoinIncludeGenerated(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), relationsSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	ann, err := models.UserQuery.Create(ctx, &models.User{Name: "ann"})
+	must(err)
+	for _, title := range []string{"one", "two"} {
+		_, err := models.PostQuery.Create(ctx, &models.Post{Title: title, AuthorId: ann.Id})
+		must(err)
+	}
+
+	posts, err := models.PostQuery.Find().JoinInclude("Author").Where("title", "=", "two").All(ctx)
+	must(err)
+	for _, item := range posts {
+		post := item.(*models.Post)
+		fmt.Println(post.Title, post.Author != nil && post.Author.Name == "ann", post.Author.Id == ann.Id, post.Author.CreatedAt.IsZero())
+	}
+
+	_, err = models.UserQuery.Find().JoinInclude("Posts").All(ctx)
+	fmt.Println(err)
+}
+`)
+
+	want := "two true true false\nrelation 'Posts' on User cannot be loaded with a join"
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
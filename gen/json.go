@@ -0,0 +1,27 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func (g *Generator) WriteSchemaJSON(filename string) error {
+	if g.schema == nil {
+		return fmt.Errorf("no schema has been parsed")
+	}
+
+	data, err := json.MarshalIndent(g.schema, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
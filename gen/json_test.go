@@ -0,0 +1,39 @@
+package gen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+func TestSchemaJSONRoundTrip(t *testing.T) {
+	g := NewGenerator()
+	generate(t, g, relationsSchema+enumSchema)
+
+	file := filepath.Join(t.TempDir(), "out", "schema.json")
+	if err := g.WriteSchemaJSON(file); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema core.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&schema, g.Schema()) {
+		t.Errorf("round-tripped schema differs:\n got %+v\nwant %+v", schema, *g.Schema())
+	}
+}
+
+func TestSchemaJSONBeforeParsing(t *testing.T) {
+	if err := NewGenerator().WriteSchemaJSON(filepath.Join(t.TempDir(), "schema.json")); err == nil {
+		t.Error("WriteSchemaJSON succeeded without a parsed schema")
+	}
+}
@@ -0,0 +1,171 @@
+package gen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+func (g *Generator) GenerateJSONSchemas(outputDir string) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+
+	selected, err := g.selectedModels()
+	if err != nil {
+		return err
+	}
+
+	for _, model := range g.models {
+		if selected != nil && !selected[model.Name] {
+			continue
+		}
+
+		data, err := json.MarshalIndent(g.jsonSchema(model), "", "  ")
+		if err != nil {
+			return err
+		}
+
+		filename := filepath.Join(outputDir, jsonSchemaFile(model.Name))
+		if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jsonSchemaFile(modelName string) string {
+	return strings.ToLower(modelName) + ".schema.json"
+}
+
+func (g *Generator) jsonSchema(model core.ModelSchema) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	seen := make(map[string]bool)
+
+	add := func(name string, property map[string]interface{}, isRequired bool) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		properties[name] = property
+		if isRequired {
+			required = append(required, name)
+		}
+	}
+
+	for _, field := range model.Fields {
+		if field.Hidden {
+			continue
+		}
+
+		property := g.jsonSchemaType(field)
+		if field.Optional {
+			property["type"] = []interface{}{property["type"], "null"}
+			if enum, ok := property["enum"].([]interface{}); ok {
+				property["enum"] = append(enum, nil)
+			}
+		}
+		if field.Primary && field.AutoGen || field.Computed != "" || field.ReadOnly {
+			property["readOnly"] = true
+		}
+		if value, ok := jsonSchemaDefault(field); ok {
+			property["default"] = value
+		}
+		if field.Comment != "" {
+			property["description"] = field.Comment
+		}
+		add(g.naming.ColumnName(field.Name), property, !field.Optional)
+	}
+
+	for _, name := range []string{"createdAt", "updatedAt"} {
+		add(g.naming.ColumnName(name), map[string]interface{}{
+			"type":     "string",
+			"format":   "date-time",
+			"readOnly": true,
+		}, true)
+	}
+
+	for _, link := range g.relationLinks(model) {
+		if link.Many {
+			continue
+		}
+		add(core.ToSnakeCase(link.Name), map[string]interface{}{
+			"$ref": jsonSchemaFile(link.Model),
+		}, false)
+	}
+
+	schema := map[string]interface{}{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"$id":        jsonSchemaFile(model.Name),
+		"title":      model.Name,
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+	if model.Comment != "" {
+		schema["description"] = model.Comment
+	}
+	return schema
+}
+
+func (g *Generator) jsonSchemaType(field core.FieldSchema) map[string]interface{} {
+	var property map[string]interface{}
+	switch field.Type {
+	case "Int":
+		property = map[string]interface{}{"type": "integer"}
+	case "Float":
+		property = map[string]interface{}{"type": "number"}
+	case "Boolean":
+		property = map[string]interface{}{"type": "boolean"}
+	case "DateTime":
+		property = map[string]interface{}{"type": "string", "format": "date-time"}
+	case "Bytes":
+		property = map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+	default:
+		property = map[string]interface{}{"type": "string"}
+		if values, ok := g.enums[field.Type]; ok {
+			enum := make([]interface{}, len(values))
+			for i, value := range values {
+				enum[i] = value
+			}
+			property["enum"] = enum
+		}
+	}
+
+	if field.Array {
+		return map[string]interface{}{"type": "array", "items": property}
+	}
+	return property
+}
+
+func jsonSchemaDefault(field core.FieldSchema) (interface{}, bool) {
+	if field.Array {
+		return nil, false
+	}
+
+	switch value := field.Default.(type) {
+	case bool:
+		return value, true
+	case string:
+		if value == "CURRENT_TIMESTAMP" || strings.Contains(value, "(") {
+			return nil, false
+		}
+		switch field.Type {
+		case "Int":
+			n, err := strconv.Atoi(value)
+			return n, err == nil
+		case "Float":
+			f, err := strconv.ParseFloat(value, 64)
+			return f, err == nil
+		case "DateTime", "Bytes":
+			return nil, false
+		}
+		return value, true
+	}
+	return nil, false
+}
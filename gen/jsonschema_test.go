@@ -0,0 +1,113 @@
+package gen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readJSONSchema(t *testing.T, dir, model string) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, jsonSchemaFile(model)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestJSONSchemaForBlogModels(t *testing.T) {
+	g := NewGenerator()
+	if err := g.GenerateFromFiles([]string{filepath.Join("..", "schema", "schema.cmt")}, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := g.GenerateJSONSchemas(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, model := range []string{"User", "Post", "Category", "Tag", "Profile"} {
+		schema := readJSONSchema(t, dir, model)
+		if schema["title"] != model || schema["type"] != "object" || schema["$id"] != jsonSchemaFile(model) {
+			t.Errorf("%s schema header = %v %v %v", model, schema["$id"], schema["title"], schema["type"])
+		}
+	}
+
+	post := readJSONSchema(t, dir, "Post")
+	properties := post["properties"].(map[string]interface{})
+	for column, want := range map[string]string{
+		"id":          `{"readOnly":true,"type":"integer"}`,
+		"title":       `{"type":"string"}`,
+		"content":     `{"type":["string","null"]}`,
+		"published":   `{"default":false,"type":"boolean"}`,
+		"category_id": `{"type":["integer","null"]}`,
+		"created_at":  `{"format":"date-time","readOnly":true,"type":"string"}`,
+		"author":      `{"$ref":"user.schema.json"}`,
+	} {
+		got, err := json.Marshal(properties[column])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("post %s = %s, want %s", column, got, want)
+		}
+	}
+	if _, ok := properties["tags"]; ok {
+		t.Error("post schema includes the many-to-many tags relation")
+	}
+
+	required := make(map[string]bool)
+	for _, name := range post["required"].([]interface{}) {
+		required[name.(string)] = true
+	}
+	for _, column := range []string{"id", "title", "published", "author_id", "created_at", "updated_at"} {
+		if !required[column] {
+			t.Errorf("post schema does not require %s", column)
+		}
+	}
+	for _, column := range []string{"content", "category_id", "author"} {
+		if required[column] {
+			t.Errorf("post schema requires optional %s", column)
+		}
+	}
+
+	user := readJSONSchema(t, dir, "User")
+	userProperties := user["properties"].(map[string]interface{})
+	if age := userProperties["age"].(map[string]interface{}); age["default"] != float64(0) {
+		t.Errorf("user age default = %v, want 0", age["default"])
+	}
+}
+
+func TestJSONSchemaHiddenAndReadOnly(t *testing.T) {
+	g := NewGenerator()
+	out := generate(t, g, `
+model Account {
+  Id           Int    @id @auto
+  Email        String @readonly
+  PasswordHash String @hidden
+}
+`)
+	dir := t.TempDir()
+	if err := g.GenerateJSONSchemas(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	properties := readJSONSchema(t, dir, "Account")["properties"].(map[string]interface{})
+	if _, ok := properties["password_hash"]; ok {
+		t.Error("schema includes the @hidden password_hash")
+	}
+	if email := properties["email"]; !reflect.DeepEqual(email, map[string]interface{}{"type": "string", "readOnly": true}) {
+		t.Errorf("email = %v, want a read-only string", email)
+	}
+
+	if account := readGenerated(t, out, "account.go"); !strings.Contains(account, "`json:\"-\" db:\"password_hash\"`") {
+		t.Error("account.go does not hide PasswordHash from JSON")
+	}
+}
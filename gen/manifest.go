@@ -0,0 +1,185 @@
+package gen
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+)
+
+const (
+	manifestFile = ".comet-manifest.json"
+	customMarker = "// comet:custom"
+)
+
+type manifest struct {
+	path  string
+	files map[string]string
+}
+
+func loadManifest(dir string) (*manifest, error) {
+	m := &manifest{
+		path:  filepath.Join(dir, manifestFile),
+		files: make(map[string]string),
+	}
+
+	data, err := os.ReadFile(m.path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &m.files); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *manifest) save() error {
+	data, err := json.MarshalIndent(m.files, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.path, append(data, '\n'), 0644)
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (g *Generator) SetForce(force bool) {
+	g.force = force
+}
+
+func (g *Generator) Skipped() []string {
+	skipped := append([]string(nil), g.skipped...)
+	sort.Strings(skipped)
+	return skipped
+}
+
+func (g *Generator) render(filename string, tmpl *template.Template, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	content, err := formatSource(filename, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return g.writeFile(filename, content)
+}
+
+func (g *Generator) renderPruned(filename string, tmpl *template.Template, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	content, err := pruneImports(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s: %v", filepath.Base(filename), err)
+	}
+
+	content, err = formatSource(filename, content)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(filename, content)
+}
+
+func formatSource(filename string, src []byte) ([]byte, error) {
+	if filepath.Ext(filename) != ".go" {
+		return src, nil
+	}
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Base(filename), err)
+	}
+	return formatted, nil
+}
+
+func (g *Generator) manifestFor(dir string) (*manifest, error) {
+	if g.manifests == nil {
+		g.manifests = make(map[string]*manifest)
+	}
+	if m, ok := g.manifests[dir]; ok {
+		return m, nil
+	}
+
+	m, err := loadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	g.manifests[dir] = m
+	return m, nil
+}
+
+func (g *Generator) writeFile(filename string, content []byte) error {
+	m, err := g.manifestFor(filepath.Dir(filename))
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(filename)
+	existing, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err == nil && !g.force && !bytes.Equal(existing, content) {
+		recorded, tracked := m.files[name]
+		if bytes.Contains(existing, []byte(customMarker)) || (tracked && recorded != hashContent(existing)) {
+			g.skipped = append(g.skipped, filename)
+			return nil
+		}
+	}
+
+	if err := os.WriteFile(filename, content, 0644); err != nil {
+		return err
+	}
+
+	m.files[name] = hashContent(content)
+	return m.save()
+}
+
+func (g *Generator) removeGenerated(filename string) error {
+	m, err := g.manifestFor(filepath.Dir(filename))
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(filename)
+	recorded, tracked := m.files[name]
+	if !tracked {
+		return nil
+	}
+
+	existing, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err == nil {
+		if !g.force && (bytes.Contains(existing, []byte(customMarker)) || recorded != hashContent(existing)) {
+			g.skipped = append(g.skipped, filename)
+			return nil
+		}
+		if err := os.Remove(filename); err != nil {
+			return err
+		}
+	}
+
+	delete(m.files, name)
+	return m.save()
+}
@@ -0,0 +1,83 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const manifestSchema = `
+model Note {
+  Id   Int    @id @auto
+  Body String
+}
+`
+
+func generateInto(t *testing.T, dir, schema string, force bool) *Generator {
+	t.Helper()
+	g := NewGenerator()
+	g.SetForce(force)
+	if err := g.GenerateFromFiles([]string{writeSchema(t, t.TempDir(), schema)}, dir); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestEditedFileSkippedWithoutForce(t *testing.T) {
+	dir := t.TempDir()
+	generateInto(t, dir, manifestSchema, false)
+
+	file := filepath.Join(dir, "note.go")
+	edited := readGenerated(t, dir, "note.go") + "\nfunc (m *Note) Summary() string { return m.Body }\n"
+	if err := os.WriteFile(file, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := strings.Replace(manifestSchema, "Body String", "Body String\n  Pinned Boolean", 1)
+	g := generateInto(t, dir, changed, false)
+	if got := readGenerated(t, dir, "note.go"); got != edited {
+		t.Error("note.go was overwritten without --force")
+	}
+	if want := []string{file}; !reflect.DeepEqual(g.Skipped(), want) {
+		t.Errorf("skipped = %v, want %v", g.Skipped(), want)
+	}
+
+	g = generateInto(t, dir, changed, true)
+	if got := readGenerated(t, dir, "note.go"); !strings.Contains(got, "Pinned") || strings.Contains(got, "Summary()") {
+		t.Error("note.go was not regenerated with --force")
+	}
+	if len(g.Skipped()) != 0 {
+		t.Errorf("skipped = %v with --force", g.Skipped())
+	}
+}
+
+func TestUneditedFileRegenerated(t *testing.T) {
+	dir := t.TempDir()
+	generateInto(t, dir, manifestSchema, false)
+
+	g := generateInto(t, dir, strings.Replace(manifestSchema, "Body String", "Body String\n  Pinned Boolean", 1), false)
+	if !strings.Contains(readGenerated(t, dir, "note.go"), "Pinned") {
+		t.Error("unedited note.go was not regenerated")
+	}
+	if len(g.Skipped()) != 0 {
+		t.Errorf("skipped = %v", g.Skipped())
+	}
+}
+
+func TestCustomMarkerFileSkipped(t *testing.T) {
+	dir := t.TempDir()
+	custom := "package models\n\n" + customMarker + "\n\ntype Note struct{}\n"
+	if err := os.WriteFile(filepath.Join(dir, "note.go"), []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := generateInto(t, dir, manifestSchema, false)
+	if readGenerated(t, dir, "note.go") != custom {
+		t.Error("note.go with the custom marker was overwritten")
+	}
+	if skipped := g.Skipped(); len(skipped) != 1 || filepath.Base(skipped[0]) != "note.go" {
+		t.Errorf("skipped = %v, want note.go", skipped)
+	}
+}
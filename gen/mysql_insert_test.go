@@ -0,0 +1,79 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const mysqlInsertSchema = `
+model Setting {
+  Id    Int    @id @auto
+  Name  String @unique
+  Value String
+}
+`
+
+func TestMySQLInsertGeneration(t *testing.T) {
+	setting := readGenerated(t, generate(t, NewGenerator(), mysqlInsertSchema), "setting.go")
+	for _, want := range []string{
+		"func (q *SettingQueryBuilder) InsertIgnore(ctx context.Context, m *Setting) (bool, error) {\n\treturn m.mysqlInsert(ctx, q.database(), \"INSERT IGNORE\", core.FeatureInsertIgnore)\n}",
+		"func (q *SettingQueryBuilder) Replace(ctx context.Context, m *Setting) error {\n\t_, err := m.mysqlInsert(ctx, q.database(), \"REPLACE\", core.FeatureReplace)",
+		"if err := db.Require(feature); err != nil {",
+	} {
+		if !strings.Contains(setting, want) {
+			t.Errorf("setting.go does not contain:\n%s", want)
+		}
+	}
+}
+
+const mysqlInsertProgram = `package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	inserted, err := models.SettingQuery.InsertIgnore(ctx, &models.Setting{Name: "theme", Value: "dark"})
+	var unsupported *core.UnsupportedError
+	if errors.As(err, &unsupported) {
+		fmt.Println(err)
+		fmt.Println(models.SettingQuery.Replace(ctx, &models.Setting{Name: "theme", Value: "light"}))
+		return
+	}
+	must(err)
+
+	duplicate := &models.Setting{Name: "theme", Value: "light"}
+	again, err := models.SettingQuery.InsertIgnore(ctx, duplicate)
+	must(err)
+	fmt.Println(inserted, again, duplicate.IsNew())
+
+	must(models.SettingQuery.Replace(ctx, &models.Setting{Name: "theme", Value: "blue"}))
+	found, err := models.SettingQuery.FindByName(ctx, "theme")
+	must(err)
+	count, err := models.SettingQuery.Find().Count(ctx)
+	must(err)
+	fmt.Println(found.Value, count)
+}
+`
+
+func TestMySQLInsertIgnoreAndReplace(t *testing.T) {
+	tests := map[string]string{
+		"sqlite": "insert_ignore is not supported on sqlite\nreplace is not supported on sqlite",
+		"mysql":  "true false true\nblue 1",
+	}
+	for _, provider := range []string{"sqlite", "mysql"} {
+		t.Run(provider, func(t *testing.T) {
+			output := runGeneratedOn(t, provider, NewGenerator(), mysqlInsertSchema, mysqlInsertProgram)
+			if output != tests[provider] {
+				t.Errorf("output:\n%s\nwant:\n%s", output, tests[provider])
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+type legacyNaming struct {
+	core.DefaultNamingStrategy
+}
+
+func (legacyNaming) TableName(model string) string {
+	return "tbl_" + core.ToSnakeCase(model)
+}
+
+func (legacyNaming) ColumnName(field string) string {
+	return "c_" + core.ToSnakeCase(field)
+}
+
+func (legacyNaming) JoinTableName(left, right string) string {
+	return "tbl_" + core.ToSnakeCase(left) + "_" + core.ToSnakeCase(right)
+}
+
+const namingSchema = `
+model Author {
+  Id    Int     @id @auto
+  Name  String
+  Posts Post[]  @relation("AuthorPosts")
+}
+
+model Post {
+  Id       Int    @id @auto
+  Title    String
+  AuthorId Int
+  Author   Author @relation("AuthorPosts", fields: [AuthorId], references: [Id])
+  Tags     Tag[]
+
+  @@index([Title])
+}
+
+model Tag {
+  Id    Int    @id @auto
+  Label String @unique
+  Posts Post[]
+}
+`
+
+func TestCustomNamingStrategy(t *testing.T) {
+	g := NewGenerator()
+	g.SetNamingStrategy(legacyNaming{})
+	dir := generate(t, g, namingSchema)
+
+	tables := make(map[string]string)
+	for _, model := range g.Schema().Models {
+		tables[model.Name] = model.TableName
+	}
+	for model, want := range map[string]string{"Author": "tbl_author", "Post": "tbl_post", "Tag": "tbl_tag"} {
+		if tables[model] != want {
+			t.Errorf("table for %s = %q, want %q", model, tables[model], want)
+		}
+	}
+
+	post := readGenerated(t, dir, "post.go")
+	for _, want := range []string{
+		`const PostTable = "tbl_post"`,
+		`"c_title", "c_author_id", "c_created_at", "c_updated_at"`,
+		`UPDATE tbl_post SET `,
+		`"c_updated_at = ?"`,
+	} {
+		if !strings.Contains(post, want) {
+			t.Errorf("post.go does not contain %s", want)
+		}
+	}
+	if strings.Contains(post, `"created_at"`) {
+		t.Error("post.go still uses the default created_at column")
+	}
+
+	ddl := strings.Join(schemaStatements(t, g.Schema()), "\n")
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS tbl_post (",
+		"c_author_id INTEGER NOT NULL",
+		"c_created_at DATETIME NOT NULL",
+		"CREATE TABLE IF NOT EXISTS tbl_post_tag (",
+		"c_post_id INTEGER NOT NULL",
+		"c_tag_id INTEGER NOT NULL",
+		"ON tbl_post (c_title)",
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("DDL does not contain %q:\n%s", want, ddl)
+		}
+	}
+}
+
+func TestCustomNamingStrategyRoundTrip(t *testing.T) {
+	g := NewGenerator()
+	g.SetNamingStrategy(legacyNaming{})
+
+	output := runGenerated(t, g, namingSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	author, err := models.AuthorQuery.Create(ctx, &models.Author{Name: "Ann"})
+	must(err)
+
+	post := &models.Post{Title: "Draft"}
+	post.SetAuthor(author)
+	must(post.Save(ctx))
+
+	post.Title = "Published"
+	must(post.UpdateFields(ctx, "Title"))
+
+	found, err := models.PostQuery.Find().Where("c_title", "=", "Published").First(ctx)
+	must(err)
+	fmt.Println(found.(*models.Post).Title, found.(*models.Post).AuthorId == author.Id)
+
+	count, err := author.PostsCount(ctx)
+	must(err)
+	fmt.Println(count)
+}
+`)
+
+	if output != "Published true\n1" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestTablePrefix(t *testing.T) {
+	g := NewGenerator()
+	g.SetNamingStrategy(core.NewPrefixNamingStrategy("app_", nil))
+	dir := generate(t, g, namingSchema)
+
+	post := readGenerated(t, dir, "post.go")
+	for _, want := range []string{
+		`const PostTable = "app_posts"`,
+		"UPDATE app_posts SET ",
+		`"title", "author_id", "created_at", "updated_at"`,
+	} {
+		if !strings.Contains(post, want) {
+			t.Errorf("post.go does not contain %s", want)
+		}
+	}
+
+	ddl := strings.Join(schemaStatements(t, g.Schema()), "\n")
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS app_authors (",
+		"CREATE TABLE IF NOT EXISTS app_posts (",
+		"CREATE TABLE IF NOT EXISTS app_post_tags (",
+		"ON app_posts (title)",
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("DDL does not contain %q:\n%s", want, ddl)
+		}
+	}
+	if strings.Contains(ddl, "EXISTS posts") {
+		t.Errorf("DDL has an unprefixed table:\n%s", ddl)
+	}
+
+	output := runGenerated(t, g, namingSchema, `package main
+
+import (
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	author, err := models.AuthorQuery.Create(ctx, &models.Author{Name: "Ann"})
+	must(err)
+	post := &models.Post{Title: "Draft"}
+	post.SetAuthor(author)
+	must(post.Save(ctx))
+
+	count, err := author.PostsCount(ctx)
+	must(err)
+	var tables int
+	must(core.GetDB().SQL().QueryRowContext(ctx, "SELECT COUNT(*) FROM app_posts").Scan(&tables))
+	fmt.Println(count, tables, models.PostTable)
+}
+`)
+	if output != "1 1 app_posts" {
+		t.Errorf("output = %q", output)
+	}
+}
@@ -0,0 +1,64 @@
+package gen
+
+import "testing"
+
+const nullableSchema = `
+enum Mood {
+  HAPPY
+  SAD
+}
+
+model Author {
+  Id          Int       @id @auto
+  Name        String
+  Bio         String?
+  CategoryId  Int?
+  Rating      Float?
+  Verified    Boolean?
+  LastLoginAt DateTime?
+  Mood        Mood?
+}
+`
+
+func TestScanNullOptionalColumns(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), nullableSchema, `package main
+
+import (
+	"fmt"
+	"time"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	_, err := models.Exec(ctx, "INSERT INTO authors (name, bio, category_id, rating, verified, last_login_at, mood, created_at, updated_at) VALUES (?, NULL, NULL, NULL, NULL, NULL, NULL, ?, ?)", "raw", time.Now(), time.Now())
+	must(err)
+
+	raw, err := models.AuthorQuery.Find().Where("name", "=", "raw").First(ctx)
+	must(err)
+	author := raw.(*models.Author)
+	fmt.Println(author.Bio == nil, author.CategoryId == nil, author.Rating == nil, author.Verified == nil, author.LastLoginAt == nil, author.Mood == nil)
+
+	bio, category, mood := "writer", 3, models.MoodHappy
+	author.Bio, author.CategoryId, author.Mood = &bio, &category, &mood
+	must(author.Save(ctx))
+
+	loaded, err := models.AuthorQuery.FindById(ctx, author.Id)
+	must(err)
+	fmt.Println(*loaded.Bio, *loaded.CategoryId, *loaded.Mood, loaded.Rating == nil)
+
+	loaded.Bio = nil
+	must(loaded.Save(ctx))
+	loaded, err = models.AuthorQuery.FindById(ctx, author.Id)
+	must(err)
+	fmt.Println(loaded.Bio == nil, *loaded.CategoryId)
+}
+`)
+
+	want := "true true true true true true\nwriter 3 HAPPY true\ntrue 3"
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
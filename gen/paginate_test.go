@@ -0,0 +1,53 @@
+package gen
+
+import "testing"
+
+const paginateProgram = `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	for _, title := range []string{"a", "b", "c", "d", "e"} {
+		_, err := models.ArticleQuery.Create(ctx, &models.Article{Title: title})
+		must(err)
+	}
+
+	for _, number := range []int{2, 4} {
+		page, err := models.ArticleQuery.Find().OrderBy("title", "ASC").Paginate(ctx, number, 2)
+		must(err)
+
+		var titles []string
+		for _, item := range page.Items {
+			titles = append(titles, item.(*models.Article).Title)
+		}
+		fmt.Println(titles, page.Total, page.TotalPages(), page.HasNext())
+	}
+}
+`
+
+// On postgres a non-empty page comes from a single COUNT(*) OVER() query; the
+// results must match the two-query path SQLite takes.
+func TestPaginateTotals(t *testing.T) {
+	schema := `
+model Article {
+  Id    Int    @id @auto
+  Title String
+}
+`
+	for _, provider := range []string{"sqlite", "postgres"} {
+		t.Run(provider, func(t *testing.T) {
+			output := runGeneratedOn(t, provider, NewGenerator(), schema, paginateProgram)
+
+			want := "[c d] 5 3 true\n[] 5 3 false"
+			if output != want {
+				t.Errorf("output:\n%s\nwant:\n%s", output, want)
+			}
+		})
+	}
+}
@@ -5,21 +5,29 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/nitrix4ly/comet/core"
 )
 
 type Parser struct {
-	schema *core.Schema
+	schema   *core.Schema
+	naming   core.NamingStrategy
+	declared map[string]string
 }
 
 func NewParser() *Parser {
 	return &Parser{
 		schema: &core.Schema{},
+		naming: core.DefaultNamingStrategy{},
 	}
 }
 
+func (p *Parser) SetNamingStrategy(naming core.NamingStrategy) {
+	p.naming = naming
+}
+
 func (p *Parser) ParseFile(filename string) (*core.Schema, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -29,24 +37,65 @@ func (p *Parser) ParseFile(filename string) (*core.Schema, error) {
 
 	scanner := bufio.NewScanner(file)
 	var currentModel *core.ModelSchema
+	var currentEnum *core.EnumSchema
 	var inModel bool
+	lineNumber := 0
 
 	for scanner.Scan() {
+		lineNumber++
 		line := strings.TrimSpace(scanner.Text())
-		
+
 		if line == "" || strings.HasPrefix(line, "//") {
 			continue
 		}
 
+		if strings.HasPrefix(line, "enum ") && !inModel {
+			enumName := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "enum "), "{"))
+			if !regexp.MustCompile(`^[A-Z]\w*$`).MatchString(enumName) {
+				return nil, fmt.Errorf("invalid enum name '%s'", enumName)
+			}
+			if err := p.declare("enum", enumName, filename, lineNumber); err != nil {
+				return nil, err
+			}
+			currentEnum = &core.EnumSchema{Name: enumName}
+			continue
+		}
+
+		if currentEnum != nil {
+			if line == "}" {
+				if len(currentEnum.Values) == 0 {
+					return nil, fmt.Errorf("enum %s has no values", currentEnum.Name)
+				}
+				p.schema.Enums = append(p.schema.Enums, *currentEnum)
+				currentEnum = nil
+				continue
+			}
+			for _, value := range strings.Fields(line) {
+				if !regexp.MustCompile(`^[A-Za-z]\w*$`).MatchString(value) {
+					return nil, fmt.Errorf("invalid value '%s' in enum %s", value, currentEnum.Name)
+				}
+				for _, existing := range currentEnum.Values {
+					if strings.EqualFold(existing, value) {
+						return nil, fmt.Errorf("duplicate value '%s' in enum %s", value, currentEnum.Name)
+					}
+				}
+				currentEnum.Values = append(currentEnum.Values, value)
+			}
+			continue
+		}
+
 		if strings.HasPrefix(line, "model ") {
 			if currentModel != nil {
 				p.schema.Models = append(p.schema.Models, *currentModel)
 			}
-			
+
 			modelName := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "model "), "{"))
+			if err := p.declare("model", modelName, filename, lineNumber); err != nil {
+				return nil, err
+			}
 			currentModel = &core.ModelSchema{
 				Name:      modelName,
-				TableName: core.GetTableName(modelName),
+				TableName: p.naming.TableName(modelName),
 				Fields:    []core.FieldSchema{},
 				Relations: []core.Relation{},
 			}
@@ -63,6 +112,13 @@ func (p *Parser) ParseFile(filename string) (*core.Schema, error) {
 			continue
 		}
 
+		if inModel && currentModel != nil && strings.HasPrefix(line, "@@") {
+			if err := p.parseModelAttribute(line, currentModel); err != nil {
+				return nil, fmt.Errorf("error parsing attribute '%s': %v", line, err)
+			}
+			continue
+		}
+
 		if inModel && currentModel != nil {
 			if err := p.parseField(line, currentModel); err != nil {
 				return nil, fmt.Errorf("error parsing field '%s': %v", line, err)
@@ -77,6 +133,232 @@ func (p *Parser) ParseFile(filename string) (*core.Schema, error) {
 	return p.schema, scanner.Err()
 }
 
+func (p *Parser) declare(kind, name, filename string, line int) error {
+	location := fmt.Sprintf("%s:%d", filename, line)
+	if previous, ok := p.declared[name]; ok {
+		return fmt.Errorf("%s %s is already declared at %s (redeclared at %s)", kind, name, previous, location)
+	}
+
+	if p.declared == nil {
+		p.declared = make(map[string]string)
+	}
+	p.declared[name] = location
+	return nil
+}
+
+func (p *Parser) ParseFiles(filenames []string) (*core.Schema, error) {
+	p.schema = &core.Schema{}
+	p.declared = nil
+
+	for _, filename := range filenames {
+		if _, err := p.ParseFile(filename); err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+	}
+
+	if err := p.resolveTypes(); err != nil {
+		return nil, err
+	}
+
+	return p.schema, nil
+}
+
+func (p *Parser) resolveTypes() error {
+	modelNames := make(map[string]bool, len(p.schema.Models))
+	for _, model := range p.schema.Models {
+		modelNames[model.Name] = true
+	}
+
+	enumNames := make(map[string]bool, len(p.schema.Enums))
+	for _, enum := range p.schema.Enums {
+		if enumNames[enum.Name] || modelNames[enum.Name] {
+			return fmt.Errorf("duplicate type name '%s'", enum.Name)
+		}
+		enumNames[enum.Name] = true
+	}
+
+	projectionNames := make(map[string]bool)
+	for i := range p.schema.Models {
+		model := &p.schema.Models[i]
+
+		var relations []core.Relation
+		for _, relation := range model.Relations {
+			if relation.Type == "hasMany" && enumNames[relation.Model] {
+				return fmt.Errorf("%s.%s: enum arrays are not supported", model.Name, relation.FieldName)
+			}
+			if relation.Type == "hasMany" && !modelNames[relation.Model] {
+				return fmt.Errorf("%s.%s: unknown type '%s'", model.Name, relation.FieldName, relation.Model)
+			}
+			if relation.Type == "polymorphic" {
+				if err := p.checkPolymorphic(model, relation); err != nil {
+					return err
+				}
+			}
+			relations = append(relations, relation)
+		}
+		model.Relations = relations
+		model.Timestamps = p.timestampFields(model)
+
+		for j := range model.Projections {
+			projection := &model.Projections[j]
+			if modelNames[projection.Name] || enumNames[projection.Name] || projectionNames[projection.Name] {
+				return fmt.Errorf("duplicate type name '%s'", projection.Name)
+			}
+			projectionNames[projection.Name] = true
+
+			if err := p.resolveProjection(model, projection); err != nil {
+				return err
+			}
+		}
+
+		for j := range model.Fields {
+			if enumNames[model.Fields[j].Type] {
+				if model.Fields[j].GoType != "" {
+					return fmt.Errorf("%s.%s: @gotype cannot be used on enum fields", model.Name, model.Fields[j].Name)
+				}
+				model.Fields[j].Enum = true
+			}
+		}
+	}
+
+	p.resolveManyToMany()
+	return nil
+}
+
+// timestampFields returns the created and updated columns every model gets.
+// A declared createdAt or updatedAt field is moved out of model.Fields so its
+// attributes (precision, default) apply to the built-in timestamp instead of
+// generating a second field.
+func (p *Parser) timestampFields(model *core.ModelSchema) []core.FieldSchema {
+	var fields []core.FieldSchema
+	for _, name := range []string{"createdAt", "updatedAt"} {
+		column := p.naming.ColumnName(name)
+		timestamp := core.FieldSchema{Name: name, Column: column, Type: "DateTime"}
+		for i, field := range model.Fields {
+			if field.Name == name || field.ColumnName() == column {
+				timestamp = field
+				timestamp.Name, timestamp.Column = name, column
+				model.Fields = append(model.Fields[:i:i], model.Fields[i+1:]...)
+				break
+			}
+		}
+		fields = append(fields, timestamp)
+	}
+	return fields
+}
+
+func (p *Parser) resolveManyToMany() {
+	for i := range p.schema.Models {
+		model := &p.schema.Models[i]
+		for j := range model.Relations {
+			relation := &model.Relations[j]
+			if relation.Type != "hasMany" || len(relation.Fields) > 0 || relation.Model == model.Name || !p.hasManyBack(relation.Model, model.Name) {
+				continue
+			}
+
+			left, right := model.Name, relation.Model
+			if right < left {
+				left, right = right, left
+			}
+
+			relation.Type = "manyToMany"
+			relation.JoinTable = p.naming.JoinTableName(left, right)
+			relation.Fields = []string{p.naming.ColumnName(core.ToCamelCase(model.Name) + "Id")}
+			relation.References = []string{p.naming.ColumnName(core.ToCamelCase(relation.Model) + "Id")}
+		}
+	}
+}
+
+func (p *Parser) hasManyBack(target, model string) bool {
+	for _, candidate := range p.schema.Models {
+		if candidate.Name != target {
+			continue
+		}
+		for _, relation := range candidate.Relations {
+			if relation.Model == model && (relation.Type == "manyToMany" || relation.Type == "hasMany" && len(relation.Fields) == 0) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Parser) resolveProjection(model *core.ModelSchema, projection *core.Projection) error {
+	seen := make(map[string]bool, len(projection.Fields))
+	for i, name := range projection.Fields {
+		resolved := ""
+		for _, field := range model.Fields {
+			if field.Name == name || p.naming.ColumnName(field.Name) == name {
+				resolved = field.Name
+				break
+			}
+		}
+		if resolved == "" {
+			switch name {
+			case "createdAt", "created_at", p.naming.ColumnName("createdAt"):
+				resolved = "createdAt"
+			case "updatedAt", "updated_at", p.naming.ColumnName("updatedAt"):
+				resolved = "updatedAt"
+			default:
+				return fmt.Errorf("%s.%s: unknown field '%s'", model.Name, projection.Name, name)
+			}
+		}
+		if seen[resolved] {
+			return fmt.Errorf("%s.%s: duplicate field '%s'", model.Name, projection.Name, name)
+		}
+		seen[resolved] = true
+		projection.Fields[i] = resolved
+	}
+	return nil
+}
+
+func (p *Parser) checkPolymorphic(model *core.ModelSchema, relation core.Relation) error {
+	typeField := findField(model, relation.Fields[0])
+	idField := findField(model, relation.Fields[1])
+	if typeField == nil || idField == nil {
+		return fmt.Errorf("%s.%s: polymorphic fields must be declared on the model", model.Name, relation.FieldName)
+	}
+	if typeField.Type != "String" || typeField.Array {
+		return fmt.Errorf("%s.%s: polymorphic type field '%s' must be a String", model.Name, relation.FieldName, typeField.Name)
+	}
+	if typeField.Optional || idField.Optional {
+		return fmt.Errorf("%s.%s: polymorphic fields cannot be optional", model.Name, relation.FieldName)
+	}
+
+	for _, name := range relation.Models {
+		var target *core.ModelSchema
+		for i := range p.schema.Models {
+			if p.schema.Models[i].Name == name {
+				target = &p.schema.Models[i]
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("%s.%s: unknown model '%s'", model.Name, relation.FieldName, name)
+		}
+
+		var primary *core.FieldSchema
+		for i := range target.Fields {
+			if target.Fields[i].Primary {
+				primary = &target.Fields[i]
+			}
+		}
+		if primary == nil || primary.Type != idField.Type || idField.Array {
+			return fmt.Errorf("%s.%s: field '%s' does not match the primary key of %s", model.Name, relation.FieldName, idField.Name, name)
+		}
+	}
+
+	return nil
+}
+
+func findField(model *core.ModelSchema, name string) *core.FieldSchema {
+	for i := range model.Fields {
+		if model.Fields[i].Name == name {
+			return &model.Fields[i]
+		}
+	}
+	return nil
+}
+
 func (p *Parser) parseField(line string, model *core.ModelSchema) error {
 	parts := strings.Fields(line)
 	if len(parts) < 2 {
@@ -85,14 +367,27 @@ func (p *Parser) parseField(line string, model *core.ModelSchema) error {
 
 	fieldName := parts[0]
 	fieldType := parts[1]
-	
+
 	field := core.FieldSchema{
 		Name:     fieldName,
+		Column:   p.naming.ColumnName(fieldName),
 		Type:     strings.TrimSuffix(fieldType, "?"),
 		Optional: strings.HasSuffix(fieldType, "?"),
 	}
 
 	if strings.HasSuffix(fieldType, "[]") {
+		elemType := strings.TrimSuffix(fieldType, "[]")
+		if !isScalarType(elemType) {
+			return p.parseRelation(line, model)
+		}
+		if elemType == "DateTime" || elemType == "Bytes" {
+			return fmt.Errorf("array fields of type %s are not supported", elemType)
+		}
+		field.Type = elemType
+		field.Array = true
+	}
+
+	if !field.Array && !isScalarType(field.Type) && strings.Contains(line, "@relation(") {
 		return p.parseRelation(line, model)
 	}
 
@@ -105,6 +400,14 @@ func (p *Parser) parseField(line string, model *core.ModelSchema) error {
 	return nil
 }
 
+func isScalarType(fieldType string) bool {
+	switch fieldType {
+	case "Int", "String", "Boolean", "Float", "DateTime", "Bytes":
+		return true
+	}
+	return false
+}
+
 func (p *Parser) parseRelation(line string, model *core.ModelSchema) error {
 	parts := strings.Fields(line)
 	if len(parts) < 2 {
@@ -112,12 +415,18 @@ func (p *Parser) parseRelation(line string, model *core.ModelSchema) error {
 	}
 
 	fieldName := parts[0]
-	fieldType := strings.TrimSuffix(parts[1], "[]")
-	
+	fieldType := strings.TrimSuffix(strings.TrimSuffix(parts[1], "[]"), "?")
+
+	relationType := "hasOne"
+	if strings.HasSuffix(parts[1], "[]") {
+		relationType = "hasMany"
+	}
+
 	relation := core.Relation{
-		Name:  fieldName,
-		Type:  "hasMany",
-		Model: fieldType,
+		Name:      fieldName,
+		FieldName: fieldName,
+		Type:      relationType,
+		Model:     fieldType,
 	}
 
 	attributeStr := strings.Join(parts[2:], " ")
@@ -129,8 +438,13 @@ func (p *Parser) parseRelation(line string, model *core.ModelSchema) error {
 	return nil
 }
 
+var nativeTypeAttributes = map[string]string{
+	"TIMESTAMPTZ": "db.Timestamptz",
+	"TIMESTAMP":   "db.Timestamp",
+}
+
 func (p *Parser) parseAttributes(attributeStr string, field *core.FieldSchema) error {
-	re := regexp.MustCompile(`@(\w+)(?:\(([^)]*)\))?`)
+	re := regexp.MustCompile(`@([\w.]+)(?:\(((?:"(?:[^"\\]|\\.)*"|\([^()]*\)|[^()"])*)\))?`)
 	matches := re.FindAllStringSubmatch(attributeStr, -1)
 
 	for _, match := range matches {
@@ -152,24 +466,204 @@ func (p *Parser) parseAttributes(attributeStr string, field *core.FieldSchema) e
 		case "updatedAt":
 			field.Type = "DateTime"
 			field.Default = "now()"
+		case "check":
+			field.Check = unquote(attrValue)
+		case "tenant":
+			field.Tenant = true
+		case "searchable":
+			field.Searchable = true
+		case "computed":
+			field.Computed = unquote(attrValue)
+		case "db.Timestamptz", "db.Timestamp":
+			field.NativeType = strings.ToUpper(strings.TrimPrefix(attrName, "db."))
+			if value := strings.TrimSpace(attrValue); value != "" {
+				precision, err := strconv.Atoi(value)
+				if err != nil || precision < 0 || precision > 6 {
+					return fmt.Errorf("invalid timestamp precision '%s', must be between 0 and 6", value)
+				}
+				field.Precision = &precision
+			}
+		case "db.Collate":
+			field.Collation = unquote(attrValue)
+		case "comment":
+			field.Comment = unquote(attrValue)
+		case "gotype":
+			field.GoType = unquote(attrValue)
+		case "hidden":
+			field.Hidden = true
+		case "readonly":
+			field.ReadOnly = true
+		}
+	}
+
+	if field.Computed != "" && (field.Primary || field.Default != nil || field.Tenant) {
+		return fmt.Errorf("@computed fields cannot be combined with @id, @default or @tenant")
+	}
+
+	if field.Searchable && (field.Type != "String" || field.Array) {
+		return fmt.Errorf("@searchable can only be used on String fields")
+	}
+
+	if field.Type == "Bytes" && (field.Primary || field.Default != nil) {
+		return fmt.Errorf("@id and @default cannot be used on Bytes fields")
+	}
+
+	if field.Collation != "" {
+		if field.Type != "String" {
+			return fmt.Errorf("@db.Collate can only be used on String fields")
+		}
+		if !regexp.MustCompile(`^[\w.-]+$`).MatchString(field.Collation) {
+			return fmt.Errorf("invalid collation '%s'", field.Collation)
 		}
 	}
 
+	if field.GoType != "" {
+		if !regexp.MustCompile(`^([\w.-]+/)*[\w.-]+\.[A-Za-z_]\w*$`).MatchString(field.GoType) {
+			return fmt.Errorf("invalid Go type '%s', expected \"import/path.Type\"", field.GoType)
+		}
+		if field.Primary || field.Array || field.Tenant || field.Computed != "" {
+			return fmt.Errorf("@gotype cannot be combined with @id, @tenant, @computed or array fields")
+		}
+	}
+
+	if field.NativeType != "" && field.Type != "DateTime" {
+		return fmt.Errorf("@%s can only be used on DateTime fields", nativeTypeAttributes[field.NativeType])
+	}
+
 	return nil
 }
 
-func (p *Parser) parseRelationAttributes(attributeStr string, relation *core.Relation) error {
-	re := regexp.MustCompile(`@relation\("([^"]*)"(?:,\s*fields:\s*\[([^\]]*)\])?(?:,\s*references:\s*\[([^\]]*)\])?\)`)
-	match := re.FindStringSubmatch(attributeStr)
+func (p *Parser) parseModelAttribute(line string, model *core.ModelSchema) error {
+	switch {
+	case strings.HasPrefix(line, "@@check("):
+		return p.parseCheckAttribute(line, model)
+	case strings.HasPrefix(line, "@@scope("):
+		return p.parseScopeAttribute(line, model)
+	case strings.HasPrefix(line, "@@comment("):
+		return p.parseCommentAttribute(line, model)
+	case strings.HasPrefix(line, "@@projection"):
+		return p.parseProjectionAttribute(line, model)
+	default:
+		return p.parseIndexAttribute(line, model)
+	}
+}
+
+func (p *Parser) parseCheckAttribute(line string, model *core.ModelSchema) error {
+	re := regexp.MustCompile(`^@@check\(("(?:[^"\\]|\\.)*")\)$`)
+	match := re.FindStringSubmatch(line)
+	if match == nil {
+		return fmt.Errorf("invalid check constraint")
+	}
 
-	if len(match) > 1 {
-		relation.Name = match[1]
+	model.Checks = append(model.Checks, unquote(match[1]))
+	return nil
+}
+
+func (p *Parser) parseCommentAttribute(line string, model *core.ModelSchema) error {
+	re := regexp.MustCompile(`^@@comment\(("(?:[^"\\]|\\.)*")\)$`)
+	match := re.FindStringSubmatch(line)
+	if match == nil {
+		return fmt.Errorf("invalid comment, expected @@comment(\"text\")")
+	}
+
+	model.Comment = unquote(match[1])
+	return nil
+}
+
+func (p *Parser) parseProjectionAttribute(line string, model *core.ModelSchema) error {
+	re := regexp.MustCompile(`^@@projection\s+([A-Za-z]\w*)\s*\{([\w\s,]*)\}$`)
+	match := re.FindStringSubmatch(line)
+	if match == nil {
+		return fmt.Errorf("invalid projection, expected @@projection Name { field ... }")
+	}
+
+	fields := strings.FieldsFunc(match[2], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return fmt.Errorf("projection %s has no fields", match[1])
+	}
+
+	model.Projections = append(model.Projections, core.Projection{
+		Name:   match[1],
+		Fields: fields,
+	})
+	return nil
+}
+
+func (p *Parser) parseScopeAttribute(line string, model *core.ModelSchema) error {
+	re := regexp.MustCompile(`^@@scope\(("(?:[^"\\]|\\.)*")\s*,\s*("(?:[^"\\]|\\.)*")\)$`)
+	match := re.FindStringSubmatch(line)
+	if match == nil {
+		return fmt.Errorf("invalid scope, expected @@scope(\"name\", \"condition\")")
+	}
+
+	name := unquote(match[1])
+	if !regexp.MustCompile(`^[A-Za-z]\w*$`).MatchString(name) {
+		return fmt.Errorf("invalid scope name '%s'", name)
+	}
+
+	for _, scope := range model.Scopes {
+		if strings.EqualFold(scope.Name, name) {
+			return fmt.Errorf("duplicate scope '%s'", name)
+		}
+	}
+
+	model.Scopes = append(model.Scopes, core.Scope{
+		Name:      name,
+		Condition: unquote(match[2]),
+	})
+	return nil
+}
+
+func (p *Parser) parseIndexAttribute(line string, model *core.ModelSchema) error {
+	re := regexp.MustCompile(`^@@(unique|index)\(\[([^\]]*)\](?:,\s*where:\s*"([^"]*)")?\)$`)
+	match := re.FindStringSubmatch(line)
+	if match == nil {
+		return fmt.Errorf("unknown model attribute")
+	}
+
+	fields := strings.Split(strings.ReplaceAll(match[2], " ", ""), ",")
+	if len(fields) == 0 || fields[0] == "" {
+		return fmt.Errorf("index requires at least one field")
+	}
+
+	index := core.Index{
+		Fields: fields,
+		Unique: match[1] == "unique",
+		Where:  match[3],
+	}
+
+	suffix := "idx"
+	if index.Unique {
+		suffix = "key"
+	}
+	index.Name = fmt.Sprintf("%s_%s_%s", model.TableName, strings.Join(fields, "_"), suffix)
+
+	model.Indexes = append(model.Indexes, index)
+	return nil
+}
+
+func (p *Parser) parseRelationAttributes(attributeStr string, relation *core.Relation) error {
+	match := regexp.MustCompile(`@relation\(([^)]*)\)`).FindStringSubmatch(attributeStr)
+	if match == nil {
+		return nil
 	}
-	if len(match) > 2 && match[2] != "" {
-		relation.Fields = strings.Split(strings.ReplaceAll(match[2], " ", ""), ",")
+	args := match[1]
+
+	if name := regexp.MustCompile(`^\s*"([^"]*)"`).FindStringSubmatch(args); name != nil {
+		relation.Name = name[1]
 	}
-	if len(match) > 3 && match[3] != "" {
-		relation.References = strings.Split(strings.ReplaceAll(match[3], " ", ""), ",")
+	relation.Fields = relationList(args, "fields")
+	relation.References = relationList(args, "references")
+
+	if models := relationList(args, "polymorphic"); models != nil {
+		if len(relation.Fields) != 2 {
+			return fmt.Errorf("polymorphic relation '%s' needs fields: [typeField, idField]", relation.FieldName)
+		}
+		relation.Type = "polymorphic"
+		relation.Models = models
+		return nil
 	}
 
 	if len(relation.Fields) > 0 && len(relation.References) > 0 {
@@ -179,9 +673,24 @@ func (p *Parser) parseRelationAttributes(attributeStr string, relation *core.Rel
 	return nil
 }
 
+func relationList(args, key string) []string {
+	match := regexp.MustCompile(`\b` + key + `:\s*\[([^\]]*)\]`).FindStringSubmatch(args)
+	if match == nil || match[1] == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(match[1], " ", ""), ",")
+}
+
+func unquote(value string) string {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return value
+}
+
 func (p *Parser) parseDefaultValue(value string) interface{} {
 	value = strings.Trim(value, `"'`)
-	
+
 	switch value {
 	case "now()":
 		return "CURRENT_TIMESTAMP"
@@ -190,9 +699,6 @@ func (p *Parser) parseDefaultValue(value string) interface{} {
 	case "false":
 		return false
 	default:
-		if strings.Contains(value, ".") {
-			return value
-		}
 		return value
 	}
 }
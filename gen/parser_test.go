@@ -0,0 +1,110 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRelationAndScalarArrays(t *testing.T) {
+	schema, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), `
+model Article {
+  Id       Int       @id @auto
+  Tags     String[]
+  Scores   Int[]
+  Comments Comment[]
+}
+
+model Comment {
+  Id        Int     @id @auto
+  ArticleId Int
+  Article   Article @relation(fields: [ArticleId], references: [Id])
+}
+`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	article := schema.Models[0]
+	arrays := make(map[string]string)
+	for _, field := range article.Fields {
+		if field.Array {
+			arrays[field.Name] = field.Type
+		}
+	}
+	if len(arrays) != 2 || arrays["Tags"] != "String" || arrays["Scores"] != "Int" {
+		t.Errorf("scalar arrays = %v, want Tags String and Scores Int", arrays)
+	}
+
+	if len(article.Relations) != 1 || article.Relations[0].FieldName != "Comments" || article.Relations[0].Type != "hasMany" || article.Relations[0].Model != "Comment" {
+		t.Errorf("relations = %+v, want hasMany Comments", article.Relations)
+	}
+}
+
+func TestParseUnknownArrayType(t *testing.T) {
+	_, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), `
+model Article {
+  Id    Int    @id @auto
+  Notes Note[]
+}
+`)})
+	if err == nil || !strings.Contains(err.Error(), "Article.Notes: unknown type 'Note'") {
+		t.Errorf("err = %v, want unknown type 'Note'", err)
+	}
+}
+
+func TestParseCollation(t *testing.T) {
+	schema, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), `
+model Account {
+  Id    Int    @id @auto
+  Email String @unique @db.Collate("NOCASE")
+}
+`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, field := range schema.Models[0].Fields {
+		if field.Name == "Email" && field.Collation != "NOCASE" {
+			t.Errorf("Email collation = %q, want NOCASE", field.Collation)
+		}
+	}
+}
+
+func TestParseInvalidCollation(t *testing.T) {
+	for field, want := range map[string]string{
+		`Age Int @db.Collate("NOCASE")`:            "@db.Collate can only be used on String fields",
+		`Email String @db.Collate("NOCASE; DROP")`: "invalid collation 'NOCASE; DROP'",
+	} {
+		_, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), `
+model Account {
+  Id Int @id @auto
+  `+field+`
+}
+`)})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %q", field, err, want)
+		}
+	}
+}
+
+func TestParseDuplicateModelAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	users := filepath.Join(dir, "users.cmt")
+	accounts := filepath.Join(dir, "accounts.cmt")
+	for file, src := range map[string]string{
+		users:    "model User {\n  Id Int @id @auto\n}\n",
+		accounts: "model Account {\n  Id Int @id @auto\n}\n\nmodel User {\n  Id    Int    @id @auto\n  Email String\n}\n",
+	} {
+		if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := NewParser().ParseFiles([]string{users, accounts})
+	want := "model User is already declared at " + users + ":1 (redeclared at " + accounts + ":5)"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("err = %v, want %s", err, want)
+	}
+}
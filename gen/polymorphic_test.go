@@ -0,0 +1,149 @@
+package gen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const polymorphicSchema = `
+model Post {
+  Id    Int    @id @auto
+  Title String
+}
+
+model User {
+  Id   Int    @id @auto
+  Name String
+}
+
+model Comment {
+  Id              Int    @id @auto
+  Body            String
+  CommentableType String
+  CommentableId   Int
+
+  Commentable     Commentable @relation("Commentable", polymorphic: [Post, User], fields: [CommentableType, CommentableId])
+}
+`
+
+func TestParsePolymorphicRelation(t *testing.T) {
+	schema, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), polymorphicSchema)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comment := schema.Models[2]
+	if len(comment.Relations) != 1 {
+		t.Fatalf("relations = %+v, want one", comment.Relations)
+	}
+	relation := comment.Relations[0]
+	if relation.Type != "polymorphic" || relation.FieldName != "Commentable" {
+		t.Errorf("relation = %+v, want polymorphic Commentable", relation)
+	}
+	if !reflect.DeepEqual(relation.Models, []string{"Post", "User"}) || !reflect.DeepEqual(relation.Fields, []string{"CommentableType", "CommentableId"}) {
+		t.Errorf("models = %v, fields = %v", relation.Models, relation.Fields)
+	}
+}
+
+func TestParseInvalidPolymorphicRelations(t *testing.T) {
+	tests := []struct {
+		fields   string
+		relation string
+		want     string
+	}{
+		{"TargetType String\n  TargetId Int", `@relation(polymorphic: [Post], fields: [TargetType])`, "needs fields: [typeField, idField]"},
+		{"TargetType Int\n  TargetId Int", `@relation(polymorphic: [Post], fields: [TargetType, TargetId])`, "type field 'TargetType' must be a String"},
+		{"TargetType String\n  TargetId Int?", `@relation(polymorphic: [Post], fields: [TargetType, TargetId])`, "polymorphic fields cannot be optional"},
+		{"TargetType String\n  TargetId Int", `@relation(polymorphic: [Post, Page], fields: [TargetType, TargetId])`, "unknown model 'Page'"},
+		{"TargetType String\n  TargetId String", `@relation(polymorphic: [Post], fields: [TargetType, TargetId])`, "'TargetId' does not match the primary key of Post"},
+		{"TargetType String", `@relation(polymorphic: [Post], fields: [TargetType, TargetId])`, "polymorphic fields must be declared on the model"},
+	}
+
+	for _, tt := range tests {
+		_, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), `
+model Post {
+  Id Int @id @auto
+}
+
+model Tag {
+  Id Int @id @auto
+  `+tt.fields+`
+  Target Target `+tt.relation+`
+}
+`)})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.relation, err, tt.want)
+		}
+	}
+}
+
+func TestPolymorphicAccessors(t *testing.T) {
+	comment := readGenerated(t, generate(t, NewGenerator(), polymorphicSchema), "comment.go")
+	for _, want := range []string{
+		"func (m *Comment) Commentable(ctx context.Context) (interface{}, error) {",
+		"\tswitch m.CommentableType {\n\tcase \"Post\":\n\t\tresult, err := PostQuery.FindById(ctx, m.CommentableId)",
+		"\tcase \"User\":\n\t\tresult, err := UserQuery.FindById(ctx, m.CommentableId)",
+		"func (m *Comment) SetCommentable(target interface{}) error {",
+	} {
+		if !strings.Contains(comment, want) {
+			t.Errorf("comment.go does not contain:\n%s", want)
+		}
+	}
+
+	output := runGenerated(t, NewGenerator(), polymorphicSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func describe(target interface{}) string {
+	switch t := target.(type) {
+	case *models.Post:
+		return "post " + t.Title
+	case *models.User:
+		return "user " + t.Name
+	}
+	return fmt.Sprintf("%T", target)
+}
+
+func main() {
+	ctx := setup()
+
+	post, err := models.PostQuery.Create(ctx, &models.Post{Title: "Hello"})
+	must(err)
+	_, err = models.UserQuery.Create(ctx, &models.User{Name: "filler"})
+	must(err)
+	user, err := models.UserQuery.Create(ctx, &models.User{Name: "ann"})
+	must(err)
+
+	for _, target := range []interface{}{post, user} {
+		comment := &models.Comment{Body: "nice"}
+		must(comment.SetCommentable(target))
+		must(comment.Save(ctx))
+
+		loaded, err := models.CommentQuery.FindById(ctx, comment.Id)
+		must(err)
+		found, err := loaded.Commentable(ctx)
+		must(err)
+		fmt.Println(loaded.CommentableType, loaded.CommentableId, describe(found))
+	}
+
+	fmt.Println((&models.Comment{}).SetCommentable(&models.Comment{}))
+	_, err = (&models.Comment{CommentableType: "Page", CommentableId: 1}).Commentable(ctx)
+	fmt.Println(err)
+}
+`)
+
+	want := strings.Join([]string{
+		"Post 1 post Hello",
+		"User 2 user ann",
+		"cannot use *models.Comment as commentable",
+		"unknown commentable type 'Page'",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
@@ -0,0 +1,108 @@
+package gen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const projectionSchema = `
+model Post {
+  Id    Int    @id @auto
+  Title String
+  Body  String
+
+  @@projection PostSummary { id title created_at }
+}
+`
+
+func TestParseProjection(t *testing.T) {
+	schema, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), projectionSchema)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	projections := schema.Models[0].Projections
+	if len(projections) != 1 || projections[0].Name != "PostSummary" {
+		t.Fatalf("projections = %+v", projections)
+	}
+	if want := []string{"Id", "Title", "createdAt"}; !reflect.DeepEqual(projections[0].Fields, want) {
+		t.Errorf("fields = %q, want %q", projections[0].Fields, want)
+	}
+}
+
+func TestParseInvalidProjection(t *testing.T) {
+	for attr, want := range map[string]string{
+		"@@projection PostSummary { id missing }": "unknown field 'missing'",
+		"@@projection PostSummary { id Id }":      "duplicate field 'Id'",
+		"@@projection PostSummary { }":            "has no fields",
+		"@@projection PostSummary id title":       "invalid projection",
+		"@@projection Post { id }":                "duplicate type name 'Post'",
+	} {
+		_, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), `
+model Post {
+  Id    Int    @id @auto
+  Title String
+
+  `+attr+`
+}
+`)})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %s", attr, err, want)
+		}
+	}
+}
+
+func TestProjectionGeneration(t *testing.T) {
+	dir := generate(t, NewGenerator(), projectionSchema)
+
+	post := readGenerated(t, dir, "post.go")
+	for _, want := range []string{
+		"type PostSummary struct {",
+		"func (q *PostQueryBuilder) Summaries() core.QueryBuilder {",
+		`.Select("id", "title", "created_at")`,
+		"func (q *PostQueryBuilder) FindSummaries(ctx context.Context) ([]*PostSummary, error) {",
+		"rows.Scan(&m.Id, &m.Title, &m.CreatedAt)",
+	} {
+		if !strings.Contains(post, want) {
+			t.Errorf("post.go does not contain %s", want)
+		}
+	}
+	if strings.Contains(post[strings.Index(post, "type PostSummary struct {"):], "Body ") {
+		t.Error("PostSummary has the unprojected Body field")
+	}
+}
+
+func TestProjectionRoundTrip(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), projectionSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	_, err := models.PostQuery.Create(ctx, &models.Post{Title: "Go", Body: "long body"})
+	must(err)
+	_, err = models.PostQuery.Create(ctx, &models.Post{Title: "Rust", Body: "longer body"})
+	must(err)
+
+	summaries, err := models.PostQuery.FindSummaries(ctx)
+	must(err)
+	for _, s := range summaries {
+		fmt.Println(s.Id, s.Title, !s.CreatedAt.IsZero())
+	}
+
+	rows, err := models.PostQuery.Summaries().Where("title", "=", "Rust").All(ctx)
+	must(err)
+	fmt.Println(len(rows), rows[0].(*models.PostSummary).Title)
+}
+`)
+
+	if output != "1 Go true\n2 Rust true\n1 Rust" {
+		t.Errorf("output = %q", output)
+	}
+}
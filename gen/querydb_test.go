@@ -0,0 +1,65 @@
+package gen
+
+import "testing"
+
+func TestQueryOnWritesToExplicitDB(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), `
+model Note {
+  Id   Int    @id @auto
+  Slug String @unique
+  Body String
+}
+`, `package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+	other := openDB("other")
+	q := models.NoteQueryOn(other)
+
+	note, err := q.Create(ctx, &models.Note{Slug: "a", Body: "one"})
+	must(err)
+	note.Body = "uno"
+	_, err = q.Update(ctx, note)
+	must(err)
+	_, err = q.CreateReturning(ctx, &models.Note{Slug: "b", Body: "two"}, "id")
+	must(err)
+	_, err = q.CreateUnique(ctx, &models.Note{Slug: "c", Body: "three"}, "slug")
+	must(err)
+
+	result, err := q.Sync(ctx, []*models.Note{{Slug: "a", Body: "eins"}, {Slug: "d", Body: "vier"}}, "slug", false)
+	must(err)
+	fmt.Println(result.Inserted, result.Updated)
+
+	var unsupported *core.UnsupportedError
+	_, err = q.InsertIgnore(ctx, &models.Note{Slug: "e", Body: "five"})
+	fmt.Println(errors.As(err, &unsupported))
+
+	onOther, err := q.Find().Count(ctx)
+	must(err)
+	onMain, err := models.NoteQuery.Find().Count(ctx)
+	must(err)
+	fmt.Println(onOther, onMain)
+
+	core.SetDB(nil)
+	_, err = q.Create(ctx, &models.Note{Slug: "f", Body: "six"})
+	must(err)
+	a, err := q.FindBySlug(ctx, "a")
+	must(err)
+	fmt.Println(a.Body)
+}
+`)
+
+	want := "1 1\ntrue\n4 0\neins"
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+}
@@ -0,0 +1,47 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyContextRejectsModelWrites(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), noteSchema, `package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	note := &models.Note{Body: "draft"}
+	must(note.Save(ctx))
+
+	readOnly := core.ReadOnly(ctx)
+	note.Body = "changed"
+	fmt.Println(errors.Is(note.Save(readOnly), core.ErrReadOnly))
+	fmt.Println(errors.Is(note.UpdateFields(readOnly, "Body"), core.ErrReadOnly))
+	_, err := models.NoteQuery.Update(readOnly, note)
+	fmt.Println(errors.Is(err, core.ErrReadOnly))
+	fmt.Println(errors.Is((&models.Note{Body: "new"}).Save(readOnly), core.ErrReadOnly))
+	fmt.Println(errors.Is(note.Delete(readOnly), core.ErrReadOnly))
+
+	found, err := models.NoteQuery.FindById(readOnly, note.Id)
+	must(err)
+	count, err := models.NoteQuery.Find().Count(readOnly)
+	must(err)
+	fmt.Println(found.Body, count)
+}
+`)
+
+	want := strings.Join([]string{"true", "true", "true", "true", "true", "draft 1"}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
@@ -0,0 +1,39 @@
+package gen
+
+import "testing"
+
+func TestRelationCountAndExists(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), relationsSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	ann, err := models.UserQuery.Create(ctx, &models.User{Name: "ann"})
+	must(err)
+	bob, err := models.UserQuery.Create(ctx, &models.User{Name: "bob"})
+	must(err)
+	for _, title := range []string{"one", "two", "three"} {
+		_, err := models.PostQuery.Create(ctx, &models.Post{Title: title, AuthorId: ann.Id})
+		must(err)
+	}
+
+	for _, user := range []*models.User{ann, bob} {
+		count, err := user.PostsCount(ctx)
+		must(err)
+		has, err := user.HasPosts(ctx)
+		must(err)
+		fmt.Println(user.Name, count, has, user.Posts == nil)
+	}
+}
+`)
+
+	if output != "ann 3 true true\nbob 0 false true" {
+		t.Errorf("output = %q", output)
+	}
+}
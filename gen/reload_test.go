@@ -0,0 +1,107 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const relationsSchema = `
+model User {
+  Id      Int      @id @auto
+  Name    String
+  Posts   Post[]   @relation("UserPosts")
+  Profile Profile? @relation("UserProfile")
+}
+
+model Profile {
+  Id     Int    @id @auto
+  Bio    String
+  UserId Int    @unique
+  User   User   @relation("UserProfile", fields: [UserId], references: [Id])
+}
+
+model Post {
+  Id       Int    @id @auto
+  Title    String
+  AuthorId Int
+  Author   User   @relation("UserPosts", fields: [AuthorId], references: [Id])
+}
+
+model Event {
+  Name String
+  At   DateTime
+}
+`
+
+func TestReloadOnlyForModelsWithPrimaryKey(t *testing.T) {
+	dir := generate(t, NewGenerator(), relationsSchema)
+
+	if !strings.Contains(readGenerated(t, dir, "user.go"), "func (m *User) ReloadWith(") {
+		t.Error("User has no ReloadWith")
+	}
+	event := readGenerated(t, dir, "event.go")
+	if strings.Contains(event, "Reload") {
+		t.Error("Event has no @id but got Reload")
+	}
+	if strings.Contains(event, `"errors"`) {
+		t.Error("event.go imports errors without using it")
+	}
+}
+
+func TestIncludeAndReloadWith(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), relationsSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	ann, err := models.UserQuery.Create(ctx, &models.User{Name: "Ann"})
+	must(err)
+	bob, err := models.UserQuery.Create(ctx, &models.User{Name: "Bob"})
+	must(err)
+	_, err = models.ProfileQuery.Create(ctx, &models.Profile{Bio: "gopher", UserId: ann.Id})
+	must(err)
+	for _, title := range []string{"one", "two"} {
+		_, err = models.PostQuery.Create(ctx, &models.Post{Title: title, AuthorId: ann.Id})
+		must(err)
+	}
+
+	must(ann.ReloadWith(ctx, "Posts", "Profile"))
+	fmt.Println(ann.Name, len(ann.Posts), ann.Profile.Bio)
+
+	rows, err := models.UserQuery.Find().Include("Posts", "Profile").OrderBy("id", "ASC").All(ctx)
+	must(err)
+	for _, row := range rows {
+		user := row.(*models.User)
+		fmt.Println(user.Name, len(user.Posts), user.Profile != nil)
+	}
+
+	post, err := models.PostQuery.Find().Include("Author").First(ctx)
+	must(err)
+	fmt.Println(post.(*models.Post).Author.Name)
+
+	must(bob.Delete(ctx))
+	fmt.Println(bob.Reload(ctx))
+
+	_, err = models.UserQuery.Find().Include("Comments").All(ctx)
+	fmt.Println(err)
+}
+`)
+
+	want := strings.Join([]string{
+		"Ann 2 gopher",
+		"Ann 2 true",
+		"Bob 0 false",
+		"Ann",
+		"record not found",
+		"unknown relation 'Comments' on User",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
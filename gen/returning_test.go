@@ -0,0 +1,74 @@
+package gen
+
+import (
+	"fmt"
+	"testing"
+)
+
+const returningSchema = `
+model Post {
+  Id    Int    @id @auto
+  Title String
+  Slug  String @default("")
+}
+`
+
+// slugTriggers fill posts.slug from the title on insert, behind the model's back.
+var slugTriggers = map[string][]string{
+	"sqlite": {
+		"CREATE TRIGGER post_slug AFTER INSERT ON posts BEGIN UPDATE posts SET slug = lower(NEW.title) WHERE id = NEW.id; END",
+	},
+	"postgres": {
+		"CREATE OR REPLACE FUNCTION post_slug() RETURNS trigger AS $body$ BEGIN NEW.slug := lower(NEW.title); RETURN NEW; END $body$ LANGUAGE plpgsql",
+		"CREATE TRIGGER post_slug BEFORE INSERT ON posts FOR EACH ROW EXECUTE FUNCTION post_slug()",
+	},
+	"mysql": {
+		"CREATE TRIGGER post_slug BEFORE INSERT ON posts FOR EACH ROW SET NEW.slug = LOWER(NEW.title)",
+	},
+}
+
+const returningProgram = `package main
+
+import (
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+var triggers = %#v
+
+func main() {
+	ctx := setup()
+	for _, trigger := range triggers {
+		_, err := core.GetDB().Exec(ctx, trigger)
+		must(err)
+	}
+
+	plain, err := models.PostQuery.Create(ctx, &models.Post{Title: "Plain"})
+	must(err)
+	fmt.Printf("%%q\n", plain.Slug)
+
+	post, err := models.PostQuery.CreateReturning(ctx, &models.Post{Title: "Hello World"}, "slug")
+	must(err)
+	fmt.Println(post.Id > plain.Id, post.Slug)
+
+	_, err = models.PostQuery.CreateReturning(ctx, &models.Post{Title: "Nope"}, "missing")
+	fmt.Println(err)
+}
+`
+
+func TestCreateReturning(t *testing.T) {
+	for _, provider := range []string{"sqlite", "postgres", "mysql"} {
+		t.Run(provider, func(t *testing.T) {
+			program := fmt.Sprintf(returningProgram, slugTriggers[provider])
+			output := runGeneratedOn(t, provider, NewGenerator(), returningSchema, program)
+
+			want := "\"\"\ntrue hello world\nunknown column 'missing' on Post"
+			if output != want {
+				t.Errorf("output:\n%s\nwant:\n%s", output, want)
+			}
+		})
+	}
+}
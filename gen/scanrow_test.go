@@ -0,0 +1,49 @@
+package gen
+
+import "testing"
+
+func TestScanRowHelpers(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), noteSchema, `package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gentest/models"
+
+	"github.com/nitrix4ly/comet/core"
+)
+
+func main() {
+	ctx := setup()
+
+	for _, body := range []string{"first", "second"} {
+		must((&models.Note{Body: body}).Save(ctx))
+	}
+	db := core.GetDB().SQL()
+
+	note, err := models.ScanNoteRow(db.QueryRowContext(ctx, "SELECT * FROM notes WHERE body = ?", "second"))
+	must(err)
+	fmt.Println(note.Id, note.Body, note.CreatedAt.IsZero(), note.IsNew())
+
+	_, err = models.ScanNoteRow(db.QueryRowContext(ctx, "SELECT * FROM notes WHERE body = ?", "missing"))
+	fmt.Println(errors.Is(err, sql.ErrNoRows))
+
+	rows, err := db.QueryContext(ctx, "SELECT * FROM notes ORDER BY id DESC")
+	must(err)
+	defer rows.Close()
+	for rows.Next() {
+		note, err := models.ScanNoteRows(rows)
+		must(err)
+		fmt.Println(note.Id, note.Body)
+	}
+	must(rows.Err())
+}
+`)
+
+	want := "2 second false false\ntrue\n2 second\n1 first"
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
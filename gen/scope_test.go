@@ -0,0 +1,102 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const scopeSchema = `
+model Post {
+  Id          Int     @id @auto
+  Title       String
+  IsPublished Boolean @default(false)
+  Views       Int     @default(0)
+
+  @@scope("published", "is_published = true")
+  @@scope("popular", "views > 100 AND title <> \"\"")
+}
+`
+
+func TestParseScopes(t *testing.T) {
+	schema, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), scopeSchema)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scopes := schema.Models[0].Scopes
+	if len(scopes) != 2 {
+		t.Fatalf("scopes = %+v, want 2", scopes)
+	}
+	if scopes[0].Name != "published" || scopes[0].Condition != "is_published = true" {
+		t.Errorf("scopes[0] = %+v", scopes[0])
+	}
+	if scopes[1].Name != "popular" || scopes[1].Condition != `views > 100 AND title <> ""` {
+		t.Errorf("scopes[1] = %+v", scopes[1])
+	}
+}
+
+func TestParseInvalidScopes(t *testing.T) {
+	for attribute, want := range map[string]string{
+		`@@scope("published")`:                                           "invalid scope",
+		`@@scope("is published", "is_published = true")`:                 "invalid scope name 'is published'",
+		`@@scope("live", "a = 1")` + "\n  " + `@@scope("Live", "b = 1")`: "duplicate scope 'Live'",
+	} {
+		_, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), `
+model Post {
+  Id Int @id @auto
+
+  `+attribute+`
+}
+`)})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %q", attribute, err, want)
+		}
+	}
+}
+
+func TestScopeMethods(t *testing.T) {
+	post := readGenerated(t, generate(t, NewGenerator(), scopeSchema), "post.go")
+	for _, want := range []string{
+		"func (q *PostQueryBuilder) Published() core.QueryBuilder {\n\treturn q.Find().WhereRaw(\"is_published = true\")\n}",
+		"func (q *PostQueryBuilder) Popular() core.QueryBuilder {\n\treturn q.Find().WhereRaw(\"views > 100 AND title <> \\\"\\\"\")\n}",
+	} {
+		if !strings.Contains(post, want) {
+			t.Errorf("post.go does not contain:\n%s", want)
+		}
+	}
+
+	output := runGenerated(t, NewGenerator(), scopeSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	for _, post := range []*models.Post{
+		{Title: "draft", Views: 500},
+		{Title: "quiet", IsPublished: true, Views: 3},
+		{Title: "hit", IsPublished: true, Views: 900},
+	} {
+		must(post.Save(ctx))
+	}
+
+	published, err := models.PostQuery.Published().Count(ctx)
+	must(err)
+	popular, err := models.PostQuery.Popular().Count(ctx)
+	must(err)
+	fmt.Println(published, popular)
+
+	rows, err := models.PostQuery.Published().Where("views", ">", 100).All(ctx)
+	must(err)
+	fmt.Println(len(rows), rows[0].(*models.Post).Title)
+}
+`)
+
+	if output != "2 2\n1 hit" {
+		t.Errorf("output = %q", output)
+	}
+}
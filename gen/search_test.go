@@ -0,0 +1,87 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const searchSchema = `
+model Post {
+  Id    Int     @id @auto
+  Title String  @searchable
+  Body  String? @searchable
+  Views Int
+}
+`
+
+func TestParseSearchableFields(t *testing.T) {
+	schema, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), searchSchema)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var searchable []string
+	for _, field := range schema.Models[0].Fields {
+		if field.Searchable {
+			searchable = append(searchable, field.Name)
+		}
+	}
+	if strings.Join(searchable, ",") != "Title,Body" {
+		t.Errorf("searchable fields = %v, want Title and Body", searchable)
+	}
+
+	_, err = NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), `
+model Post {
+  Id    Int @id @auto
+  Views Int @searchable
+}
+`)})
+	if err == nil || !strings.Contains(err.Error(), "@searchable can only be used on String fields") {
+		t.Errorf("err = %v, want a String-only error", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), searchSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	body := "Building an ORM in Go"
+	discount := "Save 100% today"
+	for _, post := range []*models.Post{
+		{Title: "Go generics", Views: 1},
+		{Title: "Weekly notes", Body: &body},
+		{Title: "Rust", Body: &discount},
+		{Title: "100 tips"},
+	} {
+		must(post.Save(ctx))
+	}
+
+	for _, term := range []string{"GO", "100%", "nothing"} {
+		posts, err := models.PostQuery.Search(ctx, term)
+		must(err)
+		var titles []string
+		for _, post := range posts {
+			titles = append(titles, post.Title)
+		}
+		fmt.Printf("%s: %q\n", term, titles)
+	}
+}
+`)
+
+	want := strings.Join([]string{
+		`GO: ["Go generics" "Weekly notes"]`,
+		`100%: ["Rust"]`,
+		`nothing: []`,
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
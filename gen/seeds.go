@@ -0,0 +1,132 @@
+package gen
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+func (g *Generator) GenerateSeeds(modelsDir, seedsDir string) error {
+	filename := filepath.Join(seedsDir, "seed.go")
+
+	if _, err := os.Stat(filename); err == nil {
+		return nil
+	}
+
+	modelsImport, err := importPath(modelsDir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(seedsDir, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	tmpl := template.Must(template.New("seed").Parse(seedTemplate))
+
+	data := struct {
+		ModelsImport string
+	}{
+		ModelsImport: modelsImport,
+	}
+
+	return tmpl.Execute(file, data)
+}
+
+func importPath(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for root := absDir; ; root = filepath.Dir(root) {
+		modulePath, err := readModulePath(filepath.Join(root, "go.mod"))
+		if err == nil {
+			rel, err := filepath.Rel(root, absDir)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return modulePath, nil
+			}
+			return modulePath + "/" + filepath.ToSlash(rel), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("no go.mod found for %s", dir)
+		}
+	}
+}
+
+func readModulePath(goModFile string) (string, error) {
+	file, err := os.Open(goModFile)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`), nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("no module directive in %s", goModFile)
+}
+
+const seedTemplate = `package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"{{.ModelsImport}}"
+)
+
+func init() {
+	core.RegisterSeed("default", Seed)
+}
+
+// Seed is run by ` + "`comet seed`" + `. Register additional seeders with
+// core.RegisterSeed or core.RegisterSeeder from other files in this package.
+func Seed(ctx context.Context, db *core.DB) error {
+	// Create rows with the generated models, for example:
+	//
+	//	user := &models.User{Email: "admin@example.com"}
+	//	return user.Save(ctx)
+	return nil
+}
+
+func main() {
+	cfg := models.LoadConfig()
+	if err := models.InitDB(cfg.DatabaseProvider, cfg.DatabaseURL); err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer models.Close()
+
+	if err := core.RunSeeders(context.Background(), core.GetDB(), os.Args[1:]...); err != nil {
+		log.Fatal(err)
+	}
+}
+`
@@ -0,0 +1,100 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const setterSchema = `
+model User {
+  Id    Int    @id @auto
+  Name  String
+  Posts Post[] @relation("UserPosts")
+}
+
+model Category {
+  Id    Int    @id @auto
+  Label String
+}
+
+model Post {
+  Id         Int       @id @auto
+  Title      String
+  UserId     Int
+  User       User      @relation("UserPosts", fields: [UserId], references: [Id])
+  CategoryId Int?
+  Category   Category? @relation(fields: [CategoryId], references: [Id])
+}
+`
+
+func TestRelationSetterGeneration(t *testing.T) {
+	dir := generate(t, NewGenerator(), setterSchema)
+
+	post := readGenerated(t, dir, "post.go")
+	for _, want := range []string{
+		"func (m *Post) SetUser(related *User) {",
+		"func (m *Post) SetCategory(related *Category) {",
+		"func (m *Post) ClearCategory() {",
+	} {
+		if !strings.Contains(post, want) {
+			t.Errorf("post.go does not contain %s", want)
+		}
+	}
+	if strings.Contains(post, "ClearUser") {
+		t.Error("post.go has ClearUser for a required foreign key")
+	}
+
+	if user := readGenerated(t, dir, "user.go"); !strings.Contains(user, "func (m *User) AddPost(children ...*Post) {") {
+		t.Error("user.go does not contain AddPost")
+	}
+}
+
+func TestRelationSettersPopulateForeignKeys(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), setterSchema, `package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	ann, err := models.UserQuery.Create(ctx, &models.User{Name: "Ann"})
+	must(err)
+	bob, err := models.UserQuery.Create(ctx, &models.User{Name: "Bob"})
+	must(err)
+	news, err := models.CategoryQuery.Create(ctx, &models.Category{Label: "News"})
+	must(err)
+
+	post := &models.Post{Title: "Hello"}
+	post.SetUser(ann)
+	post.SetCategory(news)
+	must(post.Save(ctx))
+
+	columns := func() string {
+		var userID int
+		var categoryID sql.NullInt64
+		must(core.GetDB().SQL().QueryRowContext(ctx, "SELECT user_id, category_id FROM posts WHERE id = ?", post.Id).Scan(&userID, &categoryID))
+		return fmt.Sprint(userID, categoryID.Valid, categoryID.Int64)
+	}
+	fmt.Println(columns(), post.User == ann)
+
+	post.ClearCategory()
+	must(post.Save(ctx))
+	fmt.Println(columns(), post.Category == nil)
+
+	bob.AddPost(post)
+	must(post.Save(ctx))
+	fmt.Println(columns(), post.User == bob)
+}
+`)
+
+	if output != "1 true 1 true\n1 false 0 true\n2 false 0 true" {
+		t.Errorf("output = %q", output)
+	}
+}
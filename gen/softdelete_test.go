@@ -0,0 +1,80 @@
+package gen
+
+import "testing"
+
+const softDeleteSchema = `
+model User {
+  Id    Int    @id @auto
+  Name  String
+  Posts Post[] @relation("UserPosts")
+}
+
+model Post {
+  Id        Int       @id @auto
+  Title     String
+  DeletedAt DateTime?
+  AuthorId  Int
+  Author    User      @relation("UserPosts", fields: [AuthorId], references: [Id])
+}
+`
+
+func TestRelationsHideSoftDeletedRows(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), softDeleteSchema, `package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+	core.RegisterDefaultScope("Post", func(q *core.Query) {
+		q.Wheres = append(q.Wheres, core.WhereClause{Field: "deleted_at IS NULL", Operator: "RAW"})
+	})
+
+	ann, err := models.UserQuery.Create(ctx, &models.User{Name: "ann"})
+	must(err)
+	bob, err := models.UserQuery.Create(ctx, &models.User{Name: "bob"})
+	must(err)
+	var posts []*models.Post
+	for _, title := range []string{"kept", "deleted"} {
+		post, err := models.PostQuery.Create(ctx, &models.Post{Title: title, AuthorId: ann.Id})
+		must(err)
+		posts = append(posts, post)
+	}
+	_, err = models.PostQuery.Create(ctx, &models.Post{Title: "only", AuthorId: bob.Id})
+	must(err)
+
+	now := time.Now()
+	posts[1].DeletedAt = &now
+	must(posts[1].Save(ctx))
+	_, err = models.PostQuery.Find().Where("author_id", "=", bob.Id).Update(ctx, map[string]interface{}{"deleted_at": now})
+	must(err)
+
+	live, err := ann.PostsQuery().All(ctx)
+	must(err)
+	trashed, err := ann.PostsQuery().Unscoped().All(ctx)
+	must(err)
+	fmt.Println(len(live), live[0].(*models.Post).Title, len(trashed))
+
+	count, err := ann.PostsCount(ctx)
+	must(err)
+	has, err := bob.HasPosts(ctx)
+	must(err)
+	fmt.Println(count, has)
+
+	authors, err := models.UserQuery.Find().WhereHas("Posts", nil).All(ctx)
+	must(err)
+	fmt.Println(len(authors), authors[0].(*models.User).Name)
+}
+`)
+
+	want := "1 kept 2\n1 false\n1 ann"
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
@@ -0,0 +1,69 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitQueriesFiles(t *testing.T) {
+	g := NewGenerator()
+	g.SetSplitQueries(true)
+	dir := generate(t, g, relationsSchema)
+
+	user := readGenerated(t, dir, "user.go")
+	query := readGenerated(t, dir, "user_query.go")
+	if !strings.Contains(user, "type User struct {") || strings.Contains(user, "type UserQueryBuilder struct") {
+		t.Error("user.go should hold the struct but not the query builder")
+	}
+	if !strings.Contains(query, "type UserQueryBuilder struct") || strings.Contains(query, "type User struct {") {
+		t.Error("user_query.go should hold the query builder but not the struct")
+	}
+
+	g = NewGenerator()
+	if err := g.GenerateFromFiles([]string{writeSchema(t, t.TempDir(), relationsSchema)}, dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "user_query.go")); !os.IsNotExist(err) {
+		t.Errorf("user_query.go still exists after regenerating without split: %v", err)
+	}
+	if !strings.Contains(readGenerated(t, dir, "user.go"), "type UserQueryBuilder struct") {
+		t.Error("user.go does not hold the query builder after regenerating without split")
+	}
+}
+
+func TestSplitQueriesCompile(t *testing.T) {
+	g := NewGenerator()
+	g.SetSplitQueries(true)
+
+	output := runGenerated(t, g, relationsSchema+enumSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	user, err := models.UserQuery.Create(ctx, &models.User{Name: "Ann"})
+	must(err)
+	_, err = models.PostQuery.Create(ctx, &models.Post{Title: "Hello", AuthorId: user.Id})
+	must(err)
+	_, err = models.MemberQuery.Create(ctx, &models.Member{Name: "Bob", Role: models.RoleAdmin})
+	must(err)
+
+	count, err := user.PostsCount(ctx)
+	must(err)
+	member, err := models.MemberQuery.Find().Where("name", "=", "Bob").First(ctx)
+	must(err)
+	fmt.Println(count, member.(*models.Member).Role)
+}
+`)
+
+	if output != "1 ADMIN" {
+		t.Errorf("output = %q", output)
+	}
+}
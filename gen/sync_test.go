@@ -0,0 +1,98 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const syncSchema = `
+model Product {
+  Id    Int    @id @auto
+  Sku   String @unique
+  Name  String
+  Price Int
+}
+`
+
+func TestSyncKeyColumns(t *testing.T) {
+	g := NewGenerator()
+	generate(t, g, syncSchema)
+
+	columns := g.keyColumns(g.Schema().Models[0])
+	if strings.Join(columns, ",") != "id,sku" {
+		t.Errorf("sync keys = %v, want [id sku]", columns)
+	}
+}
+
+func TestSync(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), syncSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	for _, p := range []*models.Product{
+		{Sku: "a", Name: "Apple", Price: 1},
+		{Sku: "b", Name: "Banana", Price: 2},
+		{Sku: "c", Name: "Cherry", Price: 3},
+	} {
+		_, err := models.ProductQuery.Create(ctx, p)
+		must(err)
+	}
+
+	result, err := models.ProductQuery.Sync(ctx, []*models.Product{
+		{Sku: "a", Name: "Apple", Price: 1},
+		{Sku: "b", Name: "Banana", Price: 5},
+		{Sku: "d", Name: "Date", Price: 4},
+	}, "sku", true)
+	must(err)
+	fmt.Println(result.Inserted, result.Updated, result.Deleted)
+
+	rows, err := models.ProductQuery.Find().OrderBy("sku", "ASC").All(ctx)
+	must(err)
+	for _, row := range rows {
+		p := row.(*models.Product)
+		fmt.Println(p.Sku, p.Price)
+	}
+
+	result, err = models.ProductQuery.Sync(ctx, []*models.Product{
+		{Sku: "e", Name: "Elderberry", Price: 6},
+	}, "sku", false)
+	must(err)
+	fmt.Println(result.Inserted, result.Updated, result.Deleted)
+
+	_, err = models.ProductQuery.Sync(ctx, []*models.Product{{Sku: "x", Name: "Apple"}}, "name", false)
+	fmt.Println(err)
+
+	_, err = models.ProductQuery.Sync(ctx, []*models.Product{{Sku: "a"}, {Sku: "a"}}, "sku", false)
+	fmt.Println(err)
+
+	_, err = models.ProductQuery.Sync(ctx, nil, "sku", true)
+	fmt.Println(err)
+
+	count, err := models.ProductQuery.Find().Count(ctx)
+	must(err)
+	fmt.Println(count)
+}
+`)
+
+	want := strings.Join([]string{
+		"1 1 1",
+		"a 1",
+		"b 5",
+		"d 4",
+		"1 0 0",
+		"cannot sync Product on 'name', key must be the primary key or a required unique column",
+		"duplicate Product sku a in sync input",
+		"refusing to sync Product with no records and deleteMissing set",
+		"4",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
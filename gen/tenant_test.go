@@ -0,0 +1,86 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const tenantSchema = `
+model Project {
+  Id        Int    @id @auto
+  AccountId Int    @tenant
+  Name      String
+}
+`
+
+func TestTenantScoping(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), tenantSchema, `package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func names(ctx context.Context) string {
+	rows, err := models.ProjectQuery.Find().OrderBy("id", "ASC").All(ctx)
+	must(err)
+	var names []string
+	for _, row := range rows {
+		names = append(names, row.(*models.Project).Name)
+	}
+	return fmt.Sprint(names)
+}
+
+func main() {
+	ctx := setup()
+	acme := core.WithTenant(ctx, 1)
+	globex := core.WithTenant(ctx, 2)
+
+	rocket := &models.Project{Name: "rocket"}
+	must(rocket.Save(acme))
+	spoofed := &models.Project{Name: "plans", AccountId: 1}
+	must(spoofed.Save(globex))
+	_, err := models.ProjectQuery.Create(acme, &models.Project{Name: "anvil"})
+	must(err)
+	fmt.Println(rocket.AccountId, spoofed.AccountId)
+
+	fmt.Println(names(acme), names(globex))
+
+	count, err := models.ProjectQuery.Find().Count(globex)
+	must(err)
+	_, err = models.ProjectQuery.FindById(globex, rocket.Id)
+	fmt.Println(count, errors.Is(err, sql.ErrNoRows))
+
+	rocket.Name = "hijacked"
+	fmt.Println(errors.Is(rocket.Save(globex), core.ErrTenantMismatch))
+	fmt.Println(errors.Is(rocket.Delete(globex), core.ErrTenantMismatch))
+	deleted, err := models.ProjectQuery.DeleteByIds(globex, []int{rocket.Id})
+	must(err)
+	fmt.Println(deleted)
+
+	_, err = models.ProjectQuery.Find().All(ctx)
+	fmt.Println(errors.Is(err, core.ErrMissingTenant))
+	fmt.Println(errors.Is((&models.Project{Name: "orphan"}).Save(ctx), core.ErrMissingTenant))
+}
+`)
+
+	want := strings.Join([]string{
+		"1 2",
+		"[rocket anvil] [plans]",
+		"1 true",
+		"true",
+		"true",
+		"0",
+		"true",
+		"true",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
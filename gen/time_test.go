@@ -0,0 +1,241 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const timeSchema = `
+model Event {
+  Id       Int       @id @auto
+  StartsAt DateTime
+  EndsAt   DateTime?
+}
+`
+
+const timeProgram = `package main
+
+import (
+	"fmt"
+	"time"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	kolkata := time.FixedZone("IST", 5*3600+1800)
+	starts := time.Date(2024, 3, 1, 9, 30, 0, 0, kolkata)
+	ends := starts.Add(90 * time.Minute)
+
+	event := &models.Event{StartsAt: starts, EndsAt: &ends}
+	must(event.Save(ctx))
+	fmt.Println(event.StartsAt.Location(), event.EndsAt.Location())
+
+	loaded, err := models.EventQuery.FindById(ctx, event.Id)
+	must(err)
+	fmt.Println(loaded.StartsAt.Location(), loaded.StartsAt.Format(time.RFC3339), loaded.StartsAt.Equal(starts))
+	fmt.Println(loaded.EndsAt.Location(), loaded.EndsAt.Format(time.RFC3339), loaded.CreatedAt.Location())
+}
+`
+
+func TestTimesNormalizedToUTC(t *testing.T) {
+	for _, provider := range []string{"sqlite", "postgres"} {
+		t.Run(provider, func(t *testing.T) {
+			output := runGeneratedOn(t, provider, NewGenerator(), timeSchema, timeProgram)
+
+			want := "UTC UTC\nUTC 2024-03-01T04:00:00Z true\nUTC 2024-03-01T05:30:00Z UTC"
+			if output != want {
+				t.Errorf("output:\n%s\nwant:\n%s", output, want)
+			}
+		})
+	}
+}
+
+const timestampSchema = `
+model Meeting {
+  Id        Int      @id @auto
+  StartsAt  DateTime @db.Timestamptz
+  LocalTime DateTime @db.Timestamp
+  EndsAt    DateTime
+}
+`
+
+func TestPostgresTimestampTypes(t *testing.T) {
+	g := NewGenerator()
+	generate(t, g, timestampSchema)
+
+	postgres := strings.Join(dialectStatements(t, "postgres", g.Schema()), "\n")
+	for _, want := range []string{
+		"starts_at TIMESTAMPTZ NOT NULL",
+		"local_time TIMESTAMP NOT NULL",
+		"ends_at TIMESTAMPTZ NOT NULL",
+	} {
+		if !strings.Contains(postgres, want) {
+			t.Errorf("postgres DDL does not contain %q:\n%s", want, postgres)
+		}
+	}
+
+	sqlite := strings.Join(schemaStatements(t, g.Schema()), "\n")
+	if !strings.Contains(sqlite, "starts_at DATETIME NOT NULL") {
+		t.Errorf("sqlite DDL should keep DATETIME:\n%s", sqlite)
+	}
+}
+
+func TestTimestampAttributeOnlyOnDateTime(t *testing.T) {
+	for _, attr := range []string{"@db.Timestamptz", "@db.Timestamp(3)"} {
+		_, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), `
+model Meeting {
+  Id    Int    @id @auto
+  Title String `+attr+`
+}
+`)})
+		want := strings.TrimSuffix(attr, "(3)") + " can only be used on DateTime fields"
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %s", attr, err, want)
+		}
+	}
+}
+
+func TestPostgresTimestamptzRoundTrip(t *testing.T) {
+	output := runGeneratedOn(t, "postgres", NewGenerator(), timestampSchema, `package main
+
+import (
+	"fmt"
+	"time"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	tokyo := time.FixedZone("JST", 9*3600)
+	starts := time.Date(2024, 6, 1, 18, 0, 0, 0, tokyo)
+
+	meeting := &models.Meeting{StartsAt: starts, LocalTime: starts, EndsAt: starts.Add(time.Hour)}
+	must(meeting.Save(ctx))
+
+	loaded, err := models.MeetingQuery.FindById(ctx, meeting.Id)
+	must(err)
+	fmt.Println(loaded.StartsAt.Equal(starts), loaded.StartsAt.Format(time.RFC3339))
+	fmt.Println(loaded.EndsAt.Sub(loaded.StartsAt))
+}
+`)
+
+	if output != "true 2024-06-01T09:00:00Z\n1h0m0s" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+const precisionSchema = `
+model Reading {
+  Id        Int      @id @auto
+  TakenAt   DateTime @db.Timestamptz(3)
+  SeenAt    DateTime @db.Timestamp(0)
+  CreatedAt DateTime @default(now()) @db.Timestamptz(6)
+}
+`
+
+func TestTimestampPrecisionDDL(t *testing.T) {
+	g := NewGenerator()
+	generate(t, g, precisionSchema)
+
+	for provider, want := range map[string][]string{
+		"postgres": {"taken_at TIMESTAMPTZ(3) NOT NULL", "seen_at TIMESTAMP(0) NOT NULL", "created_at TIMESTAMPTZ(6) NOT NULL"},
+		"mysql":    {"taken_at TIMESTAMP(3) NOT NULL", "seen_at TIMESTAMP(0) NOT NULL", "created_at TIMESTAMP(6) NOT NULL"},
+	} {
+		ddl := strings.Join(dialectStatements(t, provider, g.Schema()), "\n")
+		for _, fragment := range want {
+			if !strings.Contains(ddl, fragment) {
+				t.Errorf("%s DDL does not contain %q:\n%s", provider, fragment, ddl)
+			}
+		}
+	}
+
+	_, err := NewParser().ParseFiles([]string{writeSchema(t, t.TempDir(), `
+model Reading {
+  Id      Int      @id @auto
+  TakenAt DateTime @db.Timestamp(7)
+}
+`)})
+	if err == nil || !strings.Contains(err.Error(), "invalid timestamp precision '7'") {
+		t.Errorf("err = %v, want invalid precision", err)
+	}
+}
+
+func TestTimestampPrecisionRoundTrip(t *testing.T) {
+	program := `package main
+
+import (
+	"fmt"
+	"time"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	at := time.Date(2024, 6, 1, 9, 30, 15, 123456789, time.UTC)
+	reading := &models.Reading{TakenAt: at, SeenAt: at}
+	must(reading.Save(ctx))
+	fmt.Println(reading.TakenAt.Nanosecond(), reading.SeenAt.Nanosecond(), reading.CreatedAt.Nanosecond()%1000)
+
+	loaded, err := models.ReadingQuery.FindById(ctx, reading.Id)
+	must(err)
+	fmt.Println(loaded.TakenAt.Equal(reading.TakenAt), loaded.SeenAt.Equal(reading.SeenAt), loaded.CreatedAt.Equal(reading.CreatedAt))
+}
+`
+	for _, provider := range []string{"sqlite", "postgres", "mysql"} {
+		t.Run(provider, func(t *testing.T) {
+			output := runGeneratedOn(t, provider, NewGenerator(), precisionSchema, program)
+			if want := "123000000 0 0\ntrue true true"; output != want {
+				t.Errorf("output:\n%s\nwant:\n%s", output, want)
+			}
+		})
+	}
+}
+
+func TestDeclaredTimestampFields(t *testing.T) {
+	schema := `
+model Post {
+  Id        Int      @id @auto
+  Title     String
+  CreatedAt DateTime @default(now())
+  UpdatedAt DateTime @updatedAt
+}
+`
+	g := NewGenerator()
+	post := readGenerated(t, generate(t, g, schema), "post.go")
+	if strings.Count(post, "CreatedAt time.Time") != 1 || strings.Count(post, "UpdatedAt time.Time") != 1 {
+		t.Errorf("post.go declares the timestamps twice")
+	}
+	ddl := strings.Join(schemaStatements(t, g.Schema()), "\n")
+	if strings.Count(ddl, "created_at") != 1 || !strings.Contains(ddl, "created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP") {
+		t.Errorf("DDL:\n%s", ddl)
+	}
+
+	output := runGenerated(t, NewGenerator(), schema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	post, err := models.PostQuery.Create(ctx, &models.Post{Title: "hello"})
+	must(err)
+	loaded, err := models.PostQuery.FindById(ctx, post.Id)
+	must(err)
+	fmt.Println(loaded.Title, loaded.CreatedAt.IsZero(), loaded.CreatedAt.Equal(post.CreatedAt))
+}
+`)
+	if output != "hello false true" {
+		t.Errorf("output = %q", output)
+	}
+}
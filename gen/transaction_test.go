@@ -0,0 +1,62 @@
+package gen
+
+import "testing"
+
+const transactionSchema = `
+model Account {
+  Id      Int    @id @auto
+  Owner   String
+  Balance Int
+}
+`
+
+func TestTransactionRollsBackOnError(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), transactionSchema, `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	kept := &models.Account{Owner: "ann", Balance: 100}
+	must(kept.Save(ctx))
+
+	err := models.Transaction(ctx, func(ctx context.Context) error {
+		must((&models.Account{Owner: "bob", Balance: 50}).Save(ctx))
+
+		kept.Balance = 0
+		must(kept.Save(ctx))
+
+		count, err := models.AccountQuery.Find().Count(ctx)
+		must(err)
+		fmt.Println("inside", count)
+		return errors.New("boom")
+	})
+	fmt.Println(err)
+
+	count, err := models.AccountQuery.Find().Count(ctx)
+	must(err)
+	loaded, err := models.AccountQuery.FindById(ctx, kept.Id)
+	must(err)
+	fmt.Println("after", count, loaded.Balance)
+
+	must(models.Transaction(ctx, func(ctx context.Context) error {
+		return loaded.Delete(ctx)
+	}))
+	count, err = models.AccountQuery.Find().Count(ctx)
+	must(err)
+	fmt.Println("committed", count)
+}
+`)
+
+	want := "inside 2\nboom\nafter 1 100\ncommitted 0"
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
@@ -0,0 +1,88 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const uniqueSchema = `
+model Member {
+  Id       Int     @id @auto
+  Email    String  @unique
+  Handle   String? @unique
+  Name     String
+  Nickname String?
+}
+`
+
+func TestExistsByUniqueFields(t *testing.T) {
+	member := readGenerated(t, generate(t, NewGenerator(), uniqueSchema), "member.go")
+
+	for _, want := range []string{
+		"func (q *MemberQueryBuilder) ExistsByEmail(ctx context.Context, value string) (bool, error)",
+		"func (q *MemberQueryBuilder) ExistsByHandle(ctx context.Context, value string) (bool, error)",
+	} {
+		if !strings.Contains(member, want) {
+			t.Errorf("member.go does not contain %s", want)
+		}
+	}
+	for _, unwanted := range []string{"ExistsById", "ExistsByName", "ExistsByNickname"} {
+		if strings.Contains(member, unwanted) {
+			t.Errorf("member.go contains %s for a non-unique field", unwanted)
+		}
+	}
+}
+
+func TestFindByUniqueFieldsGeneration(t *testing.T) {
+	member := readGenerated(t, generate(t, NewGenerator(), uniqueSchema), "member.go")
+
+	for _, want := range []string{
+		"func (q *MemberQueryBuilder) FindByEmail(ctx context.Context, value string) (*Member, error)",
+		"func (q *MemberQueryBuilder) FindByHandle(ctx context.Context, value string) (*Member, error)",
+		`Where("email", "=", value)`,
+	} {
+		if !strings.Contains(member, want) {
+			t.Errorf("member.go does not contain %s", want)
+		}
+	}
+	if strings.Contains(member, "FindByName") || strings.Contains(member, "FindByNickname") {
+		t.Error("member.go has FindBy for a non-unique field")
+	}
+}
+
+func TestFindByUniqueFields(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), uniqueSchema, `package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	handle := "ann"
+	_, err := models.MemberQuery.Create(ctx, &models.Member{Email: "ann@example.com", Handle: &handle, Name: "Ann"})
+	must(err)
+	_, err = models.MemberQuery.Create(ctx, &models.Member{Email: "bob@example.com", Name: "Bob"})
+	must(err)
+
+	bob, err := models.MemberQuery.FindByEmail(ctx, "bob@example.com")
+	must(err)
+	ann, err := models.MemberQuery.FindByHandle(ctx, "ann")
+	must(err)
+	fmt.Println(bob.Name, ann.Name)
+
+	_, err = models.MemberQuery.FindByEmail(ctx, "eve@example.com")
+	fmt.Println(errors.Is(err, core.ErrNotFound))
+}
+`)
+
+	if output != "Bob Ann\ntrue" {
+		t.Errorf("output = %q", output)
+	}
+}
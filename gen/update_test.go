@@ -0,0 +1,242 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const profileSchema = `
+model Profile {
+  Id   Int    @id @auto
+  Name String
+  Bio  String
+  Age  Int
+}
+`
+
+func TestCreateAndUpdateReturnModel(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), profileSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	input := &models.Profile{Name: "Ann", Bio: "gopher", Age: 30}
+	created, err := models.ProfileQuery.Create(ctx, input)
+	must(err)
+	fmt.Println(created == input, created.Id, created.IsNew())
+
+	second, err := models.ProfileQuery.Create(ctx, &models.Profile{Name: "Bob"})
+	must(err)
+	fmt.Println(second.Id)
+
+	created.Age = 31
+	updated, err := models.ProfileQuery.Update(ctx, created)
+	must(err)
+	fmt.Println(updated == created, updated.Id, updated.Age)
+
+	_, err = models.ProfileQuery.Update(ctx, &models.Profile{Name: "Eve"})
+	fmt.Println(err)
+}
+`)
+
+	want := strings.Join([]string{
+		"true 1 false",
+		"2",
+		"true 1 31",
+		"cannot update Profile that has not been saved",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
+
+// updatedColumnsHarness logs the columns named in each UPDATE of profiles:
+// an AFTER UPDATE OF trigger fires only when its column is in the SET list.
+const updatedColumnsHarness = `
+func trackUpdates(ctx context.Context) {
+	_, err := models.Exec(ctx, "CREATE TABLE updated_columns (name TEXT)")
+	must(err)
+	for _, column := range []string{"name", "bio", "age", "updated_at"} {
+		_, err := models.Exec(ctx, "CREATE TRIGGER profiles_"+column+" AFTER UPDATE OF "+column+" ON profiles BEGIN INSERT INTO updated_columns VALUES ('"+column+"'); END")
+		must(err)
+	}
+}
+
+func updatedColumns(ctx context.Context) string {
+	rows, err := models.Query(ctx, "SELECT name FROM updated_columns")
+	must(err)
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		must(rows.Scan(&column))
+		columns = append(columns, column)
+	}
+	_, err = models.Exec(ctx, "DELETE FROM updated_columns")
+	must(err)
+	sort.Strings(columns)
+	return strings.Join(columns, ",")
+}
+`
+
+func TestUpdateWritesChangedColumnsOnly(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), profileSchema, `package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gentest/models"
+)
+`+updatedColumnsHarness+`
+func main() {
+	ctx := setup()
+	trackUpdates(ctx)
+
+	profile, err := models.ProfileQuery.Create(ctx, &models.Profile{Name: "Ann", Bio: "gopher", Age: 30})
+	must(err)
+
+	profile.Age = 31
+	must(profile.Save(ctx))
+	fmt.Println(updatedColumns(ctx))
+
+	must(profile.Save(ctx))
+	fmt.Println(updatedColumns(ctx) == "")
+
+	profile.Bio = "rustacean"
+	profile.Name = "Anna"
+	must(profile.UpdateFields(ctx, "Bio"))
+	fmt.Println(updatedColumns(ctx))
+
+	stale, err := models.ProfileQuery.FindById(ctx, profile.Id)
+	must(err)
+	stale.Age = 40
+	must(stale.Save(ctx))
+	fmt.Println(updatedColumns(ctx))
+
+	found, err := models.ProfileQuery.FindById(ctx, profile.Id)
+	must(err)
+	fmt.Println(found.Name, found.Bio, found.Age)
+}
+`)
+
+	want := strings.Join([]string{
+		"age,updated_at",
+		"true",
+		"bio,updated_at",
+		"age,updated_at",
+		"Ann rustacean 40",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
+
+func TestSettersMarkColumnsDirty(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), profileSchema, `package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gentest/models"
+)
+`+updatedColumnsHarness+`
+func main() {
+	ctx := setup()
+	trackUpdates(ctx)
+
+	profile, err := models.ProfileQuery.Create(ctx, &models.Profile{Name: "Ann", Bio: "gopher", Age: 30})
+	must(err)
+
+	profile.SetBio("rustacean")
+	profile.Age = 99
+	must(profile.Save(ctx))
+	fmt.Println(updatedColumns(ctx))
+
+	profile.SetName("Ann")
+	must(profile.Save(ctx))
+	fmt.Println(updatedColumns(ctx))
+
+	found, err := models.ProfileQuery.FindById(ctx, profile.Id)
+	must(err)
+	fmt.Println(found.Bio, found.Age)
+}
+`)
+
+	want := strings.Join([]string{
+		"bio,updated_at",
+		"name,updated_at",
+		"rustacean 30",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
+
+func TestUpdateMany(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), profileSchema, `package main
+
+import (
+	"fmt"
+
+	"gentest/models"
+)
+
+func main() {
+	ctx := setup()
+
+	var profiles []*models.Profile
+	for i, name := range []string{"Ann", "Bob", "Cat", "Dan"} {
+		profile, err := models.ProfileQuery.Create(ctx, &models.Profile{Name: name, Bio: "-", Age: 20 + i})
+		must(err)
+		profiles = append(profiles, profile)
+	}
+	unchanged := profiles[3].UpdatedAt
+
+	profiles[0].Age = 30
+	profiles[1].Bio = "gopher"
+	profiles[2].Name = "Cathy"
+	profiles[2].Age = 50
+
+	affected, err := models.ProfileQuery.UpdateMany(ctx, profiles)
+	must(err)
+	fmt.Println(affected)
+	fmt.Println(profiles[0].UpdatedAt.Equal(profiles[2].UpdatedAt), profiles[0].UpdatedAt.After(unchanged), profiles[3].UpdatedAt.Equal(unchanged))
+
+	all, err := models.ProfileQuery.Find().OrderBy("id", "ASC").All(ctx)
+	must(err)
+	for _, item := range all {
+		profile := item.(*models.Profile)
+		fmt.Println(profile.Name, profile.Bio, profile.Age)
+	}
+
+	_, err = models.ProfileQuery.UpdateMany(ctx, []*models.Profile{profiles[0], {Name: "Eve"}})
+	fmt.Println(err)
+}
+`)
+
+	want := strings.Join([]string{
+		"3",
+		"true true true",
+		"Ann - 30",
+		"Bob gopher 21",
+		"Cathy - 50",
+		"Dan - 23",
+		"cannot update Profile that has not been saved",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}
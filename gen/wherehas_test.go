@@ -0,0 +1,67 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWhereHasRelations(t *testing.T) {
+	output := runGenerated(t, NewGenerator(), relationsSchema, `package main
+
+import (
+	"fmt"
+
+	"github.com/nitrix4ly/comet/core"
+
+	"gentest/models"
+)
+
+func names(rows []interface{}, err error) string {
+	must(err)
+	var names []string
+	for _, row := range rows {
+		switch m := row.(type) {
+		case *models.User:
+			names = append(names, m.Name)
+		case *models.Post:
+			names = append(names, m.Title)
+		}
+	}
+	return fmt.Sprint(names)
+}
+
+func main() {
+	ctx := setup()
+
+	ann, err := models.UserQuery.Create(ctx, &models.User{Name: "ann"})
+	must(err)
+	bob, err := models.UserQuery.Create(ctx, &models.User{Name: "bob"})
+	must(err)
+	_, err = models.UserQuery.Create(ctx, &models.User{Name: "cy"})
+	must(err)
+	for author, titles := range map[int][]string{ann.Id: {"go tips", "sql"}, bob.Id: {"go faster"}} {
+		for _, title := range titles {
+			_, err := models.PostQuery.Create(ctx, &models.Post{Title: title, AuthorId: author})
+			must(err)
+		}
+	}
+
+	fmt.Println(names(models.PostQuery.Find().WhereHas("Author", func(q core.QueryBuilder) {
+		q.Where("name", "=", "ann")
+	}).OrderBy("id", "ASC").All(ctx)))
+	fmt.Println(names(models.UserQuery.Find().WhereHas("Posts", func(q core.QueryBuilder) {
+		q.Where("title", "LIKE", "go%")
+	}).OrderBy("id", "ASC").All(ctx)))
+	fmt.Println(names(models.UserQuery.Find().WhereHas("Posts", nil).OrderBy("id", "ASC").All(ctx)))
+}
+`)
+
+	want := strings.Join([]string{
+		"[go tips sql]",
+		"[ann bob]",
+		"[ann bob]",
+	}, "\n")
+	if output != want {
+		t.Errorf("output:\n%s\nwant:\n%s", output, want)
+	}
+}